@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/project/library/config"
+	"github.com/project/library/internal/auth"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/library"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+// runAPIKey manages the API keys in the database the server is configured
+// with:
+//
+//	library apikey create -name name [-subject subject] [-roles reader,librarian] [-ttl 720h]
+//	library apikey revoke id
+//
+// create prints the ID and the key; only the hash of the key is stored, so
+// it can't be shown again.
+func runAPIKey(args []string) error {
+	const usage = "usage: library apikey create|revoke [flags]"
+
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	cfg, err := config.New()
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.PG.URL)
+
+	if err != nil {
+		return err
+	}
+
+	defer pool.Close()
+
+	keys := repository.NewAPIKey(pool)
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(ctx, keys, args[1:])
+	case "revoke":
+		return revokeAPIKey(ctx, keys, args[1:])
+	default:
+		return errors.New(usage)
+	}
+}
+
+func createAPIKey(ctx context.Context, keys repository.APIKeyRepository, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ExitOnError)
+	name := flags.String("name", "", "what the key is for")
+	subject := flags.String("subject", "", "principal the key authenticates as, the name when empty")
+	roleNames := flags.String("roles", string(entity.RoleReader), "comma-separated roles: reader, librarian or admin")
+	ttl := flags.Duration("ttl", 0, "lifetime of the key, unlimited when zero")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" || flags.NArg() != 0 {
+		return errors.New("usage: library apikey create -name name [-subject subject] [-roles roles] [-ttl duration]")
+	}
+
+	var roles []entity.Role
+
+	for _, roleName := range strings.Split(*roleNames, ",") {
+		role, err := entity.ParseRole(roleName)
+
+		if err != nil {
+			return err
+		}
+
+		roles = append(roles, role)
+	}
+
+	if *subject == "" {
+		*subject = *name
+	}
+
+	id, err := library.NewUUIDv7Generator().NewID()
+
+	if err != nil {
+		return err
+	}
+
+	key, keyHash, err := auth.NewAPIKey()
+
+	if err != nil {
+		return err
+	}
+
+	apiKey := entity.APIKey{ID: id, Name: *name, Subject: *subject, Roles: roles}
+
+	if *ttl > 0 {
+		apiKey.ExpiresAt = time.Now().Add(*ttl)
+	}
+
+	if _, err = keys.CreateAPIKey(ctx, apiKey, keyHash); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "id: %s\nkey: %s\n", id, key)
+
+	return nil
+}
+
+func revokeAPIKey(ctx context.Context, keys repository.APIKeyRepository, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: library apikey revoke id")
+	}
+
+	return keys.RevokeAPIKey(ctx, args[0])
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/auth"
+	"github.com/project/library/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const gzipExtension = ".gz"
+
+var catalogFormats = map[string]library.CatalogFormat{
+	"csv":     library.CatalogFormat_CATALOG_FORMAT_CSV,
+	"jsonl":   library.CatalogFormat_CATALOG_FORMAT_JSONL,
+	"marc":    library.CatalogFormat_CATALOG_FORMAT_MARC21,
+	"mrc":     library.CatalogFormat_CATALOG_FORMAT_MARC21,
+	"marcxml": library.CatalogFormat_CATALOG_FORMAT_MARCXML,
+	"xml":     library.CatalogFormat_CATALOG_FORMAT_MARCXML,
+}
+
+func addrFlag(flags *flag.FlagSet) *string {
+	return flags.String("addr", "localhost:"+os.Getenv("GRPC_PORT"), "address of the gRPC server")
+}
+
+// credentialsFlag registers -token and -api-key, by default taken from
+// LIBRARY_TOKEN and LIBRARY_API_KEY. The returned function adds the
+// credentials to the outgoing metadata.
+func credentialsFlag(flags *flag.FlagSet) func(ctx context.Context) context.Context {
+	token := flags.String("token", os.Getenv("LIBRARY_TOKEN"), "JWT to authenticate with")
+	apiKey := flags.String("api-key", os.Getenv("LIBRARY_API_KEY"), "API key to authenticate with")
+
+	return func(ctx context.Context) context.Context {
+		if *token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, auth.AuthorizationHeader, "Bearer "+*token)
+		}
+
+		if *apiKey != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, *apiKey)
+		}
+
+		return ctx
+	}
+}
+
+// clientTLS holds the TLS flags of a client subcommand.
+type clientTLS struct {
+	enabled bool
+	options tlsconfig.ClientOptions
+}
+
+// tlsFlag registers -tls, -ca, -cert, -key and -server-name, by default
+// taken from LIBRARY_TLS, LIBRARY_CA_FILE, LIBRARY_CERT_FILE and
+// LIBRARY_KEY_FILE. Setting -ca or -cert implies -tls.
+func tlsFlag(flags *flag.FlagSet) *clientTLS {
+	result := new(clientTLS)
+
+	flags.BoolVar(&result.enabled, "tls", os.Getenv("LIBRARY_TLS") == "true", "connect over TLS")
+	flags.StringVar(&result.options.CAFile, "ca", os.Getenv("LIBRARY_CA_FILE"), "CA certificates to verify the server with, the system roots when empty")
+	flags.StringVar(&result.options.CertFile, "cert", os.Getenv("LIBRARY_CERT_FILE"), "client certificate for mutual TLS")
+	flags.StringVar(&result.options.KeyFile, "key", os.Getenv("LIBRARY_KEY_FILE"), "key of the client certificate")
+	flags.StringVar(&result.options.ServerName, "server-name", "", "name to verify the server certificate for, the host of -addr when empty")
+
+	return result
+}
+
+func dial(addr string, clientTLS *clientTLS) (*grpc.ClientConn, error) {
+	transportCredentials := insecure.NewCredentials()
+
+	if clientTLS.enabled || clientTLS.options.CAFile != "" || clientTLS.options.CertFile != "" {
+		tlsConfig, err := tlsconfig.Client(clientTLS.options)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	return grpc.NewClient(addr, grpc.WithTransportCredentials(transportCredentials))
+}
+
+// catalogFormat takes the format from the flag or, when it is empty, from
+// the extension of path under an optional .gz.
+func catalogFormat(format string, path string) (library.CatalogFormat, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(path, gzipExtension)), ".")
+	}
+
+	catalogFormat, ok := catalogFormats[strings.ToLower(format)]
+
+	if !ok {
+		return library.CatalogFormat_CATALOG_FORMAT_UNSPECIFIED, fmt.Errorf("unknown format %q", format)
+	}
+
+	return catalogFormat, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/project/library/config"
+)
+
+// runConfig shows the configuration the server would run with:
+//
+//	library config print [-file path]
+//
+// print writes the effective configuration as YAML, the file merged with
+// the environment and the defaults, with the secrets redacted. It fails
+// after printing when the configuration is invalid.
+func runConfig(args []string) error {
+	const usage = "usage: library config print [-file path]"
+
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	file := flags.String("file", os.Getenv(config.FileEnv), "YAML configuration file, $"+config.FileEnv+" by default")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return errors.New(usage)
+	}
+
+	cfg, err := config.Read(*file)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := cfg.Redacted()
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stdout, string(data))
+
+	if err = cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return nil
+}
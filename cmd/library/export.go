@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/project/library/generated/api/library"
+)
+
+// runExport writes a snapshot of the catalog from ExportCatalog:
+//
+//	library export [-addr host:port] [-token jwt | -api-key key] [-tls] [-ca file] [-cert file -key file] [-format csv|jsonl|marc|marcxml] [-gzip] [-o file]
+//
+// Without -o the snapshot goes to the standard output. A file is written
+// next to its final path and renamed once complete, so a failed export never
+// leaves a truncated snapshot behind.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	addr := addrFlag(flags)
+	withCredentials := credentialsFlag(flags)
+	clientTLS := tlsFlag(flags)
+	formatName := flags.String("format", "", "csv, jsonl, marc or marcxml, taken from the file extension when empty")
+	gzipped := flags.Bool("gzip", false, "gzip the output, implied by a .gz file extension")
+	path := flags.String("o", "", "output file, the standard output when empty")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return errors.New("usage: library export [flags]")
+	}
+
+	if *formatName == "" && *path == "" {
+		*formatName = "jsonl"
+	}
+
+	format, err := catalogFormat(*formatName, *path)
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := dial(*addr, clientTLS)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	stream, err := library.NewLibraryClient(conn).ExportCatalog(withCredentials(context.Background()), &library.ExportCatalogRequest{
+		Format: format,
+		Gzip:   *gzipped || strings.HasSuffix(*path, gzipExtension),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return receiveCatalog(stream, os.Stdout)
+	}
+
+	return writeAtomically(*path, func(w io.Writer) error {
+		return receiveCatalog(stream, w)
+	})
+}
+
+func receiveCatalog(stream library.Library_ExportCatalogClient, w io.Writer) error {
+	for {
+		chunk, err := stream.Recv()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err = w.Write(chunk.GetChunk()); err != nil {
+			return err
+		}
+	}
+}
+
+func writeAtomically(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
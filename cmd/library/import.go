@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/controller"
+	"google.golang.org/grpc/metadata"
+)
+
+const importChunkSize = 64 * 1024
+
+// runImport streams a CSV or JSON Lines file to ImportCatalog:
+//
+//	library import [-addr host:port] [-token jwt | -api-key key] [-tls] [-ca file] [-cert file -key file] [-format csv|jsonl|marc|marcxml] [-dry-run] [-import-id id] file
+//
+// The import ID defaults to a hash of the file, so running the command again
+// for the same file resumes after the last committed batch. Gzipped files,
+// such as the ones written by export -gzip, are read as is.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	addr := addrFlag(flags)
+	withCredentials := credentialsFlag(flags)
+	clientTLS := tlsFlag(flags)
+	formatName := flags.String("format", "", "csv, jsonl, marc or marcxml, taken from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate the file without writing anything")
+	importID := flags.String("import-id", "", "checkpoint name, a hash of the file when empty")
+	batchSize := flags.Uint("batch-size", 0, "rows per transaction, the server default when zero")
+	actor := flags.String("actor", os.Getenv("USER"), "who the history records the changes to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: library import [flags] file")
+	}
+
+	path := flags.Arg(0)
+	format, err := catalogFormat(*formatName, path)
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if *importID == "" {
+		if *importID, err = hashFile(file); err != nil {
+			return err
+		}
+	}
+
+	conn, err := dial(*addr, clientTLS)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(withCredentials(context.Background()), controller.ActorHeader, *actor)
+
+	report, err := sendCatalog(ctx, library.NewLibraryClient(conn), file, &library.ImportOptions{
+		Format:    format,
+		DryRun:    *dryRun,
+		ImportId:  *importID,
+		BatchSize: uint32(*batchSize),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	printImportReport(os.Stdout, report)
+
+	return nil
+}
+
+// hashFile names the import after the file content and rewinds the file.
+func hashFile(file *os.File) (string, error) {
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func sendCatalog(ctx context.Context, client library.LibraryClient, r io.Reader, options *library.ImportOptions) (*library.ImportCatalogResponse, error) {
+	stream, err := client.ImportCatalog(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&library.ImportCatalogRequest{
+		Payload: &library.ImportCatalogRequest_Options{Options: options},
+	})
+
+	buf := make([]byte, importChunkSize)
+
+	for err == nil {
+		var n int
+		n, err = r.Read(buf)
+
+		if n > 0 {
+			if sendErr := stream.Send(&library.ImportCatalogRequest{
+				Payload: &library.ImportCatalogRequest_Chunk{Chunk: buf[:n]},
+			}); sendErr != nil {
+				err = sendErr
+			}
+		}
+	}
+
+	// On io.EOF from Send the server has already answered; CloseAndRecv
+	// returns its status.
+	if !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func printImportReport(w io.Writer, report *library.ImportCatalogResponse) {
+	if report.GetDryRun() {
+		fmt.Fprintln(w, "dry run, nothing was written")
+	}
+
+	fmt.Fprintf(w, "rows: %d, skipped: %d, books imported: %d, authors created: %d, errors: %d, checkpoint: %d\n",
+		report.GetRows(), report.GetRowsSkipped(), report.GetBooksImported(), report.GetAuthorsCreated(),
+		len(report.GetErrors()), report.GetCheckpoint())
+
+	for _, rowErr := range report.GetErrors() {
+		fmt.Fprintf(w, "row %d: %s\n", rowErr.GetRow(), rowErr.GetMessage())
+	}
+}
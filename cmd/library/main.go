@@ -11,6 +11,41 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatalf("import failed: %s", err)
+			}
+
+			return
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatalf("export failed: %s", err)
+			}
+
+			return
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatalf("migrate failed: %s", err)
+			}
+
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				log.Fatalf("config failed: %s", err)
+			}
+
+			return
+		case "apikey":
+			if err := runAPIKey(os.Args[2:]); err != nil {
+				log.Fatalf("apikey failed: %s", err)
+			}
+
+			return
+		}
+	}
+
 	cfg, err := config.New()
 
 	if err != nil {
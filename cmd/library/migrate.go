@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+	"github.com/project/library/config"
+	"github.com/project/library/db"
+)
+
+// runMigrate manages the schema of the database the server is configured
+// with, using the migrations embedded in the binary:
+//
+//	library migrate up|down|redo|status|version
+//
+// up applies every pending migration, down rolls the latest one back and
+// redo rolls it back and applies it again. The changes hold an advisory
+// lock, so they are safe to run while replicas start.
+func runMigrate(args []string) error {
+	const usage = "usage: library migrate up|down|redo|status|version"
+
+	// The command is checked before connecting to the database.
+	if len(args) != 1 || !slices.Contains([]string{"up", "down", "redo", "status", "version"}, args[0]) {
+		return errors.New(usage)
+	}
+
+	cfg, err := config.New()
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.PG.URL)
+
+	if err != nil {
+		return err
+	}
+
+	defer pool.Close()
+
+	migrator, err := db.NewMigrator(pool)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = migrator.Close() }()
+
+	switch args[0] {
+	case "up":
+		results, err := migrator.Up(ctx)
+		printResults(results, err)
+
+		return err
+	case "down":
+		result, err := migrator.Down(ctx)
+		printResults([]*goose.MigrationResult{result}, err)
+
+		return err
+	case "redo":
+		results, err := migrator.Redo(ctx)
+		printResults(results, err)
+
+		return err
+	case "status":
+		return printStatus(ctx, migrator)
+	case "version":
+		current, latest, err := migrator.Version(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "version: %d\nlatest: %d\n", current, latest)
+
+		return nil
+	default:
+		return errors.New(usage)
+	}
+}
+
+// printResults prints the migrations that ran, also those before the one
+// that failed.
+func printResults(results []*goose.MigrationResult, err error) {
+	var partial *goose.PartialError
+
+	if errors.As(err, &partial) {
+		results = partial.Applied
+	}
+
+	applied := 0
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "%-4s %s (%s)\n", result.Direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+		applied++
+	}
+
+	if applied == 0 && err == nil {
+		fmt.Fprintln(os.Stdout, "no migrations to run")
+	}
+}
+
+func printStatus(ctx context.Context, migrator *db.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+
+	for _, status := range statuses {
+		appliedAt := "-"
+
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+	}
+
+	return writer.Flush()
+}
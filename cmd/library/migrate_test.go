@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrateUsage(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{nil, {"sideways"}, {"up", "down"}} {
+		require.ErrorContains(t, runMigrate(args), "usage: library migrate", args)
+	}
+}
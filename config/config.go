@@ -1,123 +1,252 @@
 package config
 
 import (
-	"fmt"
+	"errors"
 	"net"
+	"net/url"
 	"os"
-	"strconv"
 	"time"
 )
 
+// FileEnv names the YAML file New reads before the environment.
+const FileEnv = "CONFIG_FILE"
+
+// The fields are driven by their tags: env names the environment variable
+// and yaml the key in the file, default is the value used when neither sets
+// it and validate lists the rules the value must follow. Durations are
+// milliseconds when given as a bare number, as in the _MS variables, and Go
+// durations such as "1m30s" otherwise. Secret values are redacted when the
+// configuration is printed and can be read from the file named by the
+// variable with a _FILE suffix, or the key with a _file suffix.
 type (
 	Config struct {
-		GRPC struct {
-			Port        string `env:"GRPC_PORT"`
-			GatewayPort string `env:"GRPC_GATEWAY_PORT"`
-		}
+		GRPC          GRPC          `yaml:"grpc"`
+		TLS           TLS           `yaml:"tls"`
+		PG            Postgres      `yaml:"postgres"`
+		Outbox        Outbox        `yaml:"outbox"`
+		Library       Library       `yaml:"library"`
+		Idempotency   Idempotency   `yaml:"idempotency"`
+		Purge         Purge         `yaml:"purge"`
+		OAI           OAI           `yaml:"oai"`
+		OPDS          OPDS          `yaml:"opds"`
+		Auth          Auth          `yaml:"auth"`
+		Health        Health        `yaml:"health"`
+		Shutdown      Shutdown      `yaml:"shutdown"`
+		Observability Observability `yaml:"observability"`
+	}
 
-		PG struct {
-			URL      string
-			Host     string `env:"POSTGRES_HOST"`
-			Port     string `env:"POSTGRES_PORT"`
-			DB       string `env:"POSTGRES_DB"`
-			User     string `env:"POSTGRES_USER"`
-			Password string `env:"POSTGRES_PASSWORD"`
-		}
+	GRPC struct {
+		Port        string `env:"GRPC_PORT" yaml:"port" default:"9090" validate:"port"`
+		GatewayPort string `env:"GRPC_GATEWAY_PORT" yaml:"gateway_port" default:"8080" validate:"port"`
+	}
 
-		Outbox struct {
-			Enabled         bool          `env:"OUTBOX_ENABLED"`
-			Workers         int           `env:"OUTBOX_WORKERS"`
-			BatchSize       int           `env:"OUTBOX_BATCH_SIZE"`
-			WaitTimeMS      time.Duration `env:"OUTBOX_WAIT_TIME_MS"`
-			InProgressTTLMS time.Duration `env:"OUTBOX_IN_PROGRESS_TTL_MS"`
-			BookSendURL     string        `env:"OUTBOX_BOOK_SEND_URL"`
-			AuthorSendURL   string        `env:"OUTBOX_AUTHOR_SEND_URL"`
-		}
+	// TLS serves the gRPC and gateway listeners over TLS once CertFile is
+	// set. The Gateway fields configure the gateway as a client of the
+	// gRPC server, the Outbox fields the webhook client: its servers are
+	// verified against OutboxCAFile, the system roots when it is empty.
+	TLS struct {
+		CertFile          string        `env:"TLS_CERT_FILE" yaml:"cert_file"`
+		KeyFile           string        `env:"TLS_KEY_FILE" yaml:"key_file"`
+		ClientCAFile      string        `env:"TLS_CLIENT_CA_FILE" yaml:"client_ca_file"`
+		RequireClientCert bool          `env:"TLS_REQUIRE_CLIENT_CERT" yaml:"require_client_cert"`
+		ReloadIntervalMS  time.Duration `env:"TLS_RELOAD_INTERVAL_MS" yaml:"reload_interval_ms" default:"1m" validate:"positive"`
+
+		GatewayCAFile     string `env:"TLS_GATEWAY_CA_FILE" yaml:"gateway_ca_file"`
+		GatewayServerName string `env:"TLS_GATEWAY_SERVER_NAME" yaml:"gateway_server_name" default:"localhost"`
+		GatewayCertFile   string `env:"TLS_GATEWAY_CERT_FILE" yaml:"gateway_cert_file"`
+		GatewayKeyFile    string `env:"TLS_GATEWAY_KEY_FILE" yaml:"gateway_key_file"`
+
+		OutboxCAFile   string `env:"OUTBOX_TLS_CA_FILE" yaml:"outbox_ca_file"`
+		OutboxCertFile string `env:"OUTBOX_TLS_CERT_FILE" yaml:"outbox_cert_file"`
+		OutboxKeyFile  string `env:"OUTBOX_TLS_KEY_FILE" yaml:"outbox_key_file"`
+	}
 
-		Observability struct {
-			MetricsPort string `env:"METRICS_PORT"`
-			JaegerURL   string `env:"JAEGER_URL"`
-		}
+	Postgres struct {
+		// URL is built from the other fields.
+		URL      string `yaml:"-"`
+		Host     string `env:"POSTGRES_HOST" yaml:"host" default:"localhost" validate:"required"`
+		Port     string `env:"POSTGRES_PORT" yaml:"port" default:"5432" validate:"port"`
+		DB       string `env:"POSTGRES_DB" yaml:"db" validate:"required"`
+		User     string `env:"POSTGRES_USER" yaml:"user" validate:"required"`
+		Password string `env:"POSTGRES_PASSWORD" yaml:"password" secret:"true"`
+
+		SSLMode     string `env:"POSTGRES_SSLMODE" yaml:"sslmode" default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+		SSLRootCert string `env:"POSTGRES_SSLROOTCERT" yaml:"sslrootcert"`
+		SSLCert     string `env:"POSTGRES_SSLCERT" yaml:"sslcert"`
+		SSLKey      string `env:"POSTGRES_SSLKEY" yaml:"sslkey"`
+
+		// AutoMigrate applies the pending migrations on start. With it
+		// off, `library migrate up` has to run before the server starts.
+		AutoMigrate bool `env:"POSTGRES_AUTO_MIGRATE" yaml:"auto_migrate" default:"true"`
 	}
-)
 
-func New() (*Config, error) {
-	cfg := &Config{}
+	Outbox struct {
+		Enabled         bool          `env:"OUTBOX_ENABLED" yaml:"enabled"`
+		Workers         int           `env:"OUTBOX_WORKERS" yaml:"workers" default:"1"`
+		BatchSize       int           `env:"OUTBOX_BATCH_SIZE" yaml:"batch_size" default:"100"`
+		WaitTimeMS      time.Duration `env:"OUTBOX_WAIT_TIME_MS" yaml:"wait_time_ms" default:"1s"`
+		InProgressTTLMS time.Duration `env:"OUTBOX_IN_PROGRESS_TTL_MS" yaml:"in_progress_ttl_ms" default:"1m"`
+		BookSendURL     string        `env:"OUTBOX_BOOK_SEND_URL" yaml:"book_send_url" validate:"url"`
+		AuthorSendURL   string        `env:"OUTBOX_AUTHOR_SEND_URL" yaml:"author_send_url" validate:"url"`
+
+		BookMergeSendURL   string `env:"OUTBOX_BOOK_MERGE_SEND_URL" yaml:"book_merge_send_url" validate:"url"`
+		AuthorMergeSendURL string `env:"OUTBOX_AUTHOR_MERGE_SEND_URL" yaml:"author_merge_send_url" validate:"url"`
+	}
 
-	cfg.Observability.MetricsPort = os.Getenv("METRICS_PORT")
-	cfg.Observability.JaegerURL = os.Getenv("JAEGER_URL")
+	Library struct {
+		UniqueAuthorNames bool `env:"LIBRARY_UNIQUE_AUTHOR_NAMES" yaml:"unique_author_names"`
+	}
 
-	cfg.GRPC.Port = os.Getenv("GRPC_PORT")
-	cfg.GRPC.GatewayPort = os.Getenv("GRPC_GATEWAY_PORT")
+	Idempotency struct {
+		TTLMS time.Duration `env:"IDEMPOTENCY_TTL_MS" yaml:"ttl_ms" default:"24h" validate:"positive"`
+	}
+
+	// Purge empties the trash when enabled; the expired idempotency records
+	// are deleted every IntervalMS regardless.
+	Purge struct {
+		Enabled     bool          `env:"PURGE_ENABLED" yaml:"enabled"`
+		IntervalMS  time.Duration `env:"PURGE_INTERVAL_MS" yaml:"interval_ms" default:"1h" validate:"positive"`
+		RetentionMS time.Duration `env:"PURGE_RETENTION_MS" yaml:"retention_ms" default:"720h" validate:"positive"`
+	}
+
+	// OAI.BaseURL is the address harvesters are told to use in every
+	// response; the default matches the default gateway port.
+	OAI struct {
+		RepositoryName       string   `env:"OAI_REPOSITORY_NAME" yaml:"repository_name" default:"Library"`
+		BaseURL              string   `env:"OAI_BASE_URL" yaml:"base_url" default:"http://localhost:8080/oai" validate:"url"`
+		AdminEmails          []string `env:"OAI_ADMIN_EMAILS" yaml:"admin_emails"`
+		RepositoryIdentifier string   `env:"OAI_REPOSITORY_IDENTIFIER" yaml:"repository_identifier" default:"library"`
+		PageSize             int      `env:"OAI_PAGE_SIZE" yaml:"page_size" default:"100" validate:"positive"`
+	}
+
+	OPDS struct {
+		Title    string `env:"OPDS_TITLE" yaml:"title"`
+		PageSize int    `env:"OPDS_PAGE_SIZE" yaml:"page_size" default:"20" validate:"positive"`
+	}
+
+	// Auth requires every gRPC call, and so every gateway call, to carry a
+	// JWT verified against JWKSFile or an API key stored in Postgres. The
+	// OAI-PMH, OPDS and API documentation endpoints of the gateway require
+	// the reader role; only the health probes stay open.
+	Auth struct {
+		Enabled  bool   `env:"AUTH_ENABLED" yaml:"enabled"`
+		JWKSFile string `env:"AUTH_JWKS_FILE" yaml:"jwks_file"`
+		Issuer   string `env:"AUTH_JWT_ISSUER" yaml:"jwt_issuer"`
+		Audience string `env:"AUTH_JWT_AUDIENCE" yaml:"jwt_audience"`
+		APIKeys  bool   `env:"AUTH_API_KEYS_ENABLED" yaml:"api_keys_enabled"`
+	}
+
+	// Health runs the readiness checks every IntervalMS, each bounded by
+	// TimeoutMS. The outbox counts as stalled once no worker has run for
+	// OutboxStaleMS.
+	Health struct {
+		IntervalMS    time.Duration `env:"HEALTH_CHECK_INTERVAL_MS" yaml:"check_interval_ms" default:"5s" validate:"positive"`
+		TimeoutMS     time.Duration `env:"HEALTH_CHECK_TIMEOUT_MS" yaml:"check_timeout_ms" default:"2s" validate:"positive"`
+		OutboxStaleMS time.Duration `env:"HEALTH_OUTBOX_STALE_MS" yaml:"outbox_stale_ms" default:"1m" validate:"positive"`
+	}
+
+	// Shutdown waits DrainMS after reporting NOT_SERVING, so that load
+	// balancers stop routing calls, and then gives the servers and the
+	// workers TimeoutMS in total to stop. The workers and the tracer each
+	// keep FlushMS of it, however long the servers take.
+	Shutdown struct {
+		DrainMS   time.Duration `env:"SHUTDOWN_DRAIN_MS" yaml:"drain_ms" default:"3s"`
+		TimeoutMS time.Duration `env:"SHUTDOWN_TIMEOUT_MS" yaml:"timeout_ms" default:"30s" validate:"positive"`
+		FlushMS   time.Duration `env:"SHUTDOWN_FLUSH_MS" yaml:"flush_ms" default:"5s" validate:"positive"`
+	}
 
-	cfg.PG.Host = os.Getenv("POSTGRES_HOST")
-	cfg.PG.Port = os.Getenv("POSTGRES_PORT")
-	cfg.PG.DB = os.Getenv("POSTGRES_DB")
-	cfg.PG.User = os.Getenv("POSTGRES_USER")
-	cfg.PG.Password = os.Getenv("POSTGRES_PASSWORD")
+	Observability struct {
+		MetricsPort string `env:"METRICS_PORT" yaml:"metrics_port" default:"9000" validate:"port"`
+		JaegerURL   string `env:"JAEGER_URL" yaml:"jaeger_url" default:"http://localhost:14268/api/traces" validate:"url"`
+	}
+)
 
-	cfg.PG.URL = fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
-		cfg.PG.User,
-		cfg.PG.Password,
-		net.JoinHostPort(cfg.PG.Host, cfg.PG.Port),
-		cfg.PG.DB,
-	)
+// New reads the file FileEnv names, if any, and the environment.
+func New() (*Config, error) {
+	return Load(os.Getenv(FileEnv))
+}
 
-	var err error
-	cfg.Outbox.Enabled, err = strconv.ParseBool(os.Getenv("OUTBOX_ENABLED"))
+// Load reads the configuration and validates it.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.Outbox.Enabled {
-		cfg.Outbox.Workers, err = parseInt(os.Getenv("OUTBOX_WORKERS"))
-
-		if err != nil {
-			return nil, err
-		}
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
 
-		cfg.Outbox.BatchSize, err = parseInt(os.Getenv("OUTBOX_BATCH_SIZE"))
+	return cfg, nil
+}
 
-		if err != nil {
-			return nil, err
-		}
+// Validate checks the rules of the fields and the combinations of fields
+// that don't work together, and reports every violation at once.
+func (c *Config) Validate() error {
+	errs := validateFields(c)
 
-		cfg.Outbox.WaitTimeMS, err = parseTime(os.Getenv("OUTBOX_WAIT_TIME_MS"))
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	if (c.TLS.GatewayCertFile == "") != (c.TLS.GatewayKeyFile == "") {
+		errs = append(errs, errors.New("TLS_GATEWAY_CERT_FILE and TLS_GATEWAY_KEY_FILE must be set together"))
+	}
 
-		cfg.Outbox.InProgressTTLMS, err = parseTime(os.Getenv("OUTBOX_IN_PROGRESS_TTL_MS"))
+	if (c.TLS.OutboxCertFile == "") != (c.TLS.OutboxKeyFile == "") {
+		errs = append(errs, errors.New("OUTBOX_TLS_CERT_FILE and OUTBOX_TLS_KEY_FILE must be set together"))
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	if c.TLS.RequireClientCert && c.TLS.ClientCAFile == "" {
+		errs = append(errs, errors.New("TLS_REQUIRE_CLIENT_CERT needs TLS_CLIENT_CA_FILE"))
+	}
 
-		cfg.Outbox.BookSendURL = os.Getenv("OUTBOX_BOOK_SEND_URL")
-		cfg.Outbox.AuthorSendURL = os.Getenv("OUTBOX_AUTHOR_SEND_URL")
+	if c.TLS.RequireClientCert && c.TLS.GatewayCertFile == "" {
+		errs = append(errs, errors.New("TLS_REQUIRE_CLIENT_CERT needs TLS_GATEWAY_CERT_FILE for the gateway"))
 	}
 
-	return cfg, nil
-}
+	if c.Outbox.Enabled && (c.Outbox.Workers <= 0 || c.Outbox.BatchSize <= 0 || c.Outbox.WaitTimeMS <= 0 || c.Outbox.InProgressTTLMS <= 0) {
+		errs = append(errs, errors.New(
+			"OUTBOX_ENABLED needs positive OUTBOX_WORKERS, OUTBOX_BATCH_SIZE, OUTBOX_WAIT_TIME_MS and OUTBOX_IN_PROGRESS_TTL_MS"))
+	}
 
-func parseTime(s string) (time.Duration, error) {
-	t, err := parseInt(s)
+	if c.Auth.Enabled && c.Auth.JWKSFile == "" && !c.Auth.APIKeys {
+		errs = append(errs, errors.New("AUTH_ENABLED needs AUTH_JWKS_FILE or AUTH_API_KEYS_ENABLED"))
+	}
 
-	if err != nil {
-		return time.Duration(0), err
+	if c.Shutdown.DrainMS >= c.Shutdown.TimeoutMS {
+		errs = append(errs, errors.New("SHUTDOWN_DRAIN_MS must be shorter than SHUTDOWN_TIMEOUT_MS"))
+	} else if c.Shutdown.DrainMS+2*c.Shutdown.FlushMS >= c.Shutdown.TimeoutMS {
+		errs = append(errs, errors.New("SHUTDOWN_DRAIN_MS and twice SHUTDOWN_FLUSH_MS must be shorter than SHUTDOWN_TIMEOUT_MS"))
 	}
 
-	return time.Duration(t) * time.Millisecond, nil
+	return errors.Join(errs...)
 }
 
-func parseInt(s string) (int, error) {
-	str, err := strconv.ParseInt(s, 10, 64)
+// postgresURL passes the sslmode and the certificate paths on as libpq
+// parameters, which pgx understands.
+func postgresURL(cfg *Config) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.PG.SSLMode)
+
+	for name, value := range map[string]string{
+		"sslrootcert": cfg.PG.SSLRootCert,
+		"sslcert":     cfg.PG.SSLCert,
+		"sslkey":      cfg.PG.SSLKey,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
 
-	if err != nil {
-		return 0, err
+	result := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.PG.User, cfg.PG.Password),
+		Host:     net.JoinHostPort(cfg.PG.Host, cfg.PG.Port),
+		Path:     cfg.PG.DB,
+		RawQuery: query.Encode(),
 	}
 
-	return int(str), nil
+	return result.String()
 }
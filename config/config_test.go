@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The tests set variables, so they don't run in parallel.
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func minimalEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("POSTGRES_DB", "library")
+	t.Setenv("POSTGRES_USER", "library")
+}
+
+func TestDefaults(t *testing.T) {
+	minimalEnv(t)
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+
+	require.Equal(t, "9090", cfg.GRPC.Port)
+	require.Equal(t, "5432", cfg.PG.Port)
+	require.True(t, cfg.PG.AutoMigrate)
+	require.False(t, cfg.Outbox.Enabled, "unset OUTBOX_ENABLED is false")
+	require.Equal(t, 24*time.Hour, cfg.Idempotency.TTLMS)
+	require.Equal(t, 100, cfg.OAI.PageSize)
+	require.Equal(t, "http://localhost:8080/oai", cfg.OAI.BaseURL)
+	require.Equal(t, 5*time.Second, cfg.Shutdown.FlushMS)
+	require.Equal(t, "postgres://library:@localhost:5432/library?sslmode=disable", cfg.PG.URL)
+}
+
+func TestPrecedence(t *testing.T) {
+	minimalEnv(t)
+
+	path := writeFile(t, "library.yaml", `
+grpc:
+  port: 7000
+  gateway_port: 7001
+outbox:
+  enabled: true
+  wait_time_ms: 1500
+  in_progress_ttl_ms: 2m
+oai:
+  admin_emails: [a@example.org, b@example.org]
+opds:
+  title:
+`)
+
+	t.Setenv("GRPC_GATEWAY_PORT", "7002")
+	t.Setenv("OPDS_PAGE_SIZE", "")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "7000", cfg.GRPC.Port, "from the file")
+	require.Equal(t, "7002", cfg.GRPC.GatewayPort, "the environment overrides the file")
+	require.Equal(t, 20, cfg.OPDS.PageSize, "an empty variable is unset")
+	require.True(t, cfg.Outbox.Enabled)
+	require.Equal(t, 1500*time.Millisecond, cfg.Outbox.WaitTimeMS, "a bare number is milliseconds")
+	require.Equal(t, 2*time.Minute, cfg.Outbox.InProgressTTLMS)
+	require.Equal(t, []string{"a@example.org", "b@example.org"}, cfg.OAI.AdminEmails)
+
+	t.Setenv("OAI_ADMIN_EMAILS", "c@example.org, d@example.org,")
+
+	cfg, err = Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c@example.org", "d@example.org"}, cfg.OAI.AdminEmails)
+
+	t.Setenv("SHUTDOWN_FLUSH_MS", "15s")
+
+	_, err = Load(path)
+	require.ErrorContains(t, err, "twice SHUTDOWN_FLUSH_MS must be shorter than SHUTDOWN_TIMEOUT_MS")
+}
+
+func TestSecretFile(t *testing.T) {
+	minimalEnv(t)
+
+	secret := writeFile(t, "password", "s3cret\n")
+
+	t.Setenv("POSTGRES_PASSWORD_FILE", secret)
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", cfg.PG.Password)
+
+	t.Setenv("POSTGRES_PASSWORD", "other")
+
+	_, err = Load("")
+	require.ErrorContains(t, err, "set POSTGRES_PASSWORD or POSTGRES_PASSWORD_FILE, not both")
+
+	t.Setenv("POSTGRES_PASSWORD", "")
+	t.Setenv("POSTGRES_PASSWORD_FILE", "")
+
+	cfg, err = Load(writeFile(t, "library.yaml", "postgres:\n  password_file: "+secret+"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", cfg.PG.Password)
+
+	_, err = Load(writeFile(t, "library.yaml", "postgres:\n  user_file: "+secret+"\n"))
+	require.ErrorContains(t, err, "unknown key postgres.user_file", "only secrets are read from files")
+}
+
+func TestErrors(t *testing.T) {
+	t.Setenv("GRPC_PORT", "grpc")
+	t.Setenv("OUTBOX_WORKERS", "many")
+
+	_, err := Load(writeFile(t, "library.yaml", "outbox:\n  enabeld: true\n"))
+	require.ErrorContains(t, err, "unknown key outbox.enabeld")
+	require.ErrorContains(t, err, "outbox.workers (OUTBOX_WORKERS)", "reported with the unknown key")
+
+	t.Setenv("OUTBOX_WORKERS", "")
+	t.Setenv("OUTBOX_ENABLED", "true")
+	t.Setenv("OUTBOX_BATCH_SIZE", "0")
+	t.Setenv("OUTBOX_BOOK_SEND_URL", "library/books")
+	t.Setenv("POSTGRES_SSLMODE", "sometimes")
+	t.Setenv("TLS_CERT_FILE", "server.pem")
+	t.Setenv("SHUTDOWN_TIMEOUT_MS", "1s")
+
+	_, err = Load("")
+	require.Error(t, err)
+
+	for _, message := range []string{
+		`grpc.port (GRPC_PORT): "grpc" is not a port`,
+		"postgres.db (POSTGRES_DB): is required",
+		`postgres.sslmode (POSTGRES_SSLMODE): "sometimes" is not one of`,
+		`outbox.book_send_url (OUTBOX_BOOK_SEND_URL): "library/books" is not an absolute URL`,
+		"OUTBOX_ENABLED needs positive",
+		"TLS_CERT_FILE and TLS_KEY_FILE must be set together",
+		"SHUTDOWN_DRAIN_MS must be shorter than SHUTDOWN_TIMEOUT_MS",
+	} {
+		require.ErrorContains(t, err, message)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	minimalEnv(t)
+	t.Setenv("POSTGRES_PASSWORD", "s3cret")
+	t.Setenv("OAI_ADMIN_EMAILS", "a@example.org")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+
+	data, err := cfg.Redacted()
+	require.NoError(t, err)
+
+	require.NotContains(t, string(data), "s3cret")
+	require.Contains(t, string(data), "password: "+RedactedValue)
+	require.Contains(t, string(data), "ttl_ms: 24h0m0s")
+	require.Contains(t, string(data), "admin_emails: [a@example.org]")
+
+	t.Setenv("POSTGRES_PASSWORD", "")
+
+	printed, err := Load(writeFile(t, "library.yaml", string(data)))
+	require.NoError(t, err, "the printed configuration loads back")
+
+	cfg.PG.Password = RedactedValue
+	cfg.PG.URL = postgresURL(cfg)
+	require.Equal(t, cfg, printed)
+}
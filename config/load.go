@@ -0,0 +1,381 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// field is a setting of the configuration: Key is its path in the file,
+// such as "outbox.workers".
+type field struct {
+	Key   string
+	Env   string
+	Tag   reflect.StructTag
+	Value reflect.Value
+}
+
+func (f field) String() string {
+	if f.Env == "" {
+		return f.Key
+	}
+
+	return fmt.Sprintf("%s (%s)", f.Key, f.Env)
+}
+
+func (f field) secret() bool {
+	return f.Tag.Get("secret") == "true"
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fields lists the settings in the order of the struct, leaving out the
+// fields tagged yaml:"-".
+func fields(cfg *Config) []field {
+	var result []field
+
+	sections := reflect.ValueOf(cfg).Elem()
+
+	for i := range sections.NumField() {
+		section := sections.Field(i)
+		prefix := sections.Type().Field(i).Tag.Get("yaml")
+
+		for j := range section.NumField() {
+			structField := section.Type().Field(j)
+			name := structField.Tag.Get("yaml")
+
+			if name == "-" {
+				continue
+			}
+
+			result = append(result, field{
+				Key:   prefix + "." + name,
+				Env:   structField.Tag.Get("env"),
+				Tag:   structField.Tag,
+				Value: section.Field(j),
+			})
+		}
+	}
+
+	return result
+}
+
+// Read takes every setting from the environment, then from the file at
+// path, if any, and then from its default. It reports all the values it
+// can't parse and the keys of the file it doesn't know at once.
+func Read(path string) (*Config, error) {
+	file, err := readFile(path)
+
+	if file == nil && err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	errs := []error{err}
+
+	for _, f := range fields(cfg) {
+		value, ok, err := lookup(f, file)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f, err))
+			continue
+		}
+
+		if !ok {
+			value, ok = f.Tag.Lookup("default")
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err = set(f.Value, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	cfg.PG.URL = postgresURL(cfg)
+
+	return cfg, nil
+}
+
+// lookup finds the value of the field, as a string or, for a list in the
+// file, a []string. An empty variable counts as unset, the way Compose
+// passes on the variables it has no value for.
+func lookup(f field, file map[string]any) (any, bool, error) {
+	env := os.Getenv(f.Env)
+	envFile := ""
+
+	if f.secret() {
+		envFile = os.Getenv(f.Env + "_FILE")
+	}
+
+	switch {
+	case env != "" && envFile != "":
+		return nil, false, fmt.Errorf("set %s or %s_FILE, not both", f.Env, f.Env)
+	case env != "":
+		return env, true, nil
+	case envFile != "":
+		value, err := readSecret(envFile)
+		return value, err == nil, err
+	}
+
+	value, ok := file[f.Key]
+	valueFile, fromFile := file[f.Key+"_file"]
+
+	switch {
+	case ok && fromFile:
+		return nil, false, fmt.Errorf("set %s or %s_file, not both", f.Key, f.Key)
+	case ok:
+		return value, true, nil
+	case fromFile:
+		path, isString := valueFile.(string)
+
+		if !isString {
+			return nil, false, fmt.Errorf("%s_file must be a path", f.Key)
+		}
+
+		value, err := readSecret(path)
+
+		return value, err == nil, err
+	}
+
+	return nil, false, nil
+}
+
+// readSecret reads a secret from a file, such as a Docker or Kubernetes
+// secret, without the line break at its end.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readFile flattens the sections of the YAML file into a map by the key of
+// the fields, with the secret fields also known with a _file suffix. It
+// returns the keys it knows along with the errors about the others.
+func readFile(path string) (map[string]any, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sections map[string]map[string]any
+
+	if err = yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+
+	for _, f := range fields(&Config{}) {
+		known[f.Key] = f.Env != ""
+		known[f.Key+"_file"] = f.Env != "" && f.secret()
+	}
+
+	result := map[string]any{}
+
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(sections)) {
+		for _, key := range slices.Sorted(maps.Keys(sections[name])) {
+			value := sections[name][key]
+			key = name + "." + key
+
+			switch {
+			case value == nil:
+				// An empty key is unset, as an empty variable is.
+				continue
+			case !known[key]:
+				errs = append(errs, fmt.Errorf("%s: unknown key %s", path, key))
+				continue
+			}
+
+			if result[key], err = fileValue(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+			}
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// fileValue turns the scalars YAML decodes into strings, parsed as the
+// values of variables are.
+func fileValue(value any) (any, error) {
+	switch value := value.(type) {
+	case []any:
+		list := make([]string, 0, len(value))
+
+		for _, item := range value {
+			if _, ok := item.([]any); ok {
+				return nil, errors.New("nested lists are not supported")
+			}
+
+			list = append(list, fmt.Sprint(item))
+		}
+
+		return list, nil
+	case map[string]any:
+		return nil, errors.New("nested sections are not supported")
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+func set(target reflect.Value, value any) error {
+	if list, ok := value.([]string); ok {
+		if target.Kind() != reflect.Slice {
+			return errors.New("a list is not allowed here")
+		}
+
+		target.Set(reflect.ValueOf(list))
+
+		return nil
+	}
+
+	s, _ := value.(string)
+
+	switch {
+	case target.Type() == durationType:
+		duration, err := parseDuration(s)
+
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(int64(duration))
+	case target.Kind() == reflect.String:
+		target.SetString(s)
+	case target.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+
+		if err != nil {
+			return err
+		}
+
+		target.SetBool(b)
+	case target.Kind() == reflect.Int:
+		i, err := strconv.Atoi(s)
+
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(int64(i))
+	case target.Kind() == reflect.Slice:
+		var list []string
+
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+
+		target.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+
+	return nil
+}
+
+// parseDuration reads a bare number as milliseconds and anything else as a
+// Go duration.
+func parseDuration(s string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// validateFields applies the validate rules of the fields:
+//
+//   - required: the value is set;
+//   - port: a TCP port number;
+//   - url: an absolute URL, when set;
+//   - positive: a number or duration above zero;
+//   - oneof=a b: one of the space-separated values, when set.
+func validateFields(cfg *Config) []error {
+	var errs []error
+
+	for _, f := range fields(cfg) {
+		rules := f.Tag.Get("validate")
+
+		if rules == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			if err := validate(f.Value, rule); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validate(value reflect.Value, rule string) error {
+	name, argument, _ := strings.Cut(rule, "=")
+
+	switch name {
+	case "required":
+		if value.IsZero() {
+			return errors.New("is required")
+		}
+	case "port":
+		port, err := strconv.Atoi(value.String())
+
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%q is not a port", value.String())
+		}
+	case "url":
+		if value.String() == "" {
+			return nil
+		}
+
+		parsed, err := url.Parse(value.String())
+
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", value.String())
+		}
+	case "positive":
+		if value.Int() <= 0 {
+			return errors.New("must be positive")
+		}
+	case "oneof":
+		allowed := strings.Fields(argument)
+
+		if value.String() != "" && !slices.Contains(allowed, value.String()) {
+			return fmt.Errorf("%q is not one of %s", value.String(), strings.Join(allowed, ", "))
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+
+	return nil
+}
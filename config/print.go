@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces the secrets set in the printed configuration.
+const RedactedValue = "<redacted>"
+
+// Redacted renders the configuration as a YAML file Load accepts, in the
+// order of the struct, with the secrets replaced by RedactedValue.
+func (c *Config) Redacted() ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+
+	for _, f := range fields(c) {
+		name, key, _ := strings.Cut(f.Key, ".")
+		section, ok := sections[name]
+
+		if !ok {
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			sections[name] = section
+			root.Content = append(root.Content, scalar(name), section)
+		}
+
+		section.Content = append(section.Content, scalar(key), valueNode(f))
+	}
+
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+func valueNode(f field) *yaml.Node {
+	value := f.Value
+
+	switch {
+	case f.secret() && !value.IsZero():
+		return scalar(RedactedValue)
+	case value.Type() == durationType:
+		return scalar(time.Duration(value.Int()).String())
+	case value.Kind() == reflect.Slice:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+
+		for i := range value.Len() {
+			list.Content = append(list.Content, scalar(value.Index(i).String()))
+		}
+
+		return list
+	case value.Kind() == reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value.Bool())}
+	case value.Kind() == reflect.Int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(value.Int(), 10)}
+	default:
+		// Quoted, so that values such as ports read back as strings.
+		return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: fmt.Sprint(value.Interface())}
+	}
+}
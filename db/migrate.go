@@ -1,28 +1,181 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"embed"
-	"os"
+	"errors"
+	"fmt"
+	"io/fs"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
-	"go.uber.org/zap"
+	"github.com/pressly/goose/v3/lock"
 )
 
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
-func SetupPostgres(pool *pgxpool.Pool, logger *zap.Logger) {
-	goose.SetBaseFS(embedMigrations)
-	if err := goose.SetDialect("postgres"); err != nil {
-		logger.Error("can not set dialect in goose", zap.Error(err))
-		os.Exit(-1)
+// ErrPendingMigrations is reported while the database lags behind the
+// migrations embedded in the binary.
+var ErrPendingMigrations = errors.New("migrations are pending")
+
+// provider is the part of goose.Provider the migrator uses.
+type provider interface {
+	Up(ctx context.Context) ([]*goose.MigrationResult, error)
+	UpByOne(ctx context.Context) (*goose.MigrationResult, error)
+	Down(ctx context.Context) (*goose.MigrationResult, error)
+	Status(ctx context.Context) ([]*goose.MigrationStatus, error)
+	GetVersions(ctx context.Context) (current int64, target int64, err error)
+	HasPending(ctx context.Context) (bool, error)
+}
+
+// Migrator applies the migrations embedded in the binary, the SQL files and
+// goMigrations. The changes run under a Postgres advisory lock, so that
+// replicas starting together apply every migration once: the others wait
+// for the lock and find nothing left to do.
+type Migrator struct {
+	// provider takes the lock for every call; unlocked runs the steps of
+	// the changes that hold it across several calls.
+	provider provider
+	unlocked provider
+	lock     func(ctx context.Context, fn func() error) error
+	db       *sql.DB
+}
+
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
 	}
 
 	db := stdlib.OpenDBFromPool(pool)
-	if err := goose.Up(db, "migrations"); err != nil {
-		logger.Error("can not setup migrations", zap.Error(err))
-		os.Exit(-1)
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations,
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithGoMigrations(goMigrations()...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked, err := goose.NewProvider(goose.DialectPostgres, db, migrations,
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithGoMigrations(goMigrations()...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		provider: provider,
+		unlocked: unlocked,
+		lock:     sessionLock(db, locker),
+		db:       db,
+	}, nil
+}
+
+// sessionLock runs fn holding the advisory lock the provider takes, on a
+// connection of its own.
+func sessionLock(db *sql.DB, locker lock.SessionLocker) func(ctx context.Context, fn func() error) error {
+	return func(ctx context.Context, fn func() error) (err error) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		if err = locker.SessionLock(ctx, conn); err != nil {
+			return fmt.Errorf("can not lock migrations: %w", err)
+		}
+
+		defer func() {
+			if unlockErr := locker.SessionUnlock(context.WithoutCancel(ctx), conn); unlockErr != nil {
+				err = errors.Join(err, fmt.Errorf("can not unlock migrations: %w", unlockErr))
+			}
+		}()
+
+		return fn()
+	}
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down rolls the latest applied migration back.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// Redo rolls the latest applied migration back and applies it again, under
+// one lock so that no replica applies it in between.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	var results []*goose.MigrationResult
+
+	err := m.lock(ctx, func() error {
+		down, err := m.unlocked.Down(ctx)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, down)
+
+		up, err := m.unlocked.UpByOne(ctx)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, up)
+
+		return nil
+	})
+
+	return results, err
+}
+
+// Status lists the embedded migrations and whether they are applied.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// Version is the version the database is at and the version of the newest
+// embedded migration.
+func (m *Migrator) Version(ctx context.Context) (current int64, latest int64, err error) {
+	return m.provider.GetVersions(ctx)
+}
+
+// Check fails with ErrPendingMigrations unless every embedded migration is
+// applied. A database ahead of the binary passes: during a rolling update
+// the old replicas keep serving after the new ones have migrated.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("can not read migration version: %w", err)
+	}
+
+	if !pending {
+		return nil
 	}
+
+	current, latest, err := m.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPendingMigrations, err)
+	}
+
+	return fmt.Errorf("%w: database is at %d of %d", ErrPendingMigrations, current, latest)
+}
+
+// Close releases the database handle of the migrator; the pool stays open.
+func (m *Migrator) Close() error {
+	return m.db.Close()
 }
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider applies versions 1 to latest of a database at current, the
+// way goose does without out-of-order migrations.
+type fakeProvider struct {
+	current int64
+	latest  int64
+	err     error
+	// locked tells whether the migrator holds the lock, calls records the
+	// calls with it.
+	locked *bool
+	calls  []string
+}
+
+func (f *fakeProvider) record(call string) {
+	if f.locked != nil && *f.locked {
+		call += " locked"
+	}
+
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeProvider) Up(context.Context) ([]*goose.MigrationResult, error) {
+	f.record("up")
+	f.current = max(f.current, f.latest)
+
+	return nil, f.err
+}
+
+func (f *fakeProvider) UpByOne(context.Context) (*goose.MigrationResult, error) {
+	f.record("up by one")
+
+	if f.current >= f.latest {
+		return nil, goose.ErrNoNextVersion
+	}
+
+	f.current++
+
+	return &goose.MigrationResult{Direction: "up"}, f.err
+}
+
+func (f *fakeProvider) Down(context.Context) (*goose.MigrationResult, error) {
+	f.record("down")
+
+	if f.current == 0 {
+		return nil, goose.ErrNoNextVersion
+	}
+
+	f.current--
+
+	return &goose.MigrationResult{Direction: "down"}, nil
+}
+
+func (f *fakeProvider) Status(context.Context) ([]*goose.MigrationStatus, error) {
+	return nil, f.err
+}
+
+func (f *fakeProvider) GetVersions(context.Context) (int64, int64, error) {
+	return f.current, f.latest, f.err
+}
+
+func (f *fakeProvider) HasPending(context.Context) (bool, error) {
+	return f.current < f.latest, f.err
+}
+
+func TestNewMigrator(t *testing.T) {
+	t.Parallel()
+
+	// The pool connects on first use, the migrator doesn't use it here.
+	pool, err := pgxpool.New(t.Context(), "postgres://library@localhost:1/library")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	target, err := NewMigrator(pool)
+	require.NoError(t, err)
+
+	sources := target.provider.(*goose.Provider).ListSources()
+	last := sources[len(sources)-1]
+	require.Equal(t, int64(21), last.Version, "the Go migrations follow the SQL ones")
+	require.Equal(t, goose.TypeGo, last.Type)
+	require.Equal(t, int64(len(sources)), last.Version, "no version is skipped")
+}
+
+func TestKeysOf(t *testing.T) {
+	t.Parallel()
+
+	// lower() in the SQL backfill left "straße" and "σοφοσ" apart from
+	// what new rows get.
+	require.Equal(t, "strasse", keysOf("STRAßE").name)
+	require.Equal(t, keysOf("ΣΟΦΟΣ").name, keysOf("σοφος").name)
+	require.Equal(t, keysOf("Garcia Marquez"), keysOf("Garcia  Marquez"))
+}
+
+func TestMigratorCheck(t *testing.T) {
+	t.Parallel()
+
+	versionError := errors.New("relation goose_db_version does not exist")
+
+	tests := []struct {
+		name        string
+		provider    *fakeProvider
+		expectedErr error
+		message     string
+	}{
+		{name: "up to date", provider: &fakeProvider{current: 3, latest: 3}},
+		{
+			name:        "behind the binary",
+			provider:    &fakeProvider{current: 2, latest: 3},
+			expectedErr: ErrPendingMigrations,
+			message:     "database is at 2 of 3",
+		},
+		{name: "ahead of the binary", provider: &fakeProvider{current: 4, latest: 3}},
+		{
+			name:        "version failure",
+			provider:    &fakeProvider{err: versionError},
+			expectedErr: versionError,
+			message:     "can not read migration version",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := &Migrator{provider: test.provider}
+
+			err := target.Check(t.Context())
+			require.ErrorIs(t, err, test.expectedErr)
+
+			if test.message != "" {
+				require.ErrorContains(t, err, test.message)
+			}
+		})
+	}
+}
+
+func TestMigratorRedo(t *testing.T) {
+	t.Parallel()
+
+	locked := false
+	unlocked := &fakeProvider{current: 3, latest: 3, locked: &locked}
+
+	target := &Migrator{
+		provider: &fakeProvider{},
+		unlocked: unlocked,
+		lock: func(_ context.Context, fn func() error) error {
+			locked = true
+			defer func() { locked = false }()
+
+			return fn()
+		},
+	}
+
+	results, err := target.Redo(t.Context())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, []string{"down locked", "up by one locked"}, unlocked.calls, "one lock for both steps")
+	require.Equal(t, int64(3), unlocked.current)
+
+	unlocked.calls = nil
+	unlocked.current = 0
+
+	results, err = target.Redo(t.Context())
+	require.ErrorIs(t, err, goose.ErrNoNextVersion)
+	require.Empty(t, results)
+	require.Equal(t, []string{"down locked"}, unlocked.calls)
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE idempotency
+(
+    method       TEXT                    NOT NULL,
+    key          TEXT                    NOT NULL,
+    request_hash TEXT                    NOT NULL,
+    response     JSONB                   NOT NULL,
+    created_at   TIMESTAMP DEFAULT now() NOT NULL,
+    expires_at   TIMESTAMP               NOT NULL,
+    PRIMARY KEY (method, key)
+);
+
+CREATE INDEX index_idempotency_expires_at ON idempotency (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency;
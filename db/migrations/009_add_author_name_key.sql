@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE author ADD COLUMN name_key TEXT;
+
+-- Close to entity.NormalizeAuthorName; the application rewrites the key on
+-- every insert and rename, and migration 21 rewrites the keys set here.
+UPDATE author SET name_key = lower(regexp_replace(btrim(normalize(name, NFKC)), '\s+', ' ', 'g'));
+
+ALTER TABLE author ALTER COLUMN name_key SET NOT NULL;
+
+CREATE INDEX index_author_name_key ON author (name_key);
+CREATE INDEX index_author_name_key_trgm ON author USING gin (name_key gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS index_author_name_key_trgm;
+DROP INDEX IF EXISTS index_author_name_key;
+ALTER TABLE author DROP COLUMN IF EXISTS name_key;
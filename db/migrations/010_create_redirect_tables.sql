@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE author_redirect
+(
+    source_id  UUID PRIMARY KEY,
+    target_id  UUID                    NOT NULL REFERENCES author (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX index_author_redirect_target_id ON author_redirect (target_id);
+
+CREATE TABLE book_redirect
+(
+    source_id  UUID PRIMARY KEY,
+    target_id  UUID                    NOT NULL REFERENCES book (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX index_book_redirect_target_id ON book_redirect (target_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS book_redirect;
+DROP TABLE IF EXISTS author_redirect;
@@ -0,0 +1,153 @@
+-- +goose Up
+-- One sequence for all history tables orders the changes of a book and of
+-- its author links made in the same transaction.
+CREATE SEQUENCE history_id_seq;
+
+CREATE TABLE book_history
+(
+    id         BIGINT    DEFAULT nextval('history_id_seq') PRIMARY KEY,
+    book_id    UUID                    NOT NULL,
+    operation  TEXT                    NOT NULL,
+    actor      TEXT                    NOT NULL,
+    changed_at TIMESTAMP DEFAULT now() NOT NULL,
+    state      JSONB                   NOT NULL,
+    diff       JSONB                   NOT NULL
+);
+
+CREATE INDEX index_book_history_book_id ON book_history (book_id, changed_at);
+
+CREATE TABLE author_history
+(
+    id         BIGINT    DEFAULT nextval('history_id_seq') PRIMARY KEY,
+    author_id  UUID                    NOT NULL,
+    operation  TEXT                    NOT NULL,
+    actor      TEXT                    NOT NULL,
+    changed_at TIMESTAMP DEFAULT now() NOT NULL,
+    state      JSONB                   NOT NULL,
+    diff       JSONB                   NOT NULL
+);
+
+CREATE INDEX index_author_history_author_id ON author_history (author_id, changed_at);
+
+CREATE TABLE author_book_history
+(
+    id         BIGINT    DEFAULT nextval('history_id_seq') PRIMARY KEY,
+    author_id  UUID                    NOT NULL,
+    book_id    UUID                    NOT NULL,
+    operation  TEXT                    NOT NULL,
+    actor      TEXT                    NOT NULL,
+    changed_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX index_author_book_history_book_id ON author_book_history (book_id, changed_at);
+CREATE INDEX index_author_book_history_author_id ON author_book_history (author_id, changed_at);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_actor() RETURNS TEXT AS
+$$
+SELECT COALESCE(NULLIF(current_setting('library.actor', true), ''), current_user);
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_diff(old_row JSONB, new_row JSONB) RETURNS JSONB AS
+$$
+SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+FROM jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) AS key
+WHERE key NOT IN ('updated_at', 'name_key')
+  AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- record_history writes the state after the change (before it for DELETE) and
+-- the diff into <table>_history.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_history() RETURNS TRIGGER AS
+$$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+
+    IF history_diff(old_row, new_row) = '{}' THEN
+        RETURN NULL;
+    END IF;
+
+    EXECUTE format('INSERT INTO %I (%I, operation, actor, state, diff) VALUES ($1, $2, $3, $4, $5)',
+                   TG_TABLE_NAME || '_history', TG_TABLE_NAME || '_id')
+        USING (COALESCE(new_row, old_row) ->> 'id')::UUID, TG_OP, history_actor(),
+        COALESCE(new_row, old_row), history_diff(old_row, new_row);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_author_book_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO author_book_history (author_id, book_id, operation, actor)
+        VALUES (OLD.author_id, OLD.book_id, TG_OP, history_actor());
+    ELSE
+        INSERT INTO author_book_history (author_id, book_id, operation, actor)
+        VALUES (NEW.author_id, NEW.book_id, TG_OP, history_actor());
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trigger_book_history
+    AFTER INSERT OR UPDATE OR DELETE
+    ON book
+    FOR EACH ROW
+EXECUTE FUNCTION record_history();
+
+CREATE TRIGGER trigger_author_history
+    AFTER INSERT OR UPDATE OR DELETE
+    ON author
+    FOR EACH ROW
+EXECUTE FUNCTION record_history();
+
+CREATE TRIGGER trigger_author_book_history
+    AFTER INSERT OR DELETE
+    ON author_book
+    FOR EACH ROW
+EXECUTE FUNCTION record_author_book_history();
+
+-- Existing rows start their history at creation.
+INSERT INTO book_history (book_id, operation, actor, changed_at, state, diff)
+SELECT id, 'INSERT', 'migration', created_at, to_jsonb(book), history_diff(NULL, to_jsonb(book))
+FROM book;
+
+INSERT INTO author_history (author_id, operation, actor, changed_at, state, diff)
+SELECT id, 'INSERT', 'migration', created_at, to_jsonb(author), history_diff(NULL, to_jsonb(author))
+FROM author;
+
+INSERT INTO author_book_history (author_id, book_id, operation, actor, changed_at)
+SELECT ab.author_id, ab.book_id, 'INSERT', 'migration', b.created_at
+FROM author_book ab
+JOIN book b ON b.id = ab.book_id;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_author_book_history ON author_book;
+DROP TRIGGER IF EXISTS trigger_author_history ON author;
+DROP TRIGGER IF EXISTS trigger_book_history ON book;
+DROP FUNCTION IF EXISTS record_author_book_history;
+DROP FUNCTION IF EXISTS record_history;
+DROP FUNCTION IF EXISTS history_diff;
+DROP FUNCTION IF EXISTS history_actor;
+DROP TABLE IF EXISTS author_book_history;
+DROP TABLE IF EXISTS author_history;
+DROP TABLE IF EXISTS book_history;
+DROP SEQUENCE IF EXISTS history_id_seq;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE import_checkpoint
+(
+    import_id  TEXT PRIMARY KEY,
+    row_number INTEGER                 NOT NULL,
+    updated_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_checkpoint;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE book ADD COLUMN isbn TEXT NOT NULL DEFAULT '';
+ALTER TABLE book ADD COLUMN publisher TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX index_book_isbn ON book (isbn) WHERE isbn <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS index_book_isbn;
+ALTER TABLE book DROP COLUMN IF EXISTS publisher;
+ALTER TABLE book DROP COLUMN IF EXISTS isbn;
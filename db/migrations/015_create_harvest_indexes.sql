@@ -0,0 +1,9 @@
+-- +goose Up
+-- OAI-PMH harvests page through the books by datestamp and find the
+-- purged and merged ones by the DELETE in their history.
+CREATE INDEX index_book_updated_at ON book (updated_at, id);
+CREATE INDEX index_book_history_deleted ON book_history (book_id, changed_at) WHERE operation = 'DELETE';
+
+-- +goose Down
+DROP INDEX IF EXISTS index_book_history_deleted;
+DROP INDEX IF EXISTS index_book_updated_at;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- The OPDS search looks for the terms anywhere in the title.
+CREATE INDEX index_book_name_trgm ON book USING gin (lower(name) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS index_book_name_trgm;
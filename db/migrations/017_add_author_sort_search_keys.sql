@@ -0,0 +1,47 @@
+-- +goose Up
+ALTER TABLE author ADD COLUMN sort_key TEXT;
+ALTER TABLE author ADD COLUMN search_key TEXT;
+
+-- Close to entity.AuthorSearchKey and entity.AuthorSortKey without the
+-- Cyrillic and Greek tables, particles and suffixes; the application
+-- rewrites the keys on every insert and rename, and migration 21 rewrites
+-- the keys set here.
+UPDATE author
+SET search_key = btrim(regexp_replace(
+        regexp_replace(lower(normalize(name, NFKD)), '[\u0300-\u036f]', '', 'g'),
+        '[^[:alnum:]]+', ' ', 'g'));
+
+UPDATE author SET sort_key = regexp_replace(search_key, '^(.+) (\S+)$', '\2, \1');
+
+ALTER TABLE author ALTER COLUMN sort_key SET NOT NULL;
+ALTER TABLE author ALTER COLUMN search_key SET NOT NULL;
+
+CREATE INDEX index_author_sort_key ON author (sort_key, id) WHERE deleted_at IS NULL;
+CREATE INDEX index_author_search_key_trgm ON author USING gin (search_key gin_trgm_ops);
+
+-- The keys follow the name and are left out of the history like name_key.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_diff(old_row JSONB, new_row JSONB) RETURNS JSONB AS
+$$
+SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+FROM jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) AS key
+WHERE key NOT IN ('updated_at', 'name_key', 'sort_key', 'search_key')
+  AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_diff(old_row JSONB, new_row JSONB) RETURNS JSONB AS
+$$
+SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+FROM jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) AS key
+WHERE key NOT IN ('updated_at', 'name_key')
+  AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS index_author_search_key_trgm;
+DROP INDEX IF EXISTS index_author_sort_key;
+ALTER TABLE author DROP COLUMN IF EXISTS search_key;
+ALTER TABLE author DROP COLUMN IF EXISTS sort_key;
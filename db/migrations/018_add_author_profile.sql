@@ -0,0 +1,50 @@
+-- +goose Up
+ALTER TABLE author
+    ADD COLUMN biography            TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN birth_date           TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN death_date           TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN nationality          TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN alternate_names      TEXT[] DEFAULT '{}' NOT NULL,
+    ADD COLUMN alternate_search_key TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN pseudonym_of         UUID REFERENCES author (id) ON DELETE SET NULL,
+    ADD COLUMN viaf                 TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN isni                 TEXT   DEFAULT ''   NOT NULL,
+    ADD COLUMN wikidata_id          TEXT   DEFAULT ''   NOT NULL;
+
+CREATE INDEX index_author_pseudonym_of ON author (pseudonym_of) WHERE pseudonym_of IS NOT NULL;
+CREATE INDEX index_author_alternate_search_key_trgm ON author USING gin (alternate_search_key gin_trgm_ops);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_diff(old_row JSONB, new_row JSONB) RETURNS JSONB AS
+$$
+SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+FROM jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) AS key
+WHERE key NOT IN ('updated_at', 'name_key', 'sort_key', 'search_key', 'alternate_search_key')
+  AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION history_diff(old_row JSONB, new_row JSONB) RETURNS JSONB AS
+$$
+SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+FROM jsonb_object_keys(COALESCE(old_row, '{}') || COALESCE(new_row, '{}')) AS key
+WHERE key NOT IN ('updated_at', 'name_key', 'sort_key', 'search_key')
+  AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS index_author_alternate_search_key_trgm;
+DROP INDEX IF EXISTS index_author_pseudonym_of;
+ALTER TABLE author
+    DROP COLUMN IF EXISTS wikidata_id,
+    DROP COLUMN IF EXISTS isni,
+    DROP COLUMN IF EXISTS viaf,
+    DROP COLUMN IF EXISTS pseudonym_of,
+    DROP COLUMN IF EXISTS alternate_search_key,
+    DROP COLUMN IF EXISTS alternate_names,
+    DROP COLUMN IF EXISTS nationality,
+    DROP COLUMN IF EXISTS death_date,
+    DROP COLUMN IF EXISTS birth_date,
+    DROP COLUMN IF EXISTS biography;
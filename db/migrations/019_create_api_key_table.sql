@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE api_key
+(
+    id         UUID PRIMARY KEY,
+    name       TEXT                    NOT NULL,
+    subject    TEXT                    NOT NULL,
+    key_hash   TEXT                    NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT now() NOT NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_key;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE api_key
+    ADD COLUMN roles TEXT[] DEFAULT '{reader}' NOT NULL;
+
+-- +goose Down
+ALTER TABLE api_key
+    DROP COLUMN IF EXISTS roles;
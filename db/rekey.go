@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"github.com/project/library/internal/entity"
+)
+
+// goMigrations are the migrations that need the application code. Every
+// provider gets migrations of its own, goose links them to the others.
+func goMigrations() []*goose.Migration {
+	return []*goose.Migration{
+		goose.NewGoMigration(21, &goose.GoFunc{RunTx: rekeyAuthors}, nil),
+	}
+}
+
+// authorKeys are the columns derived from the name of an author.
+type authorKeys struct {
+	name   string
+	sort   string
+	search string
+}
+
+func keysOf(name string) authorKeys {
+	return authorKeys{
+		name:   entity.NormalizeAuthorName(name),
+		sort:   entity.AuthorSortKey(name),
+		search: entity.AuthorSearchKey(name),
+	}
+}
+
+// rekeyAuthors rewrites the keys migrations 9 and 17 filled in with SQL
+// approximations, such as lower() for case folding, with the functions the
+// application writes them with. Only the authors whose keys change are
+// updated.
+func rekeyAuthors(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name, name_key, sort_key, search_key FROM author`)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	changed := make(map[string]authorKeys)
+
+	for rows.Next() {
+		var (
+			id, name string
+			stored   authorKeys
+		)
+
+		if err = rows.Scan(&id, &name, &stored.name, &stored.sort, &stored.search); err != nil {
+			return err
+		}
+
+		if keys := keysOf(name); keys != stored {
+			changed[id] = keys
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	const request = `UPDATE author SET name_key = $1, sort_key = $2, search_key = $3 WHERE id = $4`
+
+	for id, keys := range changed {
+		if _, err = tx.ExecContext(ctx, request, keys.name, keys.sort, keys.search, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
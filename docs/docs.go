@@ -0,0 +1,9 @@
+// Package docs embeds the OpenAPI document generated from the proto.
+package docs
+
+import _ "embed"
+
+// OpenAPI is the Swagger 2.0 document of the REST gateway.
+//
+//go:embed spec/api/library/library.swagger.json
+var OpenAPI []byte
@@ -0,0 +1,111 @@
+// Package apidocs serves the OpenAPI document of the gateway with a Swagger
+// UI, and marks the routes kept from the first version of the REST API as
+// deprecated.
+package apidocs
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+const (
+	// SpecPath is the path of the OpenAPI document, UIPath of the Swagger UI.
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs/"
+
+	// LegacyPrefix is the prefix of the deprecated aliases of the routes.
+	LegacyPrefix = "/v1/library/"
+
+	// swaggerUIVersion is the swagger-ui-dist release the UI loads.
+	swaggerUIVersion = "5.17.14"
+)
+
+// New serves the document at SpecPath and the UI at UIPath. The operations
+// under LegacyPrefix are marked deprecated in the document.
+func New(spec []byte) (http.Handler, error) {
+	spec, err := markDeprecated(spec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+SpecPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec)
+	})
+
+	mux.HandleFunc("GET "+UIPath+"{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = uiTemplate.Execute(w, struct{ Version, SpecPath string }{swaggerUIVersion, SpecPath})
+	})
+
+	return mux, nil
+}
+
+// markDeprecated sets "deprecated" on every operation of the paths under
+// LegacyPrefix.
+func markDeprecated(spec []byte) ([]byte, error) {
+	var document map[string]any
+
+	if err := json.Unmarshal(spec, &document); err != nil {
+		return nil, err
+	}
+
+	paths, _ := document["paths"].(map[string]any)
+
+	for path, item := range paths {
+		if !strings.HasPrefix(path, LegacyPrefix) {
+			continue
+		}
+
+		operations, _ := item.(map[string]any)
+
+		for _, operation := range operations {
+			if operation, ok := operation.(map[string]any); ok {
+				operation["deprecated"] = true
+			}
+		}
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// Deprecate sets the Deprecation header (RFC 9745) on the responses to the
+// legacy routes, with a link to the documentation of their successors.
+func Deprecate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, LegacyPrefix) {
+			w.Header().Set("Deprecation", "@"+deprecatedSince)
+			w.Header().Set("Link", "<"+UIPath+`>; rel="deprecation"; type="text/html"`)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprecatedSince is the Unix time the legacy routes got their successors,
+// 2026-10-19.
+const deprecatedSince = "1792368000"
+
+var uiTemplate = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Library API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "{{.SpecPath}}", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,73 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/project/library/docs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	handler, err := New(docs.OpenAPI)
+	require.NoError(t, err)
+
+	t.Run("spec", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, SpecPath, nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var document struct {
+			Paths map[string]map[string]struct {
+				Deprecated bool `json:"deprecated"`
+			} `json:"paths"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+
+		require.True(t, document.Paths["/v1/library/book/{id}"]["delete"].Deprecated)
+		require.False(t, document.Paths["/v1/books/{id}"]["delete"].Deprecated)
+		require.Contains(t, document.Paths["/v1/books/{id}"], "patch")
+	})
+
+	t.Run("ui", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, UIPath, nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Contains(t, recorder.Body.String(), "swagger-ui-dist@"+swaggerUIVersion)
+		require.Contains(t, recorder.Body.String(), `\/openapi.json`)
+	})
+
+	_, err = New([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestDeprecate(t *testing.T) {
+	t.Parallel()
+
+	handler := Deprecate(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/v1/library/book/42", nil))
+
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, "@"+deprecatedSince, recorder.Header().Get("Deprecation"))
+	require.Contains(t, recorder.Header().Get("Link"), `rel="deprecation"`)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/v1/books/42", nil))
+
+	require.Empty(t, recorder.Header().Get("Deprecation"))
+}
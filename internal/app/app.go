@@ -1,8 +1,11 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -11,17 +14,28 @@ import (
 	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/project/library/internal/apidocs"
+	"github.com/project/library/internal/auth"
 	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/health"
+	"github.com/project/library/internal/interceptor"
+	"github.com/project/library/internal/oaipmh"
+	"github.com/project/library/internal/opds"
+	"github.com/project/library/internal/problem"
+	"github.com/project/library/internal/tlsconfig"
 	"github.com/project/library/internal/usecase/outbox"
+	"github.com/project/library/internal/usecase/purge"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/project/library/db"
+	"github.com/project/library/docs"
 
 	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/project/library/config"
@@ -29,12 +43,14 @@ import (
 	"github.com/project/library/internal/controller"
 	"github.com/project/library/internal/usecase/library"
 	"github.com/project/library/internal/usecase/repository"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 )
@@ -43,16 +59,37 @@ func Run(logger *zap.Logger, cfg *config.Config) {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	shutdown := initTracer(logger, cfg.Observability.JaegerURL)
-	defer func() {
-		err := shutdown(ctx)
+	shutdownTracer := initTracer(logger, cfg.Observability.JaegerURL)
+	metricsServer := runMetricsServer(logger, cfg.Observability.MetricsPort)
 
-		if err != nil {
-			logger.Error("can not shutdown jaeger collector", zap.Error(err))
-		}
-	}()
+	serverTLS, err := tlsconfig.Server(tlsconfig.ServerOptions{
+		CertFile:          cfg.TLS.CertFile,
+		KeyFile:           cfg.TLS.KeyFile,
+		ClientCAFile:      cfg.TLS.ClientCAFile,
+		RequireClientCert: cfg.TLS.RequireClientCert,
+		ReloadInterval:    cfg.TLS.ReloadIntervalMS,
+	})
+	if err != nil {
+		logger.Error("can not load server certificate", zap.Error(err))
+		return
+	}
 
-	go runMetricsServer(logger, cfg.Observability.MetricsPort)
+	gatewayCredentials, err := newGatewayCredentials(cfg, serverTLS != nil)
+	if err != nil {
+		logger.Error("can not load gateway certificate", zap.Error(err))
+		return
+	}
+
+	outboxTLS, err := tlsconfig.Client(tlsconfig.ClientOptions{
+		CAFile:         cfg.TLS.OutboxCAFile,
+		CertFile:       cfg.TLS.OutboxCertFile,
+		KeyFile:        cfg.TLS.OutboxKeyFile,
+		ReloadInterval: cfg.TLS.ReloadIntervalMS,
+	})
+	if err != nil {
+		logger.Error("can not load outbox certificate", zap.Error(err))
+		return
+	}
 
 	dbPool, err := pgxpool.New(ctx, cfg.PG.URL)
 	if err != nil {
@@ -61,32 +98,108 @@ func Run(logger *zap.Logger, cfg *config.Config) {
 	}
 	defer dbPool.Close()
 
-	db.SetupPostgres(dbPool, logger)
+	migrator, err := db.NewMigrator(dbPool)
+	if err != nil {
+		logger.Error("can not create migrator", zap.Error(err))
+		return
+	}
+	defer func() { _ = migrator.Close() }()
+
+	if err = migrate(ctx, cfg, logger, migrator); err != nil {
+		logger.Error("refusing to serve with a schema the binary doesn't match, run `library migrate up`", zap.Error(err))
+		return
+	}
 
-	repo := repository.NewPostgresRepository(logger, dbPool)
+	repo := repository.NewPostgresRepository(logger, dbPool,
+		repository.WithUniqueAuthorNames(cfg.Library.UniqueAuthorNames),
+	)
 	outboxRepository := repository.NewOutbox(dbPool)
 
+	idempotencyRepository := repository.NewIdempotency(cfg.Idempotency.TTLMS)
+	historyRepository := repository.NewHistory(dbPool)
+	catalogRepository := repository.NewCatalog(dbPool,
+		repository.WithUniqueAuthorNames(cfg.Library.UniqueAuthorNames),
+	)
+
+	// The workers and the gateway connection outlive the signal: the
+	// shutdown stops them in order.
+	workCtx, stopWork := context.WithCancel(context.Background())
+	defer stopWork()
+
 	transactor := repository.NewTransactor(dbPool)
-	runOutbox(ctx, cfg, logger, outboxRepository, transactor)
+	outboxService, outboxDone := runOutbox(workCtx, cfg, logger, outboxRepository, transactor, outboxTLS)
+	purgeDone := runPurge(workCtx, cfg, logger, repo, repo, idempotencyRepository, transactor)
 
-	useCases := library.New(logger, repo, repo, outboxRepository, transactor)
+	useCases := library.New(logger, repo, repo, outboxRepository, transactor, library.NewUUIDv7Generator(), idempotencyRepository, historyRepository, catalogRepository)
 
 	ctrl := controller.New(logger, useCases, useCases)
 
-	go runRest(ctx, cfg, logger)
-	go runGrpc(cfg, logger, ctrl)
+	authInterceptors, authHTTP, err := newAuthInterceptors(cfg, logger, repository.NewAPIKey(dbPool))
+	if err != nil {
+		logger.Error("can not set up authentication", zap.Error(err))
+		return
+	}
+
+	// Logging and metrics see the final status, also of the calls the
+	// authentication rejects; recovery covers everything after them.
+	interceptors := append([]serverInterceptor{
+		interceptor.NewLogging(logger),
+		interceptor.NewMetrics(prometheus.DefaultRegisterer),
+		interceptor.NewRecovery(logger),
+	}, authInterceptors...)
+
+	checker := newHealthChecker(cfg, logger, dbPool, migrator, outboxService)
+
+	checker.Start(ctx, cfg.Health.IntervalMS)
+
+	gatewayServer := runRest(workCtx, cfg, logger, useCases, useCases, checker, authHTTP, serverTLS, gatewayCredentials)
+	grpcServer := runGrpc(cfg, logger, ctrl, checker, serverTLS, interceptors...)
 
 	<-ctx.Done()
-	const param = 3
-	time.Sleep(time.Second * param)
+	logger.Info("shutting down")
+
+	// The gateway goes before the gRPC server it forwards to, and the
+	// servers before the workers: a call that is let in may still write to
+	// the outbox.
+	shutdown(logger, cfg.Shutdown.TimeoutMS,
+		shutdownStep{name: "health", stop: func(ctx context.Context) error {
+			checker.Shutdown()
+			return sleep(ctx, cfg.Shutdown.DrainMS)
+		}},
+		shutdownStep{name: "gateway", stop: gatewayServer.Shutdown},
+		shutdownStep{name: "grpc", stop: func(ctx context.Context) error {
+			return gracefulStop(ctx, grpcServer)
+		}},
+		shutdownStep{name: "workers", reserve: cfg.Shutdown.FlushMS, stop: func(ctx context.Context) error {
+			stopWork()
+			return wait(ctx, outboxDone, purgeDone)
+		}},
+		shutdownStep{name: "metrics", stop: metricsServer.Shutdown},
+		shutdownStep{name: "tracer", reserve: cfg.Shutdown.FlushMS, stop: shutdownTracer},
+		shutdownStep{name: "database", stop: func(context.Context) error {
+			dbPool.Close()
+			return nil
+		}},
+	)
 }
 
-func runMetricsServer(logger *zap.Logger, port string) {
-	http.Handle("/metrics", promhttp.Handler())
-	err := http.ListenAndServe(":"+port, nil)
-	if err != nil {
-		logger.Error("Metrics server error", zap.Error(err))
+func runMetricsServer(logger *zap.Logger, port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
 	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server error", zap.Error(err))
+		}
+	}()
+
+	return server
 }
 
 func initTracer(l *zap.Logger, url string) func(context.Context) error {
@@ -115,7 +228,8 @@ func runOutbox(
 	logger *zap.Logger,
 	outboxRepository repository.OutboxRepository,
 	transactor repository.Transactor,
-) {
+	tlsConfig *tls.Config,
+) (outbox.Outbox, *sync.WaitGroup) {
 	const (
 		timeoutConst               time.Duration = 30
 		keepAliveConst             time.Duration = 180
@@ -138,27 +252,132 @@ func runOutbox(
 		TLSHandshakeTimeout:   tlSHandshakeTimeoutConst * time.Second,
 		ExpectContinueTimeout: expectContinueTimeoutConst * time.Second,
 		MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
+		TLSClientConfig:       tlsConfig,
 	}
 
 	client := new(http.Client)
 	client.Transport = transport
 
-	globalHandler := globalOutboxHandler(client, cfg.Outbox.BookSendURL, cfg.Outbox.AuthorSendURL)
+	globalHandler := globalOutboxHandler(
+		client,
+		cfg.Outbox.BookSendURL,
+		cfg.Outbox.AuthorSendURL,
+		cfg.Outbox.BookMergeSendURL,
+		cfg.Outbox.AuthorMergeSendURL,
+	)
 	outboxService := outbox.New(logger, outboxRepository, globalHandler, cfg, transactor)
 
-	outboxService.Start(
+	// OUTBOX_WORKERS has a default, but a disabled outbox runs none.
+	workers := cfg.Outbox.Workers
+
+	if !cfg.Outbox.Enabled {
+		workers = 0
+	}
+
+	done := outboxService.Start(
 		ctx,
-		cfg.Outbox.Workers,
+		workers,
 		cfg.Outbox.BatchSize,
 		cfg.Outbox.WaitTimeMS,
 		cfg.Outbox.InProgressTTLMS,
 	)
+
+	return outboxService, done
+}
+
+// newHealthChecker makes the service ready once Postgres answers, its schema
+// is at the migrations of the binary and, when enabled, the outbox workers
+// keep running.
+func newHealthChecker(
+	cfg *config.Config,
+	logger *zap.Logger,
+	dbPool *pgxpool.Pool,
+	migrator *db.Migrator,
+	outboxService outbox.Outbox,
+) *health.Checker {
+	opts := []health.Option{
+		health.WithService(generated.Library_ServiceDesc.ServiceName),
+		health.WithTimeout(cfg.Health.TimeoutMS),
+		health.WithCheck("database", dbPool.Ping),
+		health.WithCheck("migrations", migrator.Check),
+	}
+
+	if cfg.Outbox.Enabled {
+		opts = append(opts, health.WithCheck("outbox", func(context.Context) error {
+			if since := time.Since(outboxService.Heartbeat()); since > cfg.Health.OutboxStaleMS {
+				return fmt.Errorf("no worker has run for %s", since.Round(time.Second))
+			}
+
+			return nil
+		}))
+	}
+
+	return health.New(logger.Named("health"), opts...)
+}
+
+// migrate applies the pending migrations when POSTGRES_AUTO_MIGRATE is on
+// and then checks the schema has every migration of the binary.
+func migrate(ctx context.Context, cfg *config.Config, logger *zap.Logger, migrator *db.Migrator) error {
+	if cfg.PG.AutoMigrate {
+		results, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			logger.Info("migration applied",
+				zap.Int64("version", result.Source.Version),
+				zap.Duration("duration", result.Duration),
+			)
+		}
+	}
+
+	if err := migrator.Check(ctx); err != nil {
+		return err
+	}
+
+	current, latest, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current > latest {
+		logger.Warn("database schema is newer than the binary",
+			zap.Int64("version", current),
+			zap.Int64("latest", latest),
+		)
+	}
+
+	return nil
+}
+
+func runPurge(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *zap.Logger,
+	authorRepository repository.AuthorRepository,
+	booksRepository repository.BooksRepository,
+	idempotencyRepository repository.IdempotencyRepository,
+	transactor repository.Transactor,
+) *sync.WaitGroup {
+	// The expired idempotency records go on every tick; the trash only when
+	// the purge is enabled.
+	var retention time.Duration
+
+	if cfg.Purge.Enabled {
+		retention = cfg.Purge.RetentionMS
+	}
+
+	return purge.New(logger, authorRepository, booksRepository, idempotencyRepository, transactor).
+		Start(ctx, cfg.Purge.IntervalMS, retention)
 }
 
 func globalOutboxHandler(
 	client *http.Client,
 	bookURL string,
 	authorURL string,
+	bookMergeURL string,
+	authorMergeURL string,
 ) outbox.GlobalHandler {
 	return func(kind repository.OutboxKind) (outbox.KindHandler, error) {
 		switch kind {
@@ -166,6 +385,10 @@ func globalOutboxHandler(
 			return bookOutboxHandler(client, bookURL), nil
 		case repository.OutboxKindAuthor:
 			return authorOutboxHandler(client, authorURL), nil
+		case repository.OutboxKindBookMerge:
+			return mergeOutboxHandler(client, bookMergeURL), nil
+		case repository.OutboxKindAuthorMerge:
+			return mergeOutboxHandler(client, authorMergeURL), nil
 		default:
 			return nil, fmt.Errorf("unsupported outbox kind: %d", kind)
 		}
@@ -228,9 +451,52 @@ func bookOutboxHandler(client *http.Client, url string) outbox.KindHandler {
 	}
 }
 
-func runRest(ctx context.Context, cfg *config.Config, logger *zap.Logger) {
-	mux := grpcRuntime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+// mergeOutboxHandler posts the merge as JSON, the receiver needs both the
+// sources and the target.
+func mergeOutboxHandler(client *http.Client, url string) outbox.KindHandler {
+	return func(_ context.Context, data []byte) (txErr error) {
+		merge := entity.Merge{}
+		err := json.Unmarshal(data, &merge)
+
+		if err != nil {
+			return fmt.Errorf("can not deserialize data in merge outbox handler: %w", err)
+		}
+
+		response, err := client.Post(url, "application/json", bytes.NewReader(data)) //nolint:bodyclose // Because I already do it
+		if err != nil {
+			return err
+		}
+
+		defer func(closer io.ReadCloser) {
+			if err := closer.Close(); err != nil {
+				txErr = fmt.Errorf("can not close merge outbox: %w", err)
+			}
+		}(response.Body)
+
+		const httpRequestNumber int = 2
+		if response.StatusCode/100 != httpRequestNumber {
+			return fmt.Errorf("failure code: %d", response.StatusCode)
+		}
+		return nil
+	}
+}
+
+func runRest(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *zap.Logger,
+	books library.BooksUseCase,
+	authors library.AuthorUseCase,
+	checker *health.Checker,
+	authenticate func(http.Handler) http.Handler,
+	tlsConfig *tls.Config,
+	grpcCredentials credentials.TransportCredentials,
+) *http.Server {
+	mux := grpcRuntime.NewServeMux(
+		grpcRuntime.WithIncomingHeaderMatcher(headerMatcher),
+		grpcRuntime.WithErrorHandler(problem.ErrorHandler),
+	)
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(grpcCredentials)}
 
 	address := "localhost:" + cfg.GRPC.Port
 	err := generated.RegisterLibraryHandlerFromEndpoint(ctx, mux, address, opts)
@@ -240,15 +506,155 @@ func runRest(ctx context.Context, cfg *config.Config, logger *zap.Logger) {
 		os.Exit(-1)
 	}
 
+	spec, err := apidocs.New(docs.OpenAPI)
+
+	if err != nil {
+		logger.Error("can not load openapi document", zap.Error(err))
+		os.Exit(-1)
+	}
+
+	// OAI-PMH, OPDS, the API documentation and the probes are plain HTTP
+	// next to the gateway, not gRPC methods, so they authenticate on their
+	// own. The probes stay open to the orchestrator.
+	handler := http.NewServeMux()
+	handler.Handle("/", apidocs.Deprecate(mux))
+	handler.Handle(apidocs.SpecPath, authenticate(spec))
+	handler.Handle(apidocs.UIPath, authenticate(spec))
+	handler.Handle(health.LivenessPath, checker.Liveness())
+	handler.Handle(health.ReadinessPath, checker.Readiness())
+	handler.Handle("/oai", authenticate(oaipmh.New(logger, books, oaipmh.Identity{
+		RepositoryName:       cfg.OAI.RepositoryName,
+		BaseURL:              cfg.OAI.BaseURL,
+		AdminEmails:          cfg.OAI.AdminEmails,
+		RepositoryIdentifier: cfg.OAI.RepositoryIdentifier,
+	}, oaipmh.WithPageSize(cfg.OAI.PageSize))))
+
+	catalog := authenticate(opds.New(logger, books, authors, opds.WithTitle(cfg.OPDS.Title), opds.WithPageSize(cfg.OPDS.PageSize)))
+	handler.Handle(opds.Prefix, catalog)
+	handler.Handle(opds.Prefix+"/", catalog)
+
 	gatewayPort := ":" + cfg.GRPC.GatewayPort
 	logger.Info("gateway listening at port", zap.String("port", gatewayPort))
 
-	if err = http.ListenAndServe(gatewayPort, mux); err != nil {
-		logger.Error("gateway listen error", zap.Error(err))
+	server := &http.Server{
+		Addr:              gatewayPort,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
+	}
+
+	go func() {
+		var err error
+
+		if tlsConfig != nil {
+			// The certificate comes from TLSConfig.GetCertificate.
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("gateway listen error", zap.Error(err))
+		}
+	}()
+
+	return server
+}
+
+const gatewayReadHeaderTimeout = 10 * time.Second
+
+// newGatewayCredentials secures the hop from the gateway to the gRPC server
+// when the gRPC server is served over TLS.
+func newGatewayCredentials(cfg *config.Config, grpcTLS bool) (credentials.TransportCredentials, error) {
+	if !grpcTLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := tlsconfig.Client(tlsconfig.ClientOptions{
+		CAFile:         cfg.TLS.GatewayCAFile,
+		ServerName:     cfg.TLS.GatewayServerName,
+		CertFile:       cfg.TLS.GatewayCertFile,
+		KeyFile:        cfg.TLS.GatewayKeyFile,
+		ReloadInterval: cfg.TLS.ReloadIntervalMS,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// headerMatcher forwards the Idempotency-Key, X-Actor, Authorization and
+// X-Api-Key headers in addition to the headers grpc-gateway forwards by
+// default.
+func headerMatcher(key string) (string, bool) {
+	switch http.CanonicalHeaderKey(key) {
+	case controller.IdempotencyKeyHeader, controller.ActorHeader, auth.AuthorizationHeader, auth.APIKeyHeader:
+		return strings.ToLower(key), true
 	}
+
+	return grpcRuntime.DefaultHeaderMatcher(key)
 }
 
-func runGrpc(cfg *config.Config, logger *zap.Logger, libraryService generated.LibraryServer) {
+// serverInterceptor intercepts both unary and streaming calls.
+type serverInterceptor interface {
+	Unary() grpc.UnaryServerInterceptor
+	Stream() grpc.StreamServerInterceptor
+}
+
+// newAuthInterceptors authenticates the calls and then checks the roles the
+// proto options of the methods require. The HTTP wrapper does the same for
+// the plain HTTP handlers of the gateway, which read the catalog and so
+// require the reader role. It returns no interceptors and a wrapper that
+// changes nothing when authentication is disabled.
+func newAuthInterceptors(
+	cfg *config.Config,
+	logger *zap.Logger,
+	apiKeys repository.APIKeyRepository,
+) ([]serverInterceptor, func(http.Handler) http.Handler, error) {
+	if !cfg.Auth.Enabled {
+		logger.Warn("authentication is disabled, the gRPC server and the gateway are open")
+		return nil, func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	var authenticators []auth.Authenticator
+
+	if cfg.Auth.JWKSFile != "" {
+		keys, err := auth.LoadKeySet(cfg.Auth.JWKSFile)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(keys,
+			auth.WithIssuer(cfg.Auth.Issuer),
+			auth.WithAudience(cfg.Auth.Audience),
+		))
+	}
+
+	if cfg.Auth.APIKeys {
+		authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(apiKeys))
+	}
+
+	authenticator := auth.New(logger, authenticators)
+	authorizer := auth.NewAuthorizer(logger, auth.LibraryPolicy())
+
+	authHTTP := func(next http.Handler) http.Handler {
+		return authenticator.HTTP(authorizer.HTTP(entity.RoleReader, next))
+	}
+
+	return []serverInterceptor{authenticator, authorizer}, authHTTP, nil
+}
+
+func runGrpc(
+	cfg *config.Config,
+	logger *zap.Logger,
+	libraryService generated.LibraryServer,
+	checker *health.Checker,
+	tlsConfig *tls.Config,
+	interceptors ...serverInterceptor,
+) *grpc.Server {
 	port := ":" + cfg.GRPC.Port
 	lis, err := net.Listen("tcp", port)
 
@@ -257,25 +663,44 @@ func runGrpc(cfg *config.Config, logger *zap.Logger, libraryService generated.Li
 		os.Exit(-1)
 	}
 
-	s := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			otelgrpc.UnaryServerInterceptor(
-				otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
-			),
+	unary := []grpc.UnaryServerInterceptor{
+		otelgrpc.UnaryServerInterceptor(
+			otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
 		),
-		grpc.StreamInterceptor(
-			otelgrpc.StreamServerInterceptor(
-				otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
-			),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		otelgrpc.StreamServerInterceptor(
+			otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
 		),
-	)
+	}
+
+	for _, i := range interceptors {
+		unary = append(unary, i.Unary())
+		stream = append(stream, i.Stream())
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(serverOptions...)
 	reflection.Register(s)
 
 	generated.RegisterLibraryServer(s, libraryService)
+	checker.Register(s)
 
 	logger.Info("grpc server listening at port", zap.String("port", port))
 
-	if err = s.Serve(lis); err != nil {
-		logger.Error("grpc server listen error", zap.Error(err))
-	}
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			logger.Error("grpc server listen error", zap.Error(err))
+		}
+	}()
+
+	return s
 }
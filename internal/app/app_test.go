@@ -45,6 +45,12 @@ func TestGlobalOutboxHandler(t *testing.T) {
 			kind:              repository.OutboxKindBook,
 			globalExpectedErr: nil,
 		},
+		{
+			name:              "success merge global handler",
+			client:            successBookClient,
+			kind:              repository.OutboxKindBookMerge,
+			globalExpectedErr: nil,
+		},
 		{
 			name:              "failure global handler",
 			client:            successBookClient,
@@ -55,7 +61,7 @@ func TestGlobalOutboxHandler(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			globalOutboxHand := globalOutboxHandler(test.client, bookURLTest, authorURLTest)
+			globalOutboxHand := globalOutboxHandler(test.client, bookURLTest, authorURLTest, bookURLTest, authorURLTest)
 			require.NotNil(t, globalOutboxHand)
 			_, err := globalOutboxHand(test.kind)
 			require.Equal(t, err, test.globalExpectedErr)
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// shutdownStep is one stage of the shutdown. It gives up once the context
+// is done. The steps before it leave it reserve of the timeout, such as the
+// time to flush the spans.
+type shutdownStep struct {
+	name    string
+	stop    func(ctx context.Context) error
+	reserve time.Duration
+}
+
+// shutdown runs the steps in order within the timeout. Each step has until
+// the timeout less the reserves of the steps after it, and at least its own
+// reserve. A failed or timed out step is logged and the next one still
+// runs: the later steps release resources the earlier ones can't hold on to
+// anyway.
+func shutdown(logger *zap.Logger, timeout time.Duration, steps ...shutdownStep) {
+	deadline := time.Now().Add(timeout)
+
+	for i, step := range steps {
+		start := time.Now()
+		stepDeadline := deadline
+
+		for _, later := range steps[i+1:] {
+			stepDeadline = stepDeadline.Add(-later.reserve)
+		}
+
+		if reserved := start.Add(step.reserve); reserved.After(stepDeadline) {
+			stepDeadline = reserved
+		}
+
+		ctx, cancel := context.WithDeadline(context.Background(), stepDeadline)
+		err := step.stop(ctx)
+
+		cancel()
+
+		if err != nil {
+			logger.Error("shutdown step failed", zap.String("step", step.name), zap.Error(err))
+			continue
+		}
+
+		logger.Info("shutdown step done", zap.String("step", step.name), zap.Duration("duration", time.Since(start)))
+	}
+}
+
+// sleep waits for the duration unless the context is done first.
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// gracefulStop lets the server finish the running calls and streams, and
+// cancels those still running when the context is done.
+func gracefulStop(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-stopped
+
+		return ctx.Err()
+	}
+}
+
+// wait waits for the wait groups unless the context is done first.
+func wait(ctx context.Context, groups ...*sync.WaitGroup) error {
+	done := make(chan struct{})
+
+	go func() {
+		for _, group := range groups {
+			group.Wait()
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	var order []string
+
+	step := func(name string, err error) shutdownStep {
+		return shutdownStep{name: name, stop: func(context.Context) error {
+			order = append(order, name)
+			return err
+		}}
+	}
+
+	shutdown(zap.New(core), time.Second,
+		step("gateway", nil),
+		step("grpc", errors.New("listener closed")),
+		shutdownStep{name: "workers", stop: func(ctx context.Context) error {
+			order = append(order, "workers")
+			<-ctx.Done()
+
+			return ctx.Err()
+		}},
+		step("database", nil),
+	)
+
+	require.Equal(t, []string{"gateway", "grpc", "workers", "database"}, order, "a failed step doesn't stop the next ones")
+	require.Len(t, logs.FilterMessage("shutdown step failed").All(), 2)
+	require.Equal(t, "database", logs.FilterMessage("shutdown step done").All()[1].ContextMap()["step"])
+}
+
+func TestShutdownReserve(t *testing.T) {
+	t.Parallel()
+
+	var left []time.Duration
+
+	waiting := func(name string, reserve time.Duration) shutdownStep {
+		return shutdownStep{name: name, reserve: reserve, stop: func(ctx context.Context) error {
+			deadline, _ := ctx.Deadline()
+			left = append(left, time.Until(deadline))
+			<-ctx.Done()
+
+			return ctx.Err()
+		}}
+	}
+
+	shutdown(zap.NewNop(), 300*time.Millisecond,
+		waiting("grpc", 0),
+		waiting("workers", 100*time.Millisecond),
+		shutdownStep{name: "slow", stop: func(context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}},
+		waiting("tracer", 50*time.Millisecond),
+	)
+
+	require.Len(t, left, 3)
+	require.InDelta(t, 150*time.Millisecond, left[0], float64(30*time.Millisecond), "grpc leaves the reserves of the later steps")
+	require.InDelta(t, 100*time.Millisecond, left[1], float64(30*time.Millisecond), "workers leave the tracer its reserve")
+	require.InDelta(t, 50*time.Millisecond, left[2], float64(30*time.Millisecond), "the tracer keeps its reserve past the timeout")
+}
+
+func TestSleep(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, sleep(t.Context(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.ErrorIs(t, sleep(ctx, time.Hour), context.Canceled)
+}
+
+func TestWait(t *testing.T) {
+	t.Parallel()
+
+	done := new(sync.WaitGroup)
+	running := new(sync.WaitGroup)
+	running.Add(1)
+
+	require.NoError(t, wait(t.Context(), done))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, wait(ctx, done, running), context.DeadlineExceeded)
+	running.Done()
+}
+
+func TestGracefulStop(t *testing.T) {
+	t.Parallel()
+
+	serve := func(t *testing.T) (*grpc.Server, healthpb.HealthClient) {
+		t.Helper()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		server := grpc.NewServer()
+		healthpb.RegisterHealthServer(server, health.NewServer())
+
+		go func() { _ = server.Serve(listener) }()
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		return server, healthpb.NewHealthClient(conn)
+	}
+
+	t.Run("idle", func(t *testing.T) {
+		t.Parallel()
+
+		server, client := serve(t)
+
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+
+		require.NoError(t, gracefulStop(t.Context(), server))
+	})
+
+	t.Run("stream outlives the deadline", func(t *testing.T) {
+		t.Parallel()
+
+		server, client := serve(t)
+
+		stream, err := client.Watch(t.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, gracefulStop(ctx, server), context.DeadlineExceeded)
+
+		_, err = stream.Recv()
+		require.Error(t, err, "the stream is cut once the deadline passes")
+	})
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ Authenticator = (*APIKeyAuthenticator)(nil)
+
+// apiKeyPrefix marks the keys this service issues, so a leaked key is easy
+// to recognize.
+const apiKeyPrefix = "lib_"
+
+// NewAPIKey returns a random key and the hash it is stored by.
+func NewAPIKey() (key string, keyHash string, err error) {
+	const keyBytes = 32
+	random := make([]byte, keyBytes)
+
+	if _, err = rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey hashes a key for storage and lookup. The keys are random, so a
+// plain SHA-256 is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyAuthenticator accepts a key from "X-Api-Key: <key>" or
+// "Authorization: ApiKey <key>". The principal has the subject and the roles
+// of the key.
+type APIKeyAuthenticator struct {
+	repository repository.APIKeyRepository
+	now        func() time.Time
+}
+
+func NewAPIKeyAuthenticator(repository repository.APIKeyRepository) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{
+		repository: repository,
+		now:        time.Now,
+	}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, md metadata.MD) (entity.Principal, error) {
+	key, ok := apiKey(md)
+
+	if !ok {
+		return entity.Principal{}, ErrNoCredentials
+	}
+
+	stored, err := a.repository.GetAPIKey(ctx, HashAPIKey(key))
+
+	switch {
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		return entity.Principal{}, fmt.Errorf("%w: unknown key", ErrInvalidAPIKey)
+	case err != nil:
+		return entity.Principal{}, err
+	case !stored.Active(a.now()):
+		return entity.Principal{}, fmt.Errorf("%w: key %s is revoked or expired", ErrInvalidAPIKey, stored.ID)
+	}
+
+	return entity.Principal{Subject: stored.Subject, Method: entity.AuthMethodAPIKey, Roles: stored.Roles}, nil
+}
+
+func apiKey(md metadata.MD) (string, bool) {
+	if values := md.Get(APIKeyHeader); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		return strings.TrimSpace(values[0]), true
+	}
+
+	return credentials(md, apiKeyScheme)
+}
@@ -0,0 +1,172 @@
+// Package auth authenticates gRPC calls and the plain HTTP requests served
+// next to the gateway. The interceptors try the authenticators in turn and
+// put the principal of the first one that recognizes the credentials into
+// the context of the call.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/library"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AuthorizationHeader carries "Bearer <jwt>" or "ApiKey <key>".
+	AuthorizationHeader = "Authorization"
+	// APIKeyHeader carries a bare API key. The gateway forwards both headers
+	// as gRPC metadata.
+	APIKeyHeader = "X-Api-Key"
+
+	bearerScheme = "bearer"
+	apiKeyScheme = "apikey"
+)
+
+var (
+	// ErrNoCredentials tells the interceptor to try the next authenticator.
+	ErrNoCredentials   = errors.New("no credentials")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+	ErrUnauthenticated = errors.New("authentication required")
+)
+
+// Authenticator checks one kind of credentials in the metadata of a call.
+// It returns ErrNoCredentials when the call carries none of its kind.
+type Authenticator interface {
+	Authenticate(ctx context.Context, md metadata.MD) (entity.Principal, error)
+}
+
+type (
+	Option func(options *options)
+
+	options struct {
+		publicMethods []string
+	}
+)
+
+// WithPublicMethods lets calls to the methods through without credentials.
+// A method ending with "/" stands for every method of the service.
+func WithPublicMethods(methods ...string) Option {
+	return func(options *options) {
+		options.publicMethods = append(options.publicMethods, methods...)
+	}
+}
+
+type Interceptor struct {
+	logger         *zap.Logger
+	authenticators []Authenticator
+	options
+}
+
+// New accepts a call if one of the authenticators does. The reflection and
+// health services are public.
+func New(logger *zap.Logger, authenticators []Authenticator, opts ...Option) *Interceptor {
+	i := &Interceptor{
+		logger:         logger,
+		authenticators: authenticators,
+		options: options{publicMethods: []string{
+			"/grpc.reflection.v1.ServerReflection/",
+			"/grpc.reflection.v1alpha.ServerReflection/",
+			"/grpc.health.v1.Health/",
+		}},
+	}
+
+	for _, opt := range opts {
+		opt(&i.options)
+	}
+
+	return i
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(stream.Context(), info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if i.public(method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, authenticator := range i.authenticators {
+		principal, err := authenticator.Authenticate(ctx, md)
+
+		switch {
+		case err == nil:
+			return library.ContextWithPrincipal(ctx, principal), nil
+		case errors.Is(err, ErrNoCredentials):
+			continue
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidAPIKey):
+			i.logger.Info("authentication failed", zap.String("method", method), zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			i.logger.Error("can not authenticate", zap.String("method", method), zap.Error(err))
+			return nil, status.Error(codes.Internal, "can not authenticate")
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+}
+
+func (i *Interceptor) public(method string) bool {
+	for _, public := range i.publicMethods {
+		if method == public || strings.HasSuffix(public, "/") && strings.HasPrefix(method, public) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serverStream replaces the context of the stream with the authenticated
+// one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// credentials returns the credentials of the scheme from the Authorization
+// metadata.
+func credentials(md metadata.MD, scheme string) (string, bool) {
+	for _, value := range md.Get(AuthorizationHeader) {
+		name, credentials, ok := strings.Cut(strings.TrimSpace(value), " ")
+
+		if ok && strings.EqualFold(name, scheme) {
+			return strings.TrimSpace(credentials), true
+		}
+	}
+
+	return "", false
+}
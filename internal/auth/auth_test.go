@@ -0,0 +1,425 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/project/library/generated/mocks"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/library"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	now    = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	secret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+func encode(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, header map[string]any, claims map[string]any, key []byte) string {
+	t.Helper()
+
+	signed := encode(t, header) + "." + encode(t, claims)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, header map[string]any, claims map[string]any, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	signed := encode(t, header) + "." + encode(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func keySet(t *testing.T, rsaKey *rsa.PrivateKey) *KeySet {
+	t.Helper()
+
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": %q},
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": %q, "e": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256"}
+	]}`,
+		base64.RawURLEncoding.EncodeToString(secret),
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	)
+
+	keys, err := ParseKeySet([]byte(jwks))
+	require.NoError(t, err)
+	require.Len(t, keys.keys, 2)
+
+	return keys
+}
+
+func bearer(token string) metadata.MD {
+	return metadata.Pairs("authorization", "Bearer "+token)
+}
+
+func TestParseKeySet(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseKeySet([]byte(`{"keys": []}`))
+	require.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`{"keys": [{"kty": "oct", "k": "!"}]}`))
+	require.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	target := NewJWTAuthenticator(keySet(t, rsaKey),
+		WithIssuer("https://issuer"),
+		WithAudience("library"),
+		WithLeeway(time.Minute),
+		WithClock(func() time.Time { return now }),
+	)
+
+	claims := func(overrides map[string]any) map[string]any {
+		result := map[string]any{
+			"sub":   "alice",
+			"iss":   "https://issuer",
+			"aud":   []string{"library", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"nbf":   now.Add(-time.Hour).Unix(),
+			"roles": []string{"librarian", "owner"},
+		}
+
+		for key, value := range overrides {
+			if value == nil {
+				delete(result, key)
+			} else {
+				result[key] = value
+			}
+		}
+
+		return result
+	}
+
+	hs256 := map[string]any{"alg": "HS256", "kid": "hmac"}
+	rs256 := map[string]any{"alg": "RS256", "kid": "rsa"}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"hs256", signHS256(t, hs256, claims(nil), secret), nil},
+		{"rs256", signRS256(t, rs256, claims(nil), rsaKey), nil},
+		{"no kid", signRS256(t, map[string]any{"alg": "RS256"}, claims(nil), rsaKey), nil},
+		{"single audience and role", signHS256(t, hs256, claims(map[string]any{"aud": "library", "roles": "librarian"}), secret), nil},
+		{"within leeway", signHS256(t, hs256, claims(map[string]any{"exp": now.Add(-time.Second).Unix()}), secret), nil},
+		{"wrong secret", signHS256(t, hs256, claims(nil), []byte("other")), ErrInvalidToken},
+		{"wrong rsa key", signRS256(t, rs256, claims(nil), otherKey), ErrInvalidToken},
+		{"unknown kid", signHS256(t, map[string]any{"alg": "HS256", "kid": "missing"}, claims(nil), secret), ErrInvalidToken},
+		{"alg none", encode(t, map[string]any{"alg": "none"}) + "." + encode(t, claims(nil)) + ".", ErrInvalidToken},
+		{"expired", signHS256(t, hs256, claims(map[string]any{"exp": now.Add(-time.Hour).Unix()}), secret), ErrInvalidToken},
+		{"not yet valid", signHS256(t, hs256, claims(map[string]any{"nbf": now.Add(time.Hour).Unix()}), secret), ErrInvalidToken},
+		{"wrong issuer", signHS256(t, hs256, claims(map[string]any{"iss": "https://other"}), secret), ErrInvalidToken},
+		{"wrong audience", signHS256(t, hs256, claims(map[string]any{"aud": "other"}), secret), ErrInvalidToken},
+		{"no subject", signHS256(t, hs256, claims(map[string]any{"sub": nil}), secret), ErrInvalidToken},
+		{"malformed", "abc", ErrInvalidToken},
+	}
+
+	for _, test := range tests {
+		principal, err := target.Authenticate(t.Context(), bearer(test.token))
+
+		if test.err != nil {
+			require.ErrorIs(t, err, test.err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		require.Equal(t, entity.Principal{Subject: "alice", Method: entity.AuthMethodJWT, Roles: []entity.Role{entity.RoleLibrarian}}, principal, test.name)
+	}
+
+	_, err = target.Authenticate(t.Context(), metadata.MD{})
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	_, err = target.Authenticate(t.Context(), metadata.Pairs("authorization", "ApiKey key"))
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	key, keyHash, err := NewAPIKey()
+	require.NoError(t, err)
+	require.Equal(t, HashAPIKey(key), keyHash)
+
+	control := gomock.NewController(t)
+	repository := mocks.NewMockAPIKeyRepository(control)
+
+	target := NewAPIKeyAuthenticator(repository)
+	target.now = func() time.Time { return now }
+
+	repository.EXPECT().GetAPIKey(gomock.Any(), keyHash).Return(entity.APIKey{ID: "1", Subject: "ci", Roles: []entity.Role{entity.RoleReader}}, nil).Times(2)
+
+	principal, err := target.Authenticate(t.Context(), metadata.Pairs("x-api-key", key))
+	require.NoError(t, err)
+	require.Equal(t, entity.Principal{Subject: "ci", Method: entity.AuthMethodAPIKey, Roles: []entity.Role{entity.RoleReader}}, principal)
+
+	_, err = target.Authenticate(t.Context(), metadata.Pairs("authorization", "ApiKey "+key))
+	require.NoError(t, err)
+
+	repository.EXPECT().GetAPIKey(gomock.Any(), HashAPIKey("revoked")).Return(entity.APIKey{ID: "2", RevokedAt: now}, nil)
+	_, err = target.Authenticate(t.Context(), metadata.Pairs("x-api-key", "revoked"))
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	repository.EXPECT().GetAPIKey(gomock.Any(), HashAPIKey("expired")).Return(entity.APIKey{ID: "3", ExpiresAt: now}, nil)
+	_, err = target.Authenticate(t.Context(), metadata.Pairs("x-api-key", "expired"))
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	repository.EXPECT().GetAPIKey(gomock.Any(), HashAPIKey("unknown")).Return(entity.APIKey{}, entity.ErrAPIKeyNotFound)
+	_, err = target.Authenticate(t.Context(), metadata.Pairs("x-api-key", "unknown"))
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	_, err = target.Authenticate(t.Context(), bearer("token"))
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+type stubAuthenticator struct {
+	principal entity.Principal
+	err       error
+}
+
+func (s stubAuthenticator) Authenticate(context.Context, metadata.MD) (entity.Principal, error) {
+	return s.principal, s.err
+}
+
+type authenticatorFunc func(ctx context.Context, md metadata.MD) (entity.Principal, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, md metadata.MD) (entity.Principal, error) {
+	return f(ctx, md)
+}
+
+type stubStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stubStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptor(t *testing.T) {
+	t.Parallel()
+
+	alice := entity.Principal{Subject: "alice", Method: entity.AuthMethodJWT}
+	info := &grpc.UnaryServerInfo{FullMethod: "/library.Library/GetBookInfo"}
+
+	var seen entity.Principal
+	handler := func(ctx context.Context, _ any) (any, error) {
+		seen, _ = library.PrincipalFromContext(ctx)
+		return "ok", nil
+	}
+
+	target := New(zaptest.NewLogger(t), []Authenticator{
+		stubAuthenticator{err: ErrNoCredentials},
+		stubAuthenticator{principal: alice},
+	})
+
+	response, err := target.Unary()(t.Context(), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", response)
+	require.Equal(t, alice, seen)
+
+	err = target.Stream()(nil, stubStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/library.Library/ExportCatalog"},
+		func(_ any, stream grpc.ServerStream) error {
+			seen, _ = library.PrincipalFromContext(stream.Context())
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, alice, seen)
+
+	anonymous := New(zaptest.NewLogger(t), []Authenticator{stubAuthenticator{err: ErrNoCredentials}},
+		WithPublicMethods("/library.Library/GetBookInfo"))
+
+	_, err = anonymous.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/library.Library/AddBook"}, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = anonymous.Unary()(t.Context(), nil, info, handler)
+	require.NoError(t, err)
+
+	_, err = anonymous.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}, handler)
+	require.NoError(t, err)
+
+	_, err = anonymous.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	require.NoError(t, err)
+
+	rejecting := New(zaptest.NewLogger(t), []Authenticator{
+		stubAuthenticator{err: ErrInvalidToken},
+		stubAuthenticator{principal: alice},
+	})
+
+	_, err = rejecting.Unary()(t.Context(), nil, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	failing := New(zaptest.NewLogger(t), []Authenticator{stubAuthenticator{err: context.DeadlineExceeded}})
+
+	_, err = failing.Unary()(t.Context(), nil, info, handler)
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestLibraryPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := LibraryPolicy()
+	require.Len(t, policy, 20)
+	require.Equal(t, entity.RoleReader, policy["/library.Library/GetBookInfo"])
+	require.Equal(t, entity.RoleLibrarian, policy["/library.Library/UpdateBook"])
+	require.Equal(t, entity.RoleLibrarian, policy["/library.Library/ChangeAuthorInfo"])
+	require.Equal(t, entity.RoleAdmin, policy["/library.Library/ImportCatalog"])
+}
+
+func TestAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.InfoLevel)
+	target := NewAuthorizer(zap.New(core), Policy{
+		"/library.Library/GetBookInfo": entity.RoleReader,
+		"/library.Library/UpdateBook":  entity.RoleLibrarian,
+	})
+
+	reader := library.ContextWithPrincipal(t.Context(), entity.Principal{
+		Subject: "integration",
+		Method:  entity.AuthMethodAPIKey,
+		Roles:   []entity.Role{entity.RoleReader},
+	})
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	_, err := target.Unary()(reader, nil, &grpc.UnaryServerInfo{FullMethod: "/library.Library/GetBookInfo"}, handler)
+	require.NoError(t, err)
+
+	_, err = target.Unary()(reader, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}, handler)
+	require.NoError(t, err)
+
+	_, err = target.Unary()(reader, nil, &grpc.UnaryServerInfo{FullMethod: "/library.Library/UpdateBook"}, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = target.Stream()(nil, stubStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/library.Library/GetBookInfo"},
+		func(any, grpc.ServerStream) error { return nil })
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	require.Equal(t, "audit", entries[0].LoggerName)
+	require.Equal(t, "permission denied", entries[0].Message)
+	require.Equal(t, map[string]any{
+		"subject":       "integration",
+		"auth_method":   "api_key",
+		"roles":         []any{"reader"},
+		"method":        "/library.Library/UpdateBook",
+		"required_role": "librarian",
+	}, entries[0].ContextMap())
+}
+
+func TestHTTP(t *testing.T) {
+	t.Parallel()
+
+	reader := entity.Principal{Subject: "reader", Method: entity.AuthMethodAPIKey, Roles: []entity.Role{entity.RoleReader}}
+
+	var seen metadata.MD
+
+	authenticator := New(zaptest.NewLogger(t), []Authenticator{
+		authenticatorFunc(func(ctx context.Context, md metadata.MD) (entity.Principal, error) {
+			seen = md
+
+			switch {
+			case len(md.Get(APIKeyHeader)) == 0:
+				return entity.Principal{}, ErrNoCredentials
+			case md.Get(APIKeyHeader)[0] == "reader":
+				return reader, nil
+			case md.Get(APIKeyHeader)[0] == "nobody":
+				return entity.Principal{Subject: "nobody"}, nil
+			default:
+				return entity.Principal{}, ErrInvalidAPIKey
+			}
+		}),
+	})
+	authorizer := NewAuthorizer(zaptest.NewLogger(t), Policy{})
+
+	handler := authenticator.HTTP(authorizer.HTTP(entity.RoleReader, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		principal, _ := library.PrincipalFromContext(req.Context())
+		_, _ = w.Write([]byte(principal.Subject))
+	})))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+		body   string
+	}{
+		{name: "reader", key: "reader", status: http.StatusOK, body: "reader"},
+		{name: "no credentials", status: http.StatusUnauthorized},
+		{name: "invalid key", key: "invalid", status: http.StatusUnauthorized},
+		{name: "no role", key: "nobody", status: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/opds", nil)
+		req.Header.Set("Authorization", "Bearer token")
+
+		if test.key != "" {
+			req.Header.Set(APIKeyHeader, test.key)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		require.Equal(t, test.status, recorder.Code, test.name)
+		require.Equal(t, []string{"Bearer token"}, seen.Get(AuthorizationHeader), test.name)
+
+		if test.body != "" {
+			require.Equal(t, test.body, recorder.Body.String(), test.name)
+		}
+
+		if test.status == http.StatusUnauthorized {
+			require.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"), test.name)
+		}
+	}
+}
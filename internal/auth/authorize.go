@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	generated "github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/library"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Policy maps full gRPC method names to the least role allowed to call
+// them.
+type Policy map[string]entity.Role
+
+// LibraryPolicy reads the policy from the (library.required_role) options
+// of the Library service. A method without the option is left to admins.
+func LibraryPolicy() Policy {
+	return ServicePolicy(generated.File_api_library_library_proto.Services().ByName("Library"))
+}
+
+func ServicePolicy(service protoreflect.ServiceDescriptor) Policy {
+	policy := make(Policy)
+	methods := service.Methods()
+
+	for i := range methods.Len() {
+		method := methods.Get(i)
+		role := entity.RoleAdmin
+
+		if required, ok := proto.GetExtension(method.Options(), generated.E_RequiredRole).(generated.Role); ok {
+			if parsed, err := entity.ParseRole(strings.TrimPrefix(required.String(), "ROLE_")); err == nil {
+				role = parsed
+			}
+		}
+
+		policy["/"+string(service.FullName())+"/"+string(method.Name())] = role
+	}
+
+	return policy
+}
+
+// Authorizer lets a call through when the principal has a role the policy
+// requires for the method. It runs after the authentication interceptor;
+// calls the policy doesn't know, such as reflection, pass unchecked.
+type Authorizer struct {
+	audit  *zap.Logger
+	policy Policy
+}
+
+// NewAuthorizer writes an audit entry for every denied call to the "audit"
+// logger.
+func NewAuthorizer(logger *zap.Logger, policy Policy) *Authorizer {
+	return &Authorizer{
+		audit:  logger.Named("audit"),
+		policy: policy,
+	}
+}
+
+func (a *Authorizer) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authorizer) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authorize(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+func (a *Authorizer) authorize(ctx context.Context, method string) error {
+	required, ok := a.policy[method]
+
+	if !ok {
+		return nil
+	}
+
+	return a.require(ctx, method, required)
+}
+
+// require checks the principal of the context against the role and audits
+// a denial of method.
+func (a *Authorizer) require(ctx context.Context, method string, required entity.Role) error {
+	principal, _ := library.PrincipalFromContext(ctx)
+
+	if principal.HasRole(required) {
+		return nil
+	}
+
+	roles := make([]string, len(principal.Roles))
+
+	for i, role := range principal.Roles {
+		roles[i] = string(role)
+	}
+
+	a.audit.Warn("permission denied",
+		zap.String("subject", principal.Subject),
+		zap.String("auth_method", string(principal.Method)),
+		zap.Strings("roles", roles),
+		zap.String("method", method),
+		zap.String("required_role", string(required)),
+	)
+
+	return status.Errorf(codes.PermissionDenied, "%s requires the %s role", method, required)
+}
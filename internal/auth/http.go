@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/project/library/internal/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HTTP authenticates plain HTTP requests, such as the OAI-PMH and OPDS ones
+// served next to the gateway, with the same authenticators as the gRPC
+// calls. The credentials come from the Authorization and X-Api-Key headers
+// and the request path stands for the method.
+func (i *Interceptor) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		md := metadata.MD{}
+
+		for _, name := range []string{AuthorizationHeader, APIKeyHeader} {
+			if values := req.Header.Values(name); len(values) > 0 {
+				md.Set(name, values...)
+			}
+		}
+
+		ctx, err := i.authenticate(metadata.NewIncomingContext(req.Context(), md), req.URL.Path)
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// HTTP lets a request through when the principal has the role, which the
+// handler as a whole requires. It runs after the authentication handler.
+func (a *Authorizer) HTTP(required entity.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := a.require(req.Context(), req.URL.Path, required); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// writeError answers with the HTTP status of the gRPC status of err.
+func writeError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	code := http.StatusInternalServerError
+
+	switch s.Code() {
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+
+		w.Header().Set("WWW-Authenticate", `Bearer, ApiKey`)
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	}
+
+	http.Error(w, s.Message(), code)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+const (
+	algHS256 = "HS256"
+	algRS256 = "RS256"
+
+	ktyOct = "oct"
+	ktyRSA = "RSA"
+)
+
+// jsonWebKey is the part of RFC 7517 needed for HS256 and RS256.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// verificationKey is a key of the set ready to check signatures; secret is
+// set for HS256 and public for RS256.
+type verificationKey struct {
+	id     string
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+// KeySet holds the keys JWTs are verified against.
+type KeySet struct {
+	keys []verificationKey
+}
+
+// LoadKeySet reads a JWKS file. Symmetric ("oct") keys verify HS256 tokens
+// and RSA keys verify RS256 tokens; encryption keys and keys of other types
+// are skipped.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("can not read jwks: %w", err)
+	}
+
+	return ParseKeySet(data)
+}
+
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("can not parse jwks: %w", err)
+	}
+
+	set := &KeySet{}
+
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		verification, ok, err := parseKey(key)
+
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", key.Kid, err)
+		}
+
+		if ok {
+			set.keys = append(set.keys, verification)
+		}
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("jwks has no %s or %s keys", algHS256, algRS256)
+	}
+
+	return set, nil
+}
+
+func parseKey(key jsonWebKey) (verificationKey, bool, error) {
+	switch key.Kty {
+	case ktyOct:
+		if key.Alg != "" && key.Alg != algHS256 {
+			return verificationKey{}, false, nil
+		}
+
+		secret, err := base64.RawURLEncoding.DecodeString(key.K)
+
+		if err != nil || len(secret) == 0 {
+			return verificationKey{}, false, errors.New("invalid k")
+		}
+
+		return verificationKey{id: key.Kid, alg: algHS256, secret: secret}, true, nil
+	case ktyRSA:
+		if key.Alg != "" && key.Alg != algRS256 {
+			return verificationKey{}, false, nil
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+
+		if err != nil || len(n) == 0 {
+			return verificationKey{}, false, errors.New("invalid n")
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return verificationKey{}, false, errors.New("invalid e")
+		}
+
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+
+		return verificationKey{id: key.Kid, alg: algRS256, public: public}, true, nil
+	default:
+		return verificationKey{}, false, nil
+	}
+}
+
+// candidates returns the keys that may have signed a token with the header;
+// all the keys of the algorithm when the token names no key.
+func (s *KeySet) candidates(alg string, kid string) []verificationKey {
+	var result []verificationKey
+
+	for _, key := range s.keys {
+		if key.alg == alg && (kid == "" || key.id == kid) {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/project/library/internal/entity"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ Authenticator = (*JWTAuthenticator)(nil)
+
+type (
+	JWTOption func(options *jwtOptions)
+
+	jwtOptions struct {
+		issuer   string
+		audience string
+		leeway   time.Duration
+		now      func() time.Time
+	}
+)
+
+// WithIssuer rejects tokens with another "iss" claim.
+func WithIssuer(issuer string) JWTOption {
+	return func(options *jwtOptions) {
+		options.issuer = issuer
+	}
+}
+
+// WithAudience rejects tokens whose "aud" claim doesn't name audience.
+func WithAudience(audience string) JWTOption {
+	return func(options *jwtOptions) {
+		options.audience = audience
+	}
+}
+
+// WithLeeway tolerates clock skew in the "exp" and "nbf" checks.
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(options *jwtOptions) {
+		options.leeway = leeway
+	}
+}
+
+// WithClock sets the time the "exp" and "nbf" claims are checked against.
+func WithClock(now func() time.Time) JWTOption {
+	return func(options *jwtOptions) {
+		options.now = now
+	}
+}
+
+// JWTAuthenticator accepts "Authorization: Bearer <jwt>" signed with HS256
+// or RS256 by a key of the set. The "sub" claim is the principal and the
+// "roles" claim its roles; roles the service doesn't know are ignored.
+type JWTAuthenticator struct {
+	keys *KeySet
+	jwtOptions
+}
+
+func NewJWTAuthenticator(keys *KeySet, opts ...JWTOption) *JWTAuthenticator {
+	a := &JWTAuthenticator{
+		keys:       keys,
+		jwtOptions: jwtOptions{now: time.Now},
+	}
+
+	for _, opt := range opts {
+		opt(&a.jwtOptions)
+	}
+
+	return a
+}
+
+type (
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	// jwtClaims holds the registered claims RFC 7519 defines and the
+	// roles; the time claims are NumericDate seconds.
+	jwtClaims struct {
+		Subject   string     `json:"sub"`
+		Issuer    string     `json:"iss"`
+		Audience  stringList `json:"aud"`
+		ExpiresAt *float64   `json:"exp"`
+		NotBefore *float64   `json:"nbf"`
+		Roles     stringList `json:"roles"`
+	}
+
+	// stringList is a single string or an array of strings.
+	stringList []string
+)
+
+func (s *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = stringList{single}
+		return nil
+	}
+
+	var many []string
+
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+
+	*s = many
+
+	return nil
+}
+
+func (a *JWTAuthenticator) Authenticate(_ context.Context, md metadata.MD) (entity.Principal, error) {
+	token, ok := credentials(md, bearerScheme)
+
+	if !ok {
+		return entity.Principal{}, ErrNoCredentials
+	}
+
+	claims, err := a.verify(token)
+
+	if err != nil {
+		return entity.Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	principal := entity.Principal{Subject: claims.Subject, Method: entity.AuthMethodJWT}
+
+	for _, name := range claims.Roles {
+		if role, err := entity.ParseRole(name); err == nil {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+
+	return principal, nil
+}
+
+func (a *JWTAuthenticator) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+
+	const jwtParts = 3
+	if len(parts) != jwtParts {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	// The algorithm must match the key: an RSA public key is never used as
+	// an HMAC secret.
+	keys := a.keys.candidates(header.Alg, header.Kid)
+
+	if len(keys) == 0 {
+		return jwtClaims{}, fmt.Errorf("no key for alg %q and kid %q", header.Alg, header.Kid)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	if !slices.ContainsFunc(keys, func(key verificationKey) bool { return key.verify(signed, signature) }) {
+		return jwtClaims{}, errors.New("signature mismatch")
+	}
+
+	var claims jwtClaims
+
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	return claims, a.validate(claims)
+}
+
+func (a *JWTAuthenticator) validate(claims jwtClaims) error {
+	now := a.now()
+
+	switch {
+	case claims.Subject == "":
+		return errors.New("no subject")
+	case claims.ExpiresAt != nil && !now.Before(numericDate(*claims.ExpiresAt).Add(a.leeway)):
+		return errors.New("token expired")
+	case claims.NotBefore != nil && now.Add(a.leeway).Before(numericDate(*claims.NotBefore)):
+		return errors.New("token not valid yet")
+	case a.issuer != "" && claims.Issuer != a.issuer:
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	case a.audience != "" && !slices.Contains(claims.Audience, a.audience):
+		return fmt.Errorf("token is not for audience %q", a.audience)
+	}
+
+	return nil
+}
+
+func (k verificationKey) verify(signed []byte, signature []byte) bool {
+	switch k.alg {
+	case algHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(signed)
+
+		return hmac.Equal(mac.Sum(nil), signature)
+	case algRS256:
+		digest := sha256.Sum256(signed)
+
+		return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/project/library/internal/catalog/marc"
+	"github.com/project/library/internal/entity"
+)
+
+// marcReader reads bibliographic records as books and authority records as
+// authors. Rows are counted in records.
+type marcReader struct {
+	reader interface {
+		Read() (marc.Record, error)
+	}
+	row int
+}
+
+func (m *marcReader) Read() (Record, int, error) {
+	record, err := m.reader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return Record{}, 0, io.EOF
+	}
+
+	m.row++
+
+	switch {
+	case errors.Is(err, marc.ErrInvalidRecord):
+		return Record{}, m.row, &RowError{Row: m.row, Err: err}
+	case err != nil:
+		return Record{}, m.row, err
+	}
+
+	return recordFromMARC(record), m.row, nil
+}
+
+func recordFromMARC(record marc.Record) Record {
+	if marc.IsAuthority(record) {
+		author := marc.ToAuthor(record)
+
+		return Record{Kind: RecordKindAuthor, ID: author.ID, Name: author.Name}
+	}
+
+	book, authors := marc.ToBook(record)
+	result := Record{Kind: RecordKindBook, ID: book.ID, Name: book.Name, ISBN: book.ISBN, Publisher: book.Publisher}
+	allIDs := len(authors) > 0
+
+	for _, author := range authors {
+		result.Authors = append(result.Authors, author.Name)
+		allIDs = allIDs && author.ID != ""
+	}
+
+	// AuthorIDs has to match Authors, so the IDs are only of use when every
+	// author has one.
+	if allIDs {
+		for _, author := range authors {
+			result.AuthorIDs = append(result.AuthorIDs, author.ID)
+		}
+	}
+
+	return result
+}
+
+type marcWriter struct {
+	writer interface {
+		Write(marc.Record) error
+	}
+}
+
+func (m *marcWriter) Write(record Record) error {
+	return m.writer.Write(recordToMARC(record))
+}
+
+func (m *marcWriter) Close() error {
+	if closer, ok := m.writer.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func recordToMARC(record Record) marc.Record {
+	if record.IsAuthor() {
+		return marc.FromAuthor(entity.Author{ID: record.ID, Name: record.Name})
+	}
+
+	authors := make([]entity.Author, len(record.Authors))
+
+	for i, name := range record.Authors {
+		authors[i].Name = name
+
+		if i < len(record.AuthorIDs) {
+			authors[i].ID = record.AuthorIDs[i]
+		}
+	}
+
+	return marc.FromBook(entity.Book{ID: record.ID, Name: record.Name, ISBN: record.ISBN, Publisher: record.Publisher}, authors)
+}
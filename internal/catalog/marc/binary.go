@@ -0,0 +1,200 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	leaderLength         = 24
+	directoryEntryLength = 12
+	maxFieldLength       = 9999
+	maxRecordLength      = 99999
+
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+var ErrRecordTooLong = errors.New("MARC record too long")
+
+// Unmarshal decodes one ISO 2709 record.
+func Unmarshal(data []byte) (Record, error) {
+	if len(data) < leaderLength {
+		return Record{}, errors.Wrap(ErrInvalidRecord, "short leader")
+	}
+
+	leader := string(data[:leaderLength])
+	baseAddress, ok := number(leader[12:17])
+
+	if !ok || baseAddress <= leaderLength || baseAddress > len(data) {
+		return Record{}, errors.Wrapf(ErrInvalidRecord, "base address %q", leader[12:17])
+	}
+
+	directory := data[leaderLength : baseAddress-1]
+
+	if len(directory)%directoryEntryLength != 0 {
+		return Record{}, errors.Wrap(ErrInvalidRecord, "directory length")
+	}
+
+	record := Record{Leader: leader}
+
+	for entry := directory; len(entry) > 0; entry = entry[directoryEntryLength:] {
+		tag := string(entry[:3])
+		length, lengthOK := number(string(entry[3:7]))
+		start, startOK := number(string(entry[7:12]))
+
+		if !lengthOK || !startOK || baseAddress+start+length > len(data) {
+			return Record{}, errors.Wrapf(ErrInvalidRecord, "directory entry %q", entry[:directoryEntryLength])
+		}
+
+		field := bytes.TrimSuffix(data[baseAddress+start:baseAddress+start+length], []byte{fieldTerminator})
+
+		if isControlTag(tag) {
+			record.ControlFields = append(record.ControlFields, ControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+
+		if len(field) < 2 {
+			return Record{}, errors.Wrapf(ErrInvalidRecord, "field %s without indicators", tag)
+		}
+
+		dataField := DataField{Tag: tag, Ind1: field[0], Ind2: field[1]}
+
+		for _, subfield := range bytes.Split(field[2:], []byte{subfieldDelimiter}) {
+			if len(subfield) > 0 {
+				dataField.Subfields = append(dataField.Subfields, Subfield{Code: subfield[0], Value: string(subfield[1:])})
+			}
+		}
+
+		record.DataFields = append(record.DataFields, dataField)
+	}
+
+	return record, nil
+}
+
+// number parses a numeric field of the leader or the directory. Unlike
+// strconv.Atoi it takes digits only, so that a sign can't point a field
+// before the start of the record.
+func number(s string) (int, bool) {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+
+	return n, err == nil
+}
+
+// Marshal encodes the record in ISO 2709 with UTF-8 character coding,
+// control fields first.
+func Marshal(record Record) ([]byte, error) {
+	var directory, fields bytes.Buffer
+
+	add := func(tag string, field []byte) error {
+		if len(field) > maxFieldLength {
+			return errors.Wrapf(ErrRecordTooLong, "field %s", tag)
+		}
+
+		fmt.Fprintf(&directory, "%3.3s%04d%05d", tag, len(field), fields.Len())
+		fields.Write(field)
+
+		return nil
+	}
+
+	for _, field := range record.ControlFields {
+		if err := add(field.Tag, append([]byte(field.Value), fieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range record.DataFields {
+		data := []byte{field.Ind1, field.Ind2}
+
+		for _, subfield := range field.Subfields {
+			data = append(data, subfieldDelimiter, subfield.Code)
+			data = append(data, subfield.Value...)
+		}
+
+		if err := add(field.Tag, append(data, fieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+
+	directory.WriteByte(fieldTerminator)
+
+	baseAddress := leaderLength + directory.Len()
+	length := baseAddress + fields.Len() + 1
+
+	if length > maxRecordLength {
+		return nil, ErrRecordTooLong
+	}
+
+	leader := []byte(fmt.Sprintf("%-24.24s", record.Leader))
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	leader[9] = 'a'
+	copy(leader[10:12], "22")
+	copy(leader[12:17], fmt.Sprintf("%05d", baseAddress))
+	copy(leader[20:24], "4500")
+
+	result := make([]byte, 0, length)
+	result = append(result, leader...)
+	result = append(result, directory.Bytes()...)
+	result = append(result, fields.Bytes()...)
+
+	return append(result, recordTerminator), nil
+}
+
+// Reader reads consecutive ISO 2709 records. A record that fails to decode
+// is reported with ErrInvalidRecord and reading may go on with the next one.
+type Reader struct {
+	reader *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{reader: bufio.NewReader(r)}
+}
+
+func (r *Reader) Read() (Record, error) {
+	data, err := r.reader.ReadBytes(recordTerminator)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Record{}, err
+	}
+
+	// Some files put line breaks between the records.
+	data = bytes.TrimLeft(data, "\r\n")
+
+	if len(data) == 0 {
+		return Record{}, io.EOF
+	}
+
+	return Unmarshal(data)
+}
+
+type Writer struct {
+	writer io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{writer: w}
+}
+
+func (w *Writer) Write(record Record) error {
+	data, err := Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.writer.Write(data)
+
+	return err
+}
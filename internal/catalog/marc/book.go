@@ -0,0 +1,233 @@
+package marc
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/project/library/internal/entity"
+)
+
+const (
+	// bookLeader describes a monograph in UTF-8, authorLeader a name
+	// authority record.
+	bookLeader   = "00000nam a2200000 i 4500"
+	authorLeader = "00000nz  a2200000n  4500"
+
+	// uuidURN prefixes the author IDs kept in $0 of 100 and 700.
+	uuidURN = "urn:uuid:"
+
+	// surnameFirst is the first indicator of a personal name entered
+	// "Surname, Forename".
+	surnameFirst = '1'
+	forenameOnly = '0'
+)
+
+// IsAuthority reports whether the record is an authority record, which
+// describes an author rather than a book.
+func IsAuthority(record Record) bool {
+	return len(record.Leader) > 6 && record.Leader[6] == 'z'
+}
+
+// ToBook maps a bibliographic record to a book and its authors. The title
+// comes from 245 $a and $b, the authors from 100 and 700 $a, the ISBN from
+// the first valid 020 $a and the publisher from 264 $b or, for older
+// records, 260 $b. Only IDs that are UUIDs are kept: 001 for the book and
+// urn:uuid: in $0 for the authors.
+func ToBook(record Record) (entity.Book, []entity.Author) {
+	book := entity.Book{ID: recordID(record)}
+
+	for _, field := range record.Fields("245") {
+		book.Name = trimPunctuation(field.Subfield('a'))
+
+		if subtitle := trimPunctuation(field.Subfield('b')); subtitle != "" {
+			book.Name += ": " + subtitle
+		}
+
+		break
+	}
+
+	var authors []entity.Author
+
+	for _, field := range append(record.Fields("100"), record.Fields("700")...) {
+		name := personalName(field)
+
+		if name == "" {
+			continue
+		}
+
+		author := entity.Author{Name: name}
+
+		if id, ok := strings.CutPrefix(field.Subfield('0'), uuidURN); ok && uuid.Validate(id) == nil {
+			author.ID = id
+		}
+
+		authors = append(authors, author)
+	}
+
+	book.ISBN = isbn(record)
+	book.Publisher = publisher(record)
+
+	return book, authors
+}
+
+// ToAuthor maps an authority record to an author named by its 100 $a.
+func ToAuthor(record Record) entity.Author {
+	author := entity.Author{ID: recordID(record)}
+
+	if fields := record.Fields("100"); len(fields) > 0 {
+		author.Name = personalName(fields[0])
+	}
+
+	return author
+}
+
+// FromBook builds the bibliographic record of a book. The first author is
+// the main entry in 100, the others go to 700.
+func FromBook(book entity.Book, authors []entity.Author) Record {
+	record := Record{Leader: bookLeader}
+
+	if book.ID != "" {
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: "001", Value: book.ID})
+	}
+
+	if book.ISBN != "" {
+		record.DataFields = append(record.DataFields, DataField{
+			Tag: "020", Ind1: ' ', Ind2: ' ',
+			Subfields: []Subfield{{Code: 'a', Value: book.ISBN}},
+		})
+	}
+
+	var addedEntries []DataField
+
+	for i, author := range authors {
+		field := nameField("700", author)
+
+		if i == 0 {
+			field.Tag = "100"
+			record.DataFields = append(record.DataFields, field)
+		} else {
+			addedEntries = append(addedEntries, field)
+		}
+	}
+
+	titleAddedEntry := byte('0')
+
+	if len(authors) > 0 {
+		titleAddedEntry = '1'
+	}
+
+	record.DataFields = append(record.DataFields, DataField{
+		Tag: "245", Ind1: titleAddedEntry, Ind2: '0',
+		Subfields: []Subfield{{Code: 'a', Value: book.Name}},
+	})
+
+	if book.Publisher != "" {
+		record.DataFields = append(record.DataFields, DataField{
+			Tag: "264", Ind1: ' ', Ind2: '1',
+			Subfields: []Subfield{{Code: 'b', Value: book.Publisher}},
+		})
+	}
+
+	record.DataFields = append(record.DataFields, addedEntries...)
+
+	return record
+}
+
+// FromAuthor builds the authority record of an author.
+func FromAuthor(author entity.Author) Record {
+	record := Record{Leader: authorLeader}
+
+	if author.ID != "" {
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: "001", Value: author.ID})
+	}
+
+	// The ID is in 001 already.
+	record.DataFields = append(record.DataFields, nameField("100", entity.Author{Name: author.Name}))
+
+	return record
+}
+
+func recordID(record Record) string {
+	id, _ := record.ControlField("001")
+	id = strings.TrimSpace(id)
+
+	if uuid.Validate(id) != nil {
+		return ""
+	}
+
+	return id
+}
+
+// nameField enters the name surname first, the way catalogs file personal
+// names, and keeps the author ID in $0.
+func nameField(tag string, author entity.Author) DataField {
+	field := DataField{Tag: tag, Ind1: forenameOnly, Ind2: ' '}
+	words := strings.Fields(author.Name)
+	name := author.Name
+
+	if len(words) > 1 {
+		field.Ind1 = surnameFirst
+		name = words[len(words)-1] + ", " + strings.Join(words[:len(words)-1], " ")
+	}
+
+	field.Subfields = append(field.Subfields, Subfield{Code: 'a', Value: name})
+
+	if author.ID != "" {
+		field.Subfields = append(field.Subfields, Subfield{Code: '0', Value: uuidURN + author.ID})
+	}
+
+	return field
+}
+
+// personalName turns "Surname, Forename" back into "Forename Surname".
+func personalName(field DataField) string {
+	name := trimPunctuation(field.Subfield('a'))
+
+	if field.Ind1 == surnameFirst {
+		if surname, forename, ok := strings.Cut(name, ", "); ok {
+			name = strings.TrimSpace(forename) + " " + strings.TrimSpace(surname)
+		}
+	}
+
+	return name
+}
+
+func isbn(record Record) string {
+	for _, field := range record.Fields("020") {
+		// $a may carry a qualifier such as "(pbk.)" after the number.
+		words := strings.Fields(field.Subfield('a'))
+
+		if len(words) == 0 {
+			continue
+		}
+
+		if normalized, err := entity.NormalizeISBN(words[0]); err == nil {
+			return normalized
+		}
+	}
+
+	return ""
+}
+
+func publisher(record Record) string {
+	for _, field := range record.Fields("264") {
+		if field.Ind2 == '1' {
+			if name := trimPunctuation(field.Subfield('b')); name != "" {
+				return name
+			}
+		}
+	}
+
+	for _, field := range record.Fields("260") {
+		if name := trimPunctuation(field.Subfield('b')); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// trimPunctuation drops the ISBD punctuation that closes MARC subfields.
+func trimPunctuation(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,.="))
+}
@@ -0,0 +1,209 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogXML = `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>01142cam  2200301 a 4500</leader>
+    <controlfield tag="001">ocm12345678</controlfield>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="a">0-306-40615-X (pbk.)</subfield>
+    </datafield>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="a">978-0-306-40615-7</subfield>
+    </datafield>
+    <datafield tag="100" ind1="1" ind2=" ">
+      <subfield code="a">Sagan, Carl,</subfield>
+      <subfield code="d">1934-1996.</subfield>
+    </datafield>
+    <datafield tag="245" ind1="1" ind2="0">
+      <subfield code="a">Cosmos :</subfield>
+      <subfield code="b">a personal voyage /</subfield>
+      <subfield code="c">Carl Sagan.</subfield>
+    </datafield>
+    <datafield tag="260" ind1=" " ind2=" ">
+      <subfield code="a">New York :</subfield>
+      <subfield code="b">Random House,</subfield>
+      <subfield code="c">1980.</subfield>
+    </datafield>
+    <datafield tag="700" ind1="0" ind2=" ">
+      <subfield code="a">Plato.</subfield>
+    </datafield>
+    <datafield tag="700" ind1="1" ind2=" ">
+      <subfield code="a">Druyan, Ann</subfield>
+      <subfield code="0">urn:uuid:0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a</subfield>
+    </datafield>
+  </record>
+  <record>
+    <leader>00000nz  a2200000n  4500</leader>
+    <controlfield tag="001">0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a</controlfield>
+    <datafield tag="100" ind1="1" ind2=" ">
+      <subfield code="a">Druyan, Ann</subfield>
+    </datafield>
+  </record>
+</collection>
+`
+
+func readAll(t *testing.T, next func() (Record, error)) []Record {
+	t.Helper()
+
+	var records []Record
+
+	for {
+		record, err := next()
+
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+
+	records := readAll(t, NewXMLReader(strings.NewReader(catalogXML)).Read)
+	require.Len(t, records, 2)
+
+	require.False(t, IsAuthority(records[0]))
+
+	book, authors := ToBook(records[0])
+	require.Equal(t, entity.Book{
+		Name:      "Cosmos: a personal voyage",
+		ISBN:      "9780306406157",
+		Publisher: "Random House",
+	}, book)
+	require.Equal(t, []entity.Author{
+		{Name: "Carl Sagan"},
+		{Name: "Plato"},
+		{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", Name: "Ann Druyan"},
+	}, authors)
+
+	require.True(t, IsAuthority(records[1]))
+	require.Equal(t, entity.Author{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", Name: "Ann Druyan"}, ToAuthor(records[1]))
+}
+
+func TestPublisherPrefersRDA(t *testing.T) {
+	t.Parallel()
+
+	record := Record{DataFields: []DataField{
+		{Tag: "260", Ind1: ' ', Ind2: ' ', Subfields: []Subfield{{Code: 'b', Value: "Old Press,"}}},
+		{Tag: "264", Ind1: ' ', Ind2: '3', Subfields: []Subfield{{Code: 'b', Value: "Printer,"}}},
+		{Tag: "264", Ind1: ' ', Ind2: '1', Subfields: []Subfield{{Code: 'b', Value: "New Press,"}}},
+	}}
+
+	book, _ := ToBook(record)
+	require.Equal(t, "New Press", book.Publisher)
+}
+
+func TestFromBook(t *testing.T) {
+	t.Parallel()
+
+	record := FromBook(entity.Book{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b", Name: "Cosmos", ISBN: "9780306406157", Publisher: "Random House"},
+		[]entity.Author{{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5c", Name: "Carl Edward Sagan"}, {Name: "Plato"}})
+
+	require.Equal(t, Record{
+		Leader:        bookLeader,
+		ControlFields: []ControlField{{Tag: "001", Value: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b"}},
+		DataFields: []DataField{
+			{Tag: "020", Ind1: ' ', Ind2: ' ', Subfields: []Subfield{{Code: 'a', Value: "9780306406157"}}},
+			{Tag: "100", Ind1: '1', Ind2: ' ', Subfields: []Subfield{
+				{Code: 'a', Value: "Sagan, Carl Edward"},
+				{Code: '0', Value: "urn:uuid:0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5c"},
+			}},
+			{Tag: "245", Ind1: '1', Ind2: '0', Subfields: []Subfield{{Code: 'a', Value: "Cosmos"}}},
+			{Tag: "264", Ind1: ' ', Ind2: '1', Subfields: []Subfield{{Code: 'b', Value: "Random House"}}},
+			{Tag: "700", Ind1: '0', Ind2: ' ', Subfields: []Subfield{{Code: 'a', Value: "Plato"}}},
+		},
+	}, record)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	records := readAll(t, NewXMLReader(strings.NewReader(catalogXML)).Read)
+
+	var buf bytes.Buffer
+
+	writer := NewWriter(&buf)
+
+	for _, record := range records {
+		require.NoError(t, writer.Write(record))
+	}
+
+	decoded := readAll(t, NewReader(&buf).Read)
+	require.Len(t, decoded, len(records))
+
+	for i, record := range decoded {
+		require.Equal(t, "4500", record.Leader[20:])
+		require.Equal(t, byte('a'), record.Leader[9])
+
+		// Marshal rewrites the record length and base address.
+		record.Leader = records[i].Leader
+		require.Equal(t, records[i], record)
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	records := readAll(t, NewXMLReader(strings.NewReader(catalogXML)).Read)
+
+	var buf bytes.Buffer
+
+	writer := NewXMLWriter(&buf)
+
+	for _, record := range records {
+		require.NoError(t, writer.Write(record))
+	}
+
+	require.NoError(t, writer.Close())
+	require.Contains(t, buf.String(), `<collection xmlns="http://www.loc.gov/MARC21/slim">`)
+	require.Equal(t, records, readAll(t, NewXMLReader(&buf).Read))
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "short leader", data: "00010nam"},
+		{name: "bad base address", data: "00026nam a22000xx   4500\x1e\x1d"},
+		{name: "directory outside record", data: "00038nam a2200037   4500245001000000\x1e\x1d"},
+		{name: "negative length", data: "00000nam a2200037   4500245-00100000\x1e"},
+		{name: "signed base address", data: "00026nam a22+0025   4500\x1e\x1d"},
+		{name: "no indicators", data: "00042nam a2200037   4500245000200000\x1ea\x1e\x1d"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Unmarshal([]byte(test.data))
+			require.ErrorIs(t, err, ErrInvalidRecord)
+		})
+	}
+}
+
+func TestMarshalTooLong(t *testing.T) {
+	t.Parallel()
+
+	_, err := Marshal(Record{DataFields: []DataField{
+		{Tag: "245", Ind1: '0', Ind2: '0', Subfields: []Subfield{{Code: 'a', Value: strings.Repeat("a", maxFieldLength)}}},
+	}})
+	require.ErrorIs(t, err, ErrRecordTooLong)
+}
@@ -0,0 +1,76 @@
+// Package marc reads and writes MARC 21 records, in ISO 2709 and in MARCXML,
+// and maps them to books and authors.
+package marc
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Record is a MARC 21 record. Only the leader positions this package writes
+// are meaningful to it; the others are kept as read.
+type Record struct {
+	Leader        string
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+// ControlField is one of the 00X fields, which have neither indicators nor
+// subfields.
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+var ErrInvalidRecord = errors.New("invalid MARC record")
+
+// ControlField returns the value of the first control field with the tag.
+func (r Record) ControlField(tag string) (string, bool) {
+	for _, field := range r.ControlFields {
+		if field.Tag == tag {
+			return field.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Fields returns the data fields with the tag in record order.
+func (r Record) Fields(tag string) []DataField {
+	var result []DataField
+
+	for _, field := range r.DataFields {
+		if field.Tag == tag {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+// Subfield returns the value of the first subfield with the code.
+func (f DataField) Subfield(code byte) string {
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return subfield.Value
+		}
+	}
+
+	return ""
+}
+
+// isControlTag tells the 00X control fields from the data fields.
+func isControlTag(tag string) bool {
+	return len(tag) == 3 && tag[0] == '0' && tag[1] == '0'
+}
@@ -0,0 +1,177 @@
+package marc
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Namespace is the MARC 21 XML schema namespace.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader reads the record elements of a MARCXML document, whether they
+// are wrapped in a collection or not. Syntax errors end the document.
+type XMLReader struct {
+	decoder *xml.Decoder
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+func (r *XMLReader) Read() (Record, error) {
+	for {
+		token, err := r.decoder.Token()
+
+		if err != nil {
+			return Record{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var element xmlRecord
+
+		if err := r.decoder.DecodeElement(&element, &start); err != nil {
+			return Record{}, err
+		}
+
+		return element.record(), nil
+	}
+}
+
+func (x xmlRecord) record() Record {
+	record := Record{Leader: x.Leader}
+
+	for _, field := range x.ControlFields {
+		record.ControlFields = append(record.ControlFields, ControlField(field))
+	}
+
+	for _, field := range x.DataFields {
+		dataField := DataField{Tag: field.Tag, Ind1: indicator(field.Ind1), Ind2: indicator(field.Ind2)}
+
+		for _, subfield := range field.Subfields {
+			if subfield.Code != "" {
+				dataField.Subfields = append(dataField.Subfields, Subfield{Code: subfield.Code[0], Value: subfield.Value})
+			}
+		}
+
+		record.DataFields = append(record.DataFields, dataField)
+	}
+
+	return record
+}
+
+func indicator(value string) byte {
+	if value == "" {
+		return ' '
+	}
+
+	return value[0]
+}
+
+func xmlRecordOf(record Record) xmlRecord {
+	element := xmlRecord{Leader: record.Leader}
+
+	for _, field := range record.ControlFields {
+		element.ControlFields = append(element.ControlFields, xmlControlField(field))
+	}
+
+	for _, field := range record.DataFields {
+		dataField := xmlDataField{Tag: field.Tag, Ind1: string(field.Ind1), Ind2: string(field.Ind2)}
+
+		for _, subfield := range field.Subfields {
+			dataField.Subfields = append(dataField.Subfields, xmlSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+
+		element.DataFields = append(element.DataFields, dataField)
+	}
+
+	return element
+}
+
+// XMLWriter writes a MARCXML collection. Close ends the collection; it does
+// not close the underlying writer.
+type XMLWriter struct {
+	writer  io.Writer
+	encoder *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return &XMLWriter{writer: w, encoder: encoder}
+}
+
+var collectionStart = xml.StartElement{
+	Name: xml.Name{Local: "collection"},
+	Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}},
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	if _, err := io.WriteString(w.writer, xml.Header); err != nil {
+		return err
+	}
+
+	return w.encoder.EncodeToken(collectionStart)
+}
+
+func (w *XMLWriter) Write(record Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	return w.encoder.Encode(xmlRecordOf(record))
+}
+
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	if err := w.encoder.EncodeToken(collectionStart.End()); err != nil {
+		return err
+	}
+
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w.writer, "\n")
+
+	return err
+}
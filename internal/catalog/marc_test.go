@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMARCRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	records := []Record{
+		{Kind: RecordKindAuthor, ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", Name: "Leo Tolstoy"},
+		{
+			Kind:      RecordKindBook,
+			ID:        "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b",
+			Name:      "War and Peace",
+			Authors:   []string{"Leo Tolstoy", "Homer"},
+			AuthorIDs: []string{"0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5c"},
+			ISBN:      "9780140447934",
+			Publisher: "Penguin Classics",
+		},
+		{Kind: RecordKindBook, Name: "Anonymous"},
+	}
+
+	for _, format := range []entity.CatalogFormat{entity.CatalogFormatMARC21, entity.CatalogFormatMARCXML} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			writer, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+
+			for _, record := range records {
+				require.NoError(t, writer.Write(record))
+			}
+
+			require.NoError(t, writer.Close())
+
+			reader, err := NewReader(format, &buf)
+			require.NoError(t, err)
+
+			results := readAll(t, reader)
+			require.Len(t, results, len(records))
+
+			for i, result := range results {
+				require.NoError(t, result.err)
+				require.Equal(t, i+1, result.row)
+				require.Equal(t, records[i], result.record)
+			}
+		})
+	}
+}
+
+func TestMARCReaderInvalidRecord(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	writer, err := NewWriter(entity.CatalogFormatMARC21, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(Record{Kind: RecordKindBook, Name: "First"}))
+	require.NoError(t, writer.Close())
+
+	input := "00026nam a22000xx   4500\x1e\x1d" + buf.String()
+
+	reader, err := NewReader(entity.CatalogFormatMARC21, strings.NewReader(input))
+	require.NoError(t, err)
+
+	results := readAll(t, reader)
+	require.Len(t, results, 2)
+
+	var rowErr *RowError
+	require.ErrorAs(t, results[0].err, &rowErr)
+	require.Equal(t, 1, rowErr.Row)
+	require.NoError(t, results[1].err)
+	require.Equal(t, "First", results[1].record.Name)
+}
+
+func TestMARCXMLReaderSyntaxError(t *testing.T) {
+	t.Parallel()
+
+	reader, err := NewReader(entity.CatalogFormatMARCXML, strings.NewReader(`<collection><record><leader>`))
+	require.NoError(t, err)
+
+	_, _, err = reader.Read()
+	require.Error(t, err)
+
+	var rowErr *RowError
+	require.NotErrorAs(t, err, &rowErr)
+}
@@ -0,0 +1,240 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/project/library/internal/catalog/marc"
+	"github.com/project/library/internal/entity"
+)
+
+// AuthorSeparator separates the values of the authors and author_ids columns
+// of CSV files.
+const AuthorSeparator = ";"
+
+// Reader reads records one by one.
+type Reader interface {
+	// Read returns the next record and its row number, counted from one and
+	// not counting the CSV header. It returns io.EOF after the last record
+	// and *RowError for a row that can't be parsed; reading may go on after
+	// a *RowError.
+	Read() (Record, int, error)
+}
+
+// RowError reports a row that can't be parsed.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrMissingColumn = entity.NewError(entity.KindInvalidArgument, "MISSING_COLUMN", "missing column")
+	ErrInvalidColumn = entity.NewError(entity.KindInvalidArgument, "INVALID_COLUMN", "blank or repeated column")
+	ErrColumnCount   = errors.New("wrong number of fields")
+)
+
+// NewReader reads records in the given format from r, which may be gzipped.
+func NewReader(format entity.CatalogFormat, r io.Reader) (Reader, error) {
+	r, err := gunzip(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case entity.CatalogFormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+
+		return &csvReader{reader: reader}, nil
+	case entity.CatalogFormatJSONL:
+		return &jsonlReader{reader: bufio.NewReader(r)}, nil
+	case entity.CatalogFormatMARC21:
+		return &marcReader{reader: marc.NewReader(r)}, nil
+	case entity.CatalogFormatMARCXML:
+		return &marcReader{reader: marc.NewXMLReader(r)}, nil
+	default:
+		return nil, entity.ErrUnknownCatalogFormat
+	}
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gunzip decompresses r if it starts with the gzip magic number.
+func gunzip(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(gzipMagic))
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, gzipMagic) {
+		return buffered, nil
+	}
+
+	return gzip.NewReader(buffered)
+}
+
+// csvReader reads a header naming the columns in any order followed by one
+// record per line. The name and authors columns are required, kind, id,
+// author_ids, isbn and publisher are optional.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	// width is the number of columns of the header, every row has as many
+	// fields.
+	width int
+	row   int
+}
+
+func (c *csvReader) Read() (Record, int, error) {
+	if c.columns == nil {
+		if err := c.readHeader(); err != nil {
+			return Record{}, 0, err
+		}
+	}
+
+	fields, err := c.reader.Read()
+
+	if err != nil {
+		var parseErr *csv.ParseError
+
+		if errors.As(err, &parseErr) {
+			c.row++
+			return Record{}, c.row, &RowError{Row: c.row, Err: parseErr.Err}
+		}
+
+		return Record{}, 0, err
+	}
+
+	c.row++
+
+	if len(fields) != c.width {
+		return Record{}, c.row, &RowError{Row: c.row, Err: ErrColumnCount}
+	}
+
+	record := Record{
+		Kind:      RecordKind(c.optional(fields, "kind")),
+		ID:        c.optional(fields, "id"),
+		Name:      strings.TrimSpace(fields[c.columns["name"]]),
+		Authors:   splitList(fields[c.columns["authors"]]),
+		ISBN:      c.optional(fields, "isbn"),
+		Publisher: c.optional(fields, "publisher"),
+	}
+
+	if authorIDs := c.optional(fields, "author_ids"); authorIDs != "" {
+		record.AuthorIDs = splitList(authorIDs)
+	}
+
+	return record, c.row, nil
+}
+
+func (c *csvReader) readHeader() error {
+	header, err := c.reader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "read csv header")
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(column))
+
+		if _, ok := columns[name]; ok || name == "" {
+			return errors.Wrapf(ErrInvalidColumn, "column %d %q", i+1, column)
+		}
+
+		columns[name] = i
+	}
+
+	for _, required := range []string{"name", "authors"} {
+		if _, ok := columns[required]; !ok {
+			return errors.Wrap(ErrMissingColumn, required)
+		}
+	}
+
+	c.columns = columns
+	c.width = len(header)
+
+	return nil
+}
+
+func (c *csvReader) optional(fields []string, column string) string {
+	if i, ok := c.columns[column]; ok {
+		return strings.TrimSpace(fields[i])
+	}
+
+	return ""
+}
+
+func splitList(field string) []string {
+	values := make([]string, 0)
+
+	for _, value := range strings.Split(field, AuthorSeparator) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// jsonlReader reads one JSON encoded Record per line and ignores blank lines.
+type jsonlReader struct {
+	reader *bufio.Reader
+	row    int
+}
+
+func (j *jsonlReader) Read() (Record, int, error) {
+	for {
+		line, err := j.reader.ReadBytes('\n')
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Record{}, 0, err
+		}
+
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			if err != nil {
+				return Record{}, 0, io.EOF
+			}
+
+			continue
+		}
+
+		j.row++
+
+		var record Record
+
+		if err := json.Unmarshal(line, &record); err != nil {
+			return Record{}, j.row, &RowError{Row: j.row, Err: err}
+		}
+
+		if record.Authors == nil {
+			record.Authors = make([]string, 0)
+		}
+
+		return record, j.row, nil
+	}
+}
@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+type readResult struct {
+	record Record
+	row    int
+	err    error
+}
+
+func readAll(t *testing.T, reader Reader) []readResult {
+	t.Helper()
+
+	var results []readResult
+
+	for {
+		record, row, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			return results
+		}
+
+		var rowErr *RowError
+
+		if err != nil && !errors.As(err, &rowErr) {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		results = append(results, readResult{record: record, row: row, err: err})
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Parallel()
+
+	const input = `authors,name,id
+Leo Tolstoy; Some Editor ,War and Peace,0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a
+,"Anonymous, collected",
+broken row
+Anna,"bad "quote",
+Anna,Last,
+`
+
+	reader, err := NewReader(entity.CatalogFormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+
+	results := readAll(t, reader)
+	require.Len(t, results, 5)
+
+	require.Equal(t, readResult{
+		record: Record{
+			ID:      "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a",
+			Name:    "War and Peace",
+			Authors: []string{"Leo Tolstoy", "Some Editor"},
+		},
+		row: 1,
+	}, results[0])
+	require.Equal(t, Record{Name: "Anonymous, collected", Authors: []string{}}, results[1].record)
+
+	require.Equal(t, 3, results[2].row)
+	require.ErrorIs(t, results[2].err, ErrColumnCount)
+
+	require.Equal(t, 4, results[3].row)
+	require.Error(t, results[3].err)
+
+	require.Equal(t, 5, results[4].row)
+	require.Equal(t, "Last", results[4].record.Name)
+}
+
+func TestCSVReaderHeader(t *testing.T) {
+	t.Parallel()
+
+	reader, err := NewReader(entity.CatalogFormatCSV, strings.NewReader("id,name\n"))
+	require.NoError(t, err)
+
+	_, _, err = reader.Read()
+	require.ErrorIs(t, err, ErrMissingColumn)
+
+	for _, header := range []string{"name,Name,authors", "name,,authors", "name,authors, "} {
+		reader, err = NewReader(entity.CatalogFormatCSV, strings.NewReader(header+"\nWar and Peace,Leo Tolstoy,x\n"))
+		require.NoError(t, err)
+
+		_, _, err = reader.Read()
+		require.ErrorIs(t, err, ErrInvalidColumn, header)
+	}
+
+	reader, err = NewReader(entity.CatalogFormatCSV, strings.NewReader(""))
+	require.NoError(t, err)
+
+	_, _, err = reader.Read()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestJSONLReader(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"name": "War and Peace", "authors": ["Leo Tolstoy"]}
+
+{"name": 
+{"id": "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", "name": "Anna Karenina"}`
+
+	reader, err := NewReader(entity.CatalogFormatJSONL, strings.NewReader(input))
+	require.NoError(t, err)
+
+	results := readAll(t, reader)
+	require.Len(t, results, 3)
+
+	require.Equal(t, readResult{
+		record: Record{Name: "War and Peace", Authors: []string{"Leo Tolstoy"}},
+		row:    1,
+	}, results[0])
+
+	require.Equal(t, 2, results[1].row)
+	require.Error(t, results[1].err)
+
+	require.Equal(t, readResult{
+		record: Record{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", Name: "Anna Karenina", Authors: []string{}},
+		row:    3,
+	}, results[2])
+}
+
+func TestUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewReader("xml", strings.NewReader(""))
+	require.ErrorIs(t, err, entity.ErrUnknownCatalogFormat)
+}
+
+func TestRecordValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		record   Record
+		expected error
+	}{
+		{
+			name:   "valid",
+			record: Record{Name: "War and Peace", Authors: []string{"Leo Tolstoy"}},
+		},
+		{
+			name:   "unicode author names",
+			record: Record{Name: "Братья Карамазовы", Authors: []string{"Фёдор Достоевский", "Gabriel García Márquez", "Flann O'Brien"}},
+		},
+		{
+			name:     "invalid id",
+			record:   Record{ID: "42", Name: "War and Peace"},
+			expected: ErrInvalidID,
+		},
+		{
+			name:     "missing name",
+			record:   Record{Authors: []string{"Leo Tolstoy"}},
+			expected: ErrMissingName,
+		},
+		{
+			name:     "invalid author name",
+			record:   Record{Name: "War and Peace", Authors: []string{"Leo  Tolstoy"}},
+			expected: ErrInvalidAuthorName,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.ErrorIs(t, test.record.Validate(), test.expected)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package catalog reads and writes the book records of catalog imports and
+// exports.
+package catalog
+
+import (
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/project/library/internal/entity"
+)
+
+// RecordKind tells books from authors; an empty kind means a book.
+type RecordKind string
+
+const (
+	RecordKindBook   RecordKind = "book"
+	RecordKindAuthor RecordKind = "author"
+)
+
+// Record is a book with its authors or an author on its own. The authors of
+// a book are referenced by name; when AuthorIDs is set, it holds the IDs of
+// the authors in the same order and the names are used only for the authors
+// that have to be created.
+type Record struct {
+	Kind      RecordKind `json:"kind,omitempty"`
+	ID        string     `json:"id,omitempty"`
+	Name      string     `json:"name"`
+	Authors   []string   `json:"authors,omitempty"`
+	AuthorIDs []string   `json:"author_ids,omitempty"`
+	ISBN      string     `json:"isbn,omitempty"`
+	Publisher string     `json:"publisher,omitempty"`
+}
+
+// IsAuthor reports whether the record describes an author.
+func (r Record) IsAuthor() bool {
+	return r.Kind == RecordKindAuthor
+}
+
+// authorNamePattern is the rule RegisterAuthor applies to author names.
+var authorNamePattern = regexp.MustCompile(entity.AuthorNamePattern)
+
+const maxAuthorNameBytes = 512
+
+var (
+	ErrUnknownKind        = errors.New("unknown record kind")
+	ErrInvalidID          = errors.New("id must be a UUID")
+	ErrMissingName        = errors.New("name is required")
+	ErrInvalidAuthorName  = errors.New("invalid author name")
+	ErrAuthorIDCount      = errors.New("author_ids must match authors")
+	ErrBookFieldsOfAuthor = errors.New("an author record can't have authors, isbn or publisher")
+)
+
+// Validate applies the rules of AddBook and RegisterAuthor to the record.
+func (r Record) Validate() error {
+	if r.Kind != "" && r.Kind != RecordKindBook && r.Kind != RecordKindAuthor {
+		return errors.Wrapf(ErrUnknownKind, "%q", r.Kind)
+	}
+
+	if err := validateID(r.ID); err != nil {
+		return err
+	}
+
+	if r.IsAuthor() {
+		if len(r.Authors) > 0 || len(r.AuthorIDs) > 0 || r.ISBN != "" || r.Publisher != "" {
+			return ErrBookFieldsOfAuthor
+		}
+
+		return validateAuthorName(r.Name)
+	}
+
+	if r.Name == "" {
+		return ErrMissingName
+	}
+
+	if r.ISBN != "" {
+		if _, err := entity.NormalizeISBN(r.ISBN); err != nil {
+			return err
+		}
+	}
+
+	if len(r.AuthorIDs) > 0 && len(r.AuthorIDs) != len(r.Authors) {
+		return ErrAuthorIDCount
+	}
+
+	for i, author := range r.Authors {
+		if err := validateAuthorName(author); err != nil {
+			return err
+		}
+
+		if len(r.AuthorIDs) > 0 {
+			if err := validateID(r.AuthorIDs[i]); err != nil || r.AuthorIDs[i] == "" {
+				return errors.Wrapf(ErrInvalidID, "%q", r.AuthorIDs[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateID(id string) error {
+	if id == "" {
+		return nil
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return errors.Wrapf(ErrInvalidID, "%q", id)
+	}
+
+	return nil
+}
+
+func validateAuthorName(name string) error {
+	if len(name) > maxAuthorNameBytes || !authorNamePattern.MatchString(name) {
+		return errors.Wrapf(ErrInvalidAuthorName, "%q", name)
+	}
+
+	return nil
+}
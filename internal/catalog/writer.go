@@ -0,0 +1,98 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/project/library/internal/catalog/marc"
+	"github.com/project/library/internal/entity"
+)
+
+// Writer writes records in a format NewReader reads back.
+type Writer interface {
+	Write(record Record) error
+	// Close writes out the buffered records and ends the document; it
+	// doesn't close the underlying writer.
+	Close() error
+}
+
+var csvHeader = []string{"kind", "id", "name", "authors", "author_ids", "isbn", "publisher"}
+
+// NewWriter writes records in the given format to w.
+func NewWriter(format entity.CatalogFormat, w io.Writer) (Writer, error) {
+	switch format {
+	case entity.CatalogFormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case entity.CatalogFormatJSONL:
+		buffered := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buffered)
+		encoder.SetEscapeHTML(false)
+
+		return &jsonlWriter{buffered: buffered, encoder: encoder}, nil
+	case entity.CatalogFormatMARC21:
+		return &marcWriter{writer: marc.NewWriter(w)}, nil
+	case entity.CatalogFormatMARCXML:
+		return &marcWriter{writer: marc.NewXMLWriter(w)}, nil
+	default:
+		return nil, entity.ErrUnknownCatalogFormat
+	}
+}
+
+// csvWriter writes the header before the first record, so even an empty
+// catalog has one.
+type csvWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(record Record) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.writer.Write([]string{
+		string(record.Kind),
+		record.ID,
+		record.Name,
+		strings.Join(record.Authors, AuthorSeparator),
+		strings.Join(record.AuthorIDs, AuthorSeparator),
+		record.ISBN,
+		record.Publisher,
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+
+	return c.writer.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+
+	c.headerWritten = true
+
+	return c.writer.Write(csvHeader)
+}
+
+type jsonlWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (j *jsonlWriter) Write(record Record) error {
+	return j.encoder.Encode(record)
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.buffered.Flush()
+}
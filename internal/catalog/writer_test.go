@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	records := []Record{
+		{Kind: RecordKindAuthor, ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a", Name: "Leo Tolstoy", Authors: []string{}},
+		{
+			Kind:      RecordKindBook,
+			ID:        "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b",
+			Name:      `War and Peace; "Voina i mir", volume 1`,
+			Authors:   []string{"Leo Tolstoy"},
+			AuthorIDs: []string{"0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a"},
+			ISBN:      "9780140447934",
+			Publisher: "Penguin Classics",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format entity.CatalogFormat
+		gzip   bool
+	}{
+		{name: "csv", format: entity.CatalogFormatCSV},
+		{name: "jsonl", format: entity.CatalogFormatJSONL},
+		{name: "gzipped csv", format: entity.CatalogFormatCSV, gzip: true},
+		{name: "gzipped jsonl", format: entity.CatalogFormatJSONL, gzip: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			var out io.Writer = &buf
+			var compressed *gzip.Writer
+
+			if test.gzip {
+				compressed = gzip.NewWriter(&buf)
+				out = compressed
+			}
+
+			writer, err := NewWriter(test.format, out)
+			require.NoError(t, err)
+
+			for _, record := range records {
+				require.NoError(t, writer.Write(record))
+			}
+
+			require.NoError(t, writer.Close())
+
+			if compressed != nil {
+				require.NoError(t, compressed.Close())
+			}
+
+			reader, err := NewReader(test.format, &buf)
+			require.NoError(t, err)
+
+			results := readAll(t, reader)
+			require.Len(t, results, len(records))
+
+			for i, result := range results {
+				require.NoError(t, result.err)
+				require.Equal(t, records[i], result.record)
+			}
+		})
+	}
+}
+
+func TestCSVWriterEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	writer, err := NewWriter(entity.CatalogFormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.Equal(t, "kind,id,name,authors,author_ids,isbn,publisher\n", buf.String())
+
+	_, err = NewWriter("xml", &buf)
+	require.ErrorIs(t, err, entity.ErrUnknownCatalogFormat)
+}
@@ -0,0 +1,125 @@
+package citation
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/project/library/internal/entity"
+)
+
+var bibTeXEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// escapeBibTeX makes text safe inside a braced field value: the special
+// characters of TeX are escaped and line breaks, which end an entry for
+// some parsers, become spaces.
+func escapeBibTeX(text string) string {
+	return bibTeXEscaper.Replace(strings.Join(strings.Fields(text), " "))
+}
+
+// bibTeXName writes a name in the "von Last, Jr, First" form, bracing the
+// parts containing a standalone "and" so it doesn't split the author list.
+func bibTeXName(name entity.PersonName) string {
+	parts := []string{bibTeXPart(name.FamilyWithParticle())}
+
+	if name.Suffix != "" {
+		parts = append(parts, bibTeXPart(name.Suffix))
+	}
+
+	if name.Given != "" {
+		parts = append(parts, bibTeXPart(name.Given))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func bibTeXPart(part string) string {
+	part = escapeBibTeX(part)
+
+	for _, word := range strings.Fields(part) {
+		if strings.EqualFold(word, "and") {
+			return "{" + part + "}"
+		}
+	}
+
+	return part
+}
+
+// bibTeXKey is the family name of the first author followed by the first
+// word of the title, transliterated and stripped to ASCII letters and
+// digits.
+func bibTeXKey(book entity.Book, authors []entity.Author) string {
+	var key strings.Builder
+
+	if len(authors) > 0 {
+		key.WriteString(keyWord(entity.SplitPersonName(authors[0].Name).Family))
+	}
+
+	for _, word := range strings.Fields(book.Name) {
+		if word = keyWord(word); word != "" {
+			key.WriteString(word)
+			break
+		}
+	}
+
+	if key.Len() == 0 {
+		return "book"
+	}
+
+	return key.String()
+}
+
+func keyWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+
+		return -1
+	}, entity.Transliterate(word))
+}
+
+func bibTeX(book entity.Book, authors []entity.Author) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("@book{" + bibTeXKey(book, authors) + ",\n")
+
+	if len(authors) > 0 {
+		names := make([]string, 0, len(authors))
+
+		for _, author := range authors {
+			names = append(names, bibTeXName(entity.SplitPersonName(author.Name)))
+		}
+
+		writeBibTeXField(&buf, "author", strings.Join(names, " and "))
+	}
+
+	writeBibTeXField(&buf, "title", escapeBibTeX(book.Name))
+
+	if book.Publisher != "" {
+		writeBibTeXField(&buf, "publisher", escapeBibTeX(book.Publisher))
+	}
+
+	if book.ISBN != "" {
+		writeBibTeXField(&buf, "isbn", escapeBibTeX(book.ISBN))
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+func writeBibTeXField(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString("  " + name + " = {" + value + "},\n")
+}
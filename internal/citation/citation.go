@@ -0,0 +1,108 @@
+// Package citation renders a book with its authors as BibTeX, RIS,
+// CSL-JSON or schema.org Book JSON-LD.
+package citation
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/project/library/internal/entity"
+)
+
+// contentTypes are the media types of the formats, the first one is the
+// type a citation is sent with.
+var contentTypes = map[entity.CitationFormat][]string{
+	entity.CitationFormatBibTeX:  {"application/x-bibtex", "text/x-bibtex"},
+	entity.CitationFormatRIS:     {"application/x-research-info-systems"},
+	entity.CitationFormatCSLJSON: {"application/vnd.citationstyles.csl+json"},
+	entity.CitationFormatJSONLD:  {"application/ld+json"},
+}
+
+// DefaultFormat is used when the client names no format.
+const DefaultFormat = entity.CitationFormatBibTeX
+
+// ContentType returns the media type of a citation in the format.
+func ContentType(format entity.CitationFormat) string {
+	if types, ok := contentTypes[format]; ok {
+		return types[0] + "; charset=utf-8"
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// Negotiate picks the format for an Accept header: the known media type
+// with the highest quality, DefaultFormat for a wildcard or an empty header.
+// It reports false when the header accepts none of the formats.
+func Negotiate(accept string) (entity.CitationFormat, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return DefaultFormat, true
+	}
+
+	var (
+		best    entity.CitationFormat
+		quality = -1.0
+	)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+
+		if value, ok := params["q"]; ok {
+			if q, ok = parseQuality(value); !ok {
+				continue
+			}
+		}
+
+		format, ok := formatOf(mediaType)
+
+		if ok && q > 0 && q > quality {
+			best, quality = format, q
+		}
+	}
+
+	return best, quality > 0
+}
+
+func parseQuality(value string) (float64, bool) {
+	q, err := strconv.ParseFloat(value, 64)
+
+	return q, err == nil && q >= 0 && q <= 1
+}
+
+func formatOf(mediaType string) (entity.CitationFormat, bool) {
+	if mediaType == "*/*" || mediaType == "application/*" || mediaType == "text/*" {
+		return DefaultFormat, true
+	}
+
+	for format, types := range contentTypes {
+		for _, known := range types {
+			if mediaType == known {
+				return format, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Render writes the citation of the book by the authors, in their order.
+func Render(format entity.CitationFormat, book entity.Book, authors []entity.Author) ([]byte, error) {
+	switch format {
+	case entity.CitationFormatBibTeX:
+		return bibTeX(book, authors), nil
+	case entity.CitationFormatRIS:
+		return ris(book, authors), nil
+	case entity.CitationFormatCSLJSON:
+		return cslJSON(book, authors)
+	case entity.CitationFormatJSONLD:
+		return jsonLD(book, authors)
+	default:
+		return nil, entity.ErrUnknownCitationFormat
+	}
+}
@@ -0,0 +1,156 @@
+package citation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testBook = entity.Book{
+		ID:        "0191c3a5-7a1c-7b5e-9d3e-6f2b8c1d4e5f",
+		Name:      "Symphonies & Sonatas_100% {complete}",
+		ISBN:      "9780306406157",
+		Publisher: "Barnes and Noble",
+	}
+	testAuthors = []entity.Author{
+		{ID: "0191c3a5-7a1c-7b5e-9d3e-000000000001", Name: "Ludwig van Beethoven"},
+		{ID: "0191c3a5-7a1c-7b5e-9d3e-000000000002", Name: "Martin Luther King Jr."},
+		{ID: "0191c3a5-7a1c-7b5e-9d3e-000000000003", Name: "Simon and Garfunkel"},
+	}
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		accept string
+		want   entity.CitationFormat
+		ok     bool
+	}{
+		{"", entity.CitationFormatBibTeX, true},
+		{"*/*", entity.CitationFormatBibTeX, true},
+		{"application/x-research-info-systems", entity.CitationFormatRIS, true},
+		{"application/ld+json, application/vnd.citationstyles.csl+json;q=0.9", entity.CitationFormatJSONLD, true},
+		{"application/ld+json;q=0.5, application/vnd.citationstyles.csl+json", entity.CitationFormatCSLJSON, true},
+		{"text/html, */*;q=0.1", entity.CitationFormatBibTeX, true},
+		{"application/ld+json;q=0, text/x-bibtex;q=0.2", entity.CitationFormatBibTeX, true},
+		{"text/html", "", false},
+		{"application/ld+json;q=2", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			t.Parallel()
+
+			format, ok := Negotiate(test.accept)
+			require.Equal(t, test.ok, ok)
+
+			if ok {
+				require.Equal(t, test.want, format)
+			}
+		})
+	}
+}
+
+func TestRenderBibTeX(t *testing.T) {
+	t.Parallel()
+
+	out, err := Render(entity.CitationFormatBibTeX, testBook, testAuthors)
+	require.NoError(t, err)
+	require.Equal(t, `@book{beethovensymphonies,
+  author = {van Beethoven, Ludwig and King, Jr., Martin Luther and {Simon and Garfunkel}},
+  title = {Symphonies \& Sonatas\_100\% \{complete\}},
+  publisher = {Barnes and Noble},
+  isbn = {9780306406157},
+}
+`, string(out))
+}
+
+func TestEscapeBibTeX(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `a\textbackslash{}b \textasciitilde{}\textasciicircum{} \#\$ c`, escapeBibTeX("a\\b ~^ #$\n c"))
+}
+
+func TestRenderBibTeXWithoutAuthors(t *testing.T) {
+	t.Parallel()
+
+	out, err := Render(entity.CitationFormatBibTeX, entity.Book{Name: "—"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "@book{book,\n  title = {—},\n}\n", string(out))
+}
+
+func TestRenderRIS(t *testing.T) {
+	t.Parallel()
+
+	book := testBook
+	book.Name = "Symphonies\nand Sonatas"
+
+	out, err := Render(entity.CitationFormatRIS, book, testAuthors)
+	require.NoError(t, err)
+	require.Equal(t, "TY  - BOOK\r\n"+
+		"AU  - van Beethoven, Ludwig\r\n"+
+		"AU  - King, Martin Luther, Jr.\r\n"+
+		"AU  - Simon and Garfunkel\r\n"+
+		"TI  - Symphonies and Sonatas\r\n"+
+		"PB  - Barnes and Noble\r\n"+
+		"SN  - 9780306406157\r\n"+
+		"ID  - 0191c3a5-7a1c-7b5e-9d3e-6f2b8c1d4e5f\r\n"+
+		"ER  - \r\n", string(out))
+}
+
+func TestRenderCSLJSON(t *testing.T) {
+	t.Parallel()
+
+	out, err := Render(entity.CitationFormatCSLJSON, testBook, testAuthors[:2])
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"title": "Symphonies & Sonatas_100% {complete}"`)
+
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(out, &items))
+	require.Len(t, items, 1)
+	require.Equal(t, "book", items[0]["type"])
+	require.Equal(t, "9780306406157", items[0]["ISBN"])
+	require.Equal(t, []any{
+		map[string]any{"family": "Beethoven", "given": "Ludwig", "non-dropping-particle": "van"},
+		map[string]any{"family": "King", "given": "Martin Luther", "suffix": "Jr."},
+	}, items[0]["author"])
+}
+
+func TestRenderJSONLD(t *testing.T) {
+	t.Parallel()
+
+	out, err := Render(entity.CitationFormatJSONLD, testBook, testAuthors[:1])
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(out, &document))
+	require.Equal(t, map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "Book",
+		"@id":      "urn:uuid:" + testBook.ID,
+		"name":     testBook.Name,
+		"isbn":     "9780306406157",
+		"publisher": map[string]any{
+			"@type": "Organization",
+			"name":  "Barnes and Noble",
+		},
+		"author": []any{map[string]any{
+			"@type":      "Person",
+			"@id":        "urn:uuid:" + testAuthors[0].ID,
+			"name":       "Ludwig van Beethoven",
+			"givenName":  "Ludwig",
+			"familyName": "van Beethoven",
+		}},
+	}, document)
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := Render("endnote", testBook, testAuthors)
+	require.ErrorIs(t, err, entity.ErrUnknownCitationFormat)
+}
@@ -0,0 +1,107 @@
+package citation
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/project/library/internal/entity"
+)
+
+type cslName struct {
+	Family              string `json:"family"`
+	Given               string `json:"given,omitempty"`
+	NonDroppingParticle string `json:"non-dropping-particle,omitempty"`
+	Suffix              string `json:"suffix,omitempty"`
+}
+
+type cslItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Author    []cslName `json:"author,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	ISBN      string    `json:"ISBN,omitempty"`
+}
+
+func cslJSON(book entity.Book, authors []entity.Author) ([]byte, error) {
+	item := cslItem{
+		ID:        book.ID,
+		Type:      "book",
+		Title:     book.Name,
+		Publisher: book.Publisher,
+		ISBN:      book.ISBN,
+	}
+
+	for _, author := range authors {
+		name := entity.SplitPersonName(author.Name)
+		item.Author = append(item.Author, cslName{
+			Family:              name.Family,
+			Given:               name.Given,
+			NonDroppingParticle: name.Particle,
+			Suffix:              name.Suffix,
+		})
+	}
+
+	return marshal([]cslItem{item})
+}
+
+type schemaThing struct {
+	Type       string `json:"@type"`
+	ID         string `json:"@id,omitempty"`
+	Name       string `json:"name"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type schemaBook struct {
+	Context   string        `json:"@context"`
+	Type      string        `json:"@type"`
+	ID        string        `json:"@id"`
+	Name      string        `json:"name"`
+	Author    []schemaThing `json:"author,omitempty"`
+	Publisher *schemaThing  `json:"publisher,omitempty"`
+	ISBN      string        `json:"isbn,omitempty"`
+}
+
+func jsonLD(book entity.Book, authors []entity.Author) ([]byte, error) {
+	document := schemaBook{
+		Context: "https://schema.org",
+		Type:    "Book",
+		ID:      "urn:uuid:" + book.ID,
+		Name:    book.Name,
+		ISBN:    book.ISBN,
+	}
+
+	for _, author := range authors {
+		name := entity.SplitPersonName(author.Name)
+		document.Author = append(document.Author, schemaThing{
+			Type:       "Person",
+			ID:         "urn:uuid:" + author.ID,
+			Name:       author.Name,
+			GivenName:  name.Given,
+			FamilyName: name.FamilyWithParticle(),
+		})
+	}
+
+	if book.Publisher != "" {
+		document.Publisher = &schemaThing{Type: "Organization", Name: book.Publisher}
+	}
+
+	return marshal(document)
+}
+
+// marshal encodes without HTML escaping, the documents are not embedded
+// in pages and "&" in a title should stay readable.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+package citation
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/project/library/internal/entity"
+)
+
+// risValue puts a value on a single line, RIS has no way to continue one.
+func risValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// risName writes a name in the "Last, First, Suffix" form of the AU tag.
+func risName(name entity.PersonName) string {
+	parts := []string{risValue(name.FamilyWithParticle())}
+
+	if name.Given != "" || name.Suffix != "" {
+		parts = append(parts, risValue(name.Given))
+	}
+
+	if name.Suffix != "" {
+		parts = append(parts, risValue(name.Suffix))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func ris(book entity.Book, authors []entity.Author) []byte {
+	var buf bytes.Buffer
+
+	writeRISTag(&buf, "TY", "BOOK")
+
+	for _, author := range authors {
+		writeRISTag(&buf, "AU", risName(entity.SplitPersonName(author.Name)))
+	}
+
+	writeRISTag(&buf, "TI", risValue(book.Name))
+
+	if book.Publisher != "" {
+		writeRISTag(&buf, "PB", risValue(book.Publisher))
+	}
+
+	if book.ISBN != "" {
+		writeRISTag(&buf, "SN", book.ISBN)
+	}
+
+	writeRISTag(&buf, "ID", book.ID)
+	writeRISTag(&buf, "ER", "")
+
+	return buf.Bytes()
+}
+
+func writeRISTag(buf *bytes.Buffer, tag string, value string) {
+	buf.WriteString(tag + "  - " + value + "\r\n")
+}
@@ -2,43 +2,20 @@ package controller
 
 import (
 	"context"
-	"time"
 
-	"go.opentelemetry.io/otel/trace"
-	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/project/library/generated/api/library"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-func (i *implementation) AddBook(ctx context.Context, req *library.AddBookRequest) (ans *library.AddBookResponse, erro error) {
-	with, err := durations.GetMetricWithLabelValues("AddBook")
-	if err != nil {
-		i.logger.Error("Can't get duration metric", zap.Error(err))
-	}
-
-	var traceID = zap.String("traceID", trace.SpanFromContext(ctx).SpanContext().TraceID().String())
-	i.logger.Info("AddBook called", traceID)
-	start := time.Now()
-
-	defer func() {
-		with.Observe(float64(time.Since(start).Milliseconds()))
-		if erro != nil {
-			i.logger.Error("AddBook error", zap.Error(erro), traceID)
-			trace.SpanFromContext(ctx).RecordError(erro)
-		} else {
-			i.logger.Info("AddBook completed", traceID)
-		}
-		trace.SpanFromContext(ctx).End()
-	}()
-
+func (i *implementation) AddBook(ctx context.Context, req *library.AddBookRequest) (*library.AddBookResponse, error) {
 	if err := req.ValidateAll(); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, validationErr(err)
 	}
 
-	book, err := i.booksUseCase.RegisterBook(ctx, req.GetName(), req.GetAuthorIds())
+	ctx = withIdempotencyKey(withActor(ctx), req.GetIdempotencyKey())
+
+	book, err := i.booksUseCase.RegisterBook(ctx, req.GetId(), req.GetName(), req.GetAuthorIds())
 
 	if err != nil {
 		return nil, i.convertErr(err)
@@ -49,6 +26,8 @@ func (i *implementation) AddBook(ctx context.Context, req *library.AddBookReques
 			Id:        book.ID,
 			Name:      book.Name,
 			AuthorId:  book.AuthorIDs,
+			Isbn:      book.ISBN,
+			Publisher: book.Publisher,
 			CreatedAt: timestamppb.New(book.CreatedAt),
 			UpdatedAt: timestamppb.New(book.UpdatedAt),
 		},
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/entity"
+)
+
+func authorProfileFromRequest(profile *library.AuthorProfile) *entity.AuthorProfile {
+	if profile == nil {
+		return nil
+	}
+
+	return &entity.AuthorProfile{
+		Biography:      profile.GetBiography(),
+		BirthDate:      profile.GetBirthDate(),
+		DeathDate:      profile.GetDeathDate(),
+		Nationality:    profile.GetNationality(),
+		AlternateNames: profile.GetAlternateNames(),
+		PseudonymOf:    profile.GetPseudonymOf(),
+		VIAF:           profile.GetViaf(),
+		ISNI:           profile.GetIsni(),
+		WikidataID:     profile.GetWikidataId(),
+	}
+}
+
+func authorProfileToResponse(profile entity.AuthorProfile) *library.AuthorProfile {
+	return &library.AuthorProfile{
+		Biography:      profile.Biography,
+		BirthDate:      profile.BirthDate,
+		DeathDate:      profile.DeathDate,
+		Nationality:    profile.Nationality,
+		AlternateNames: profile.AlternateNames,
+		PseudonymOf:    profile.PseudonymOf,
+		PseudonymIds:   profile.Pseudonyms,
+		Viaf:           profile.VIAF,
+		Isni:           profile.ISNI,
+		WikidataId:     profile.WikidataID,
+	}
+}
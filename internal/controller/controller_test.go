@@ -1,21 +1,31 @@
 package controller
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
 	"github.com/project/library/generated/api/library"
 	"github.com/project/library/generated/mocks"
 	"github.com/project/library/internal/entity"
+	usecase "github.com/project/library/internal/usecase/library"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const FAILURE = "failure"
@@ -38,8 +48,8 @@ func TestAddBook(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	bookMock.EXPECT().RegisterBook(gomock.Any(), FAILURE, gomock.Any()).Return(entity.Book{}, entity.ErrBookAlreadyExists)
-	bookMock.EXPECT().RegisterBook(gomock.Any(), SUCCESS, gomock.Any()).Return(successBook, nil)
+	bookMock.EXPECT().RegisterBook(gomock.Any(), gomock.Any(), FAILURE, gomock.Any()).Return(entity.Book{}, entity.ErrBookAlreadyExists)
+	bookMock.EXPECT().RegisterBook(gomock.Any(), gomock.Any(), SUCCESS, gomock.Any()).Return(successBook, nil)
 
 	tests := []struct {
 		name         string
@@ -56,7 +66,7 @@ func TestAddBook(t *testing.T) {
 				AuthorIds: nil,
 			},
 			expectedBook: nil,
-			expectedErr:  codes.Internal,
+			expectedErr:  codes.AlreadyExists,
 		},
 		{
 			name:   FAILURE + "_uuid_format",
@@ -109,8 +119,8 @@ func TestChangeAuthorInfo(t *testing.T) {
 
 	target := New(zaptest.NewLogger(t), bookMock, authorMock)
 
-	authorMock.EXPECT().UpdateAuthor(gomock.Any(), gomock.Any(), FAILURE).Return(entity.ErrAuthorNotFound)
-	authorMock.EXPECT().UpdateAuthor(gomock.Any(), gomock.Any(), SUCCESS).Return(nil)
+	authorMock.EXPECT().UpdateAuthor(gomock.Any(), gomock.Any(), FAILURE, nil).Return(entity.ErrAuthorNotFound)
+	authorMock.EXPECT().UpdateAuthor(gomock.Any(), gomock.Any(), SUCCESS, nil).Return(nil)
 
 	tests := []struct {
 		name           string
@@ -165,6 +175,67 @@ func TestChangeAuthorInfo(t *testing.T) {
 	}
 }
 
+func TestAuthorProfile(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	author := entity.Author{
+		ID:   uuid.NewString(),
+		Name: "Gabriel García Márquez",
+		Profile: entity.AuthorProfile{
+			Biography:      "Colombian novelist.",
+			BirthDate:      "1927-03-06",
+			DeathDate:      "2014-04-17",
+			Nationality:    "CO",
+			AlternateNames: []string{"Gabo"},
+			Pseudonyms:     []string{uuid.NewString()},
+			VIAF:           "66468564",
+			WikidataID:     "Q5878",
+		},
+	}
+	profile := &library.AuthorProfile{
+		Biography:      "Colombian novelist.",
+		BirthDate:      "1927-03-06",
+		DeathDate:      "2014-04-17",
+		Nationality:    "CO",
+		AlternateNames: []string{"Gabo"},
+		Viaf:           "66468564",
+		WikidataId:     "Q5878",
+	}
+
+	update := author.Profile
+	update.Pseudonyms = nil
+
+	authorMock.EXPECT().UpdateAuthor(gomock.Any(), author.ID, author.Name, &update).Return(nil)
+	authorMock.EXPECT().UpdateAuthor(gomock.Any(), author.ID, author.Name, gomock.Any()).Return(entity.ErrInvalidISNI)
+	authorMock.EXPECT().GetAuthorInfo(gomock.Any(), author.ID).Return(author, nil)
+
+	_, err := target.ChangeAuthorInfo(t.Context(), &library.ChangeAuthorInfoRequest{Id: author.ID, Name: author.Name, Profile: profile})
+	require.NoError(t, err)
+
+	_, err = target.ChangeAuthorInfo(t.Context(), &library.ChangeAuthorInfoRequest{
+		Id:      author.ID,
+		Name:    author.Name,
+		Profile: &library.AuthorProfile{Isni: "0000000122819550"},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = target.ChangeAuthorInfo(t.Context(), &library.ChangeAuthorInfoRequest{Id: author.ID, Name: "Gabo  "})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	actual, err := target.GetAuthorInfo(t.Context(), &library.GetAuthorInfoRequest{Id: author.ID})
+	require.NoError(t, err)
+	require.Equal(t, "Márquez, Gabriel García", actual.GetSortName())
+
+	profile.PseudonymIds = author.Profile.Pseudonyms
+	require.True(t, proto.Equal(profile, actual.GetProfile()))
+}
+
 func TestGetAuthorInfo(t *testing.T) {
 	t.Parallel()
 
@@ -215,8 +286,10 @@ func TestGetAuthorInfo(t *testing.T) {
 				Id: successID,
 			},
 			expectedAuthor: &library.GetAuthorInfoResponse{
-				Id:   successID,
-				Name: SUCCESS,
+				Id:       successID,
+				Name:     SUCCESS,
+				SortName: SUCCESS,
+				Profile:  &library.AuthorProfile{},
 			},
 			expectedErr: codes.Internal,
 		},
@@ -253,7 +326,7 @@ func TestGetBookInfo(t *testing.T) {
 		ID:        successID,
 		Name:      SUCCESS,
 		AuthorIDs: nil,
-		CreatedAt: time.Now(),
+		CreatedAt: time.Now().Add(-time.Hour),
 		UpdatedAt: time.Now(),
 	}
 	bookMock.EXPECT().GetBook(gomock.Any(), successID).Return(successBook, nil)
@@ -295,7 +368,7 @@ func TestGetBookInfo(t *testing.T) {
 					Name:      successBook.Name,
 					AuthorId:  successBook.AuthorIDs,
 					CreatedAt: timestamppb.New(successBook.CreatedAt),
-					UpdatedAt: timestamppb.New(successBook.CreatedAt),
+					UpdatedAt: timestamppb.New(successBook.UpdatedAt),
 				},
 			},
 			expectedErr: codes.Internal,
@@ -327,12 +400,12 @@ func TestRegisterAuthor(t *testing.T) {
 
 	successID := uuid.New().String()
 
-	authorMock.EXPECT().RegisterAuthor(gomock.Any(), FAILURE).Return(entity.Author{}, entity.ErrAuthorAlreadyExists)
+	authorMock.EXPECT().RegisterAuthor(gomock.Any(), gomock.Any(), FAILURE).Return(entity.Author{}, entity.ErrAuthorAlreadyExists)
 	successAuthor := entity.Author{
 		Name: SUCCESS,
 		ID:   successID,
 	}
-	authorMock.EXPECT().RegisterAuthor(gomock.Any(), SUCCESS).Return(successAuthor, nil)
+	authorMock.EXPECT().RegisterAuthor(gomock.Any(), gomock.Any(), SUCCESS).Return(successAuthor, nil)
 
 	tests := []struct {
 		name           string
@@ -357,7 +430,7 @@ func TestRegisterAuthor(t *testing.T) {
 				Name: FAILURE,
 			},
 			expectedAuthor: nil,
-			expectedErr:    codes.Internal,
+			expectedErr:    codes.AlreadyExists,
 		},
 		{
 			name:   SUCCESS,
@@ -453,3 +526,706 @@ func TestUpdateBook(t *testing.T) {
 		})
 	}
 }
+
+func TestFindDuplicateAuthors(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	first := entity.Author{ID: uuid.New().String(), Name: "Fyodor Dostoevsky"}
+	second := entity.Author{ID: uuid.New().String(), Name: "Fyodor Dostoyevsky"}
+
+	authorMock.EXPECT().FindDuplicateAuthors(gomock.Any(), 0.5, 10).Return([]entity.AuthorDuplicate{
+		{First: first, Second: second, Similarity: 0.7},
+	}, nil)
+	authorMock.EXPECT().FindDuplicateAuthors(gomock.Any(), 0.9, 10).Return(nil, entity.ErrAuthorNotFound)
+
+	tests := []struct {
+		name        string
+		req         *library.FindDuplicateAuthorsRequest
+		expected    *library.FindDuplicateAuthorsResponse
+		expectedErr codes.Code
+	}{
+		{
+			name: "invalid threshold",
+			req: &library.FindDuplicateAuthorsRequest{
+				Threshold: 2,
+			},
+			expected:    nil,
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name: "use case error",
+			req: &library.FindDuplicateAuthorsRequest{
+				Threshold: 0.9,
+				Limit:     10,
+			},
+			expected:    nil,
+			expectedErr: codes.NotFound,
+		},
+		{
+			name: SUCCESS,
+			req: &library.FindDuplicateAuthorsRequest{
+				Threshold: 0.5,
+				Limit:     10,
+			},
+			expected: &library.FindDuplicateAuthorsResponse{
+				Duplicates: []*library.DuplicateAuthors{
+					{
+						First:      &library.Author{Id: first.ID, Name: first.Name},
+						Second:     &library.Author{Id: second.ID, Name: second.Name},
+						Similarity: 0.7,
+					},
+				},
+			},
+			expectedErr: codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := target.FindDuplicateAuthors(t.Context(), test.req)
+			require.Equal(t, test.expected, actual)
+			require.Equal(t, test.expectedErr, status.Code(err))
+		})
+	}
+}
+
+func TestAuthorAlreadyExistsDetails(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	existingID := uuid.New().String()
+
+	authorMock.EXPECT().RegisterAuthor(gomock.Any(), gomock.Any(), "Leo Tolstoy").
+		Return(entity.Author{}, &entity.AuthorAlreadyExistsError{ExistingID: existingID})
+
+	_, err := target.RegisterAuthor(t.Context(), &library.RegisterAuthorRequest{Name: "Leo Tolstoy"})
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.AlreadyExists, s.Code())
+	require.Len(t, s.Details(), 2)
+
+	info, ok := s.Details()[0].(*errdetails.ResourceInfo)
+	require.True(t, ok)
+	require.Equal(t, entity.ResourceAuthor, info.GetResourceType())
+	require.Equal(t, existingID, info.GetResourceName())
+
+	errorInfo, ok := s.Details()[1].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "AUTHOR_ALREADY_EXISTS", errorInfo.GetReason())
+	require.Equal(t, ErrorDomain, errorInfo.GetDomain())
+}
+
+func TestConvertErr(t *testing.T) {
+	t.Parallel()
+
+	target := New(zaptest.NewLogger(t), nil, nil)
+
+	t.Run("field violation", func(t *testing.T) {
+		t.Parallel()
+
+		s := status.Convert(target.convertErr(errors.Wrap(entity.ErrInvalidNationality, `"XYZ"`)))
+		require.Equal(t, codes.InvalidArgument, s.Code())
+		require.Len(t, s.Details(), 2)
+
+		badRequest, ok := s.Details()[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Equal(t, "profile.nationality", badRequest.GetFieldViolations()[0].GetField())
+		require.Equal(t, "INVALID_NATIONALITY", badRequest.GetFieldViolations()[0].GetReason())
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("%w: %w", entity.ErrStorageUnavailable, errors.New("FATAL: terminating connection"))
+		s := status.Convert(target.convertErr(err))
+		require.Equal(t, codes.Unavailable, s.Code())
+		require.NotContains(t, s.Message(), "FATAL")
+
+		retryInfo, ok := s.Details()[1].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		require.Equal(t, time.Second, retryInfo.GetRetryDelay().AsDuration())
+	})
+
+	t.Run("unexpected", func(t *testing.T) {
+		t.Parallel()
+
+		s := status.Convert(target.convertErr(errors.New(`ERROR: relation "book" does not exist (SQLSTATE 42P01)`)))
+		require.Equal(t, codes.Internal, s.Code())
+		require.Equal(t, "internal error", s.Message())
+		require.Empty(t, s.Details())
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+
+		s := status.Convert(target.convertErr(entity.ErrImportCheckpointConflict))
+		require.Equal(t, codes.Aborted, s.Code())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, codes.Canceled, status.Code(target.convertErr(context.Canceled)))
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		t.Parallel()
+
+		err := entity.NewError(entity.ErrorKind(100), "UNKNOWN", "unknown kind")
+		require.Equal(t, codes.Internal, status.Code(target.convertErr(err)))
+	})
+}
+
+func TestValidationErr(t *testing.T) {
+	t.Parallel()
+
+	req := &library.MergeAuthorsRequest{SourceIds: []string{uuid.New().String(), "not-a-uuid"}, TargetId: "nope"}
+	s := status.Convert(validationErr(req.ValidateAll()))
+	require.Equal(t, codes.InvalidArgument, s.Code())
+
+	badRequest, ok := s.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+
+	fields := make([]string, 0, len(badRequest.GetFieldViolations()))
+
+	for _, violation := range badRequest.GetFieldViolations() {
+		fields = append(fields, violation.GetField())
+	}
+
+	require.Equal(t, []string{"source_ids[1]", "target_id"}, fields)
+
+	nested := &library.ChangeAuthorInfoRequest{
+		Id:      uuid.New().String(),
+		Name:    "Leo Tolstoy",
+		Profile: &library.AuthorProfile{BirthDate: "1828-9-9"},
+	}
+	s = status.Convert(validationErr(nested.ValidateAll()))
+
+	badRequest, ok = s.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Equal(t, "profile.birth_date", badRequest.GetFieldViolations()[0].GetField())
+}
+
+func TestMergeAuthors(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	sourceID := uuid.New().String()
+	targetID := uuid.New().String()
+	missingID := uuid.New().String()
+
+	authorMock.EXPECT().MergeAuthors(gomock.Any(), []string{sourceID}, targetID).Return(entity.Author{ID: targetID, Name: SUCCESS}, nil)
+	authorMock.EXPECT().MergeAuthors(gomock.Any(), []string{missingID}, targetID).Return(entity.Author{}, entity.ErrAuthorNotFound)
+	authorMock.EXPECT().MergeAuthors(gomock.Any(), []string{targetID}, targetID).Return(entity.Author{}, entity.ErrMergeIntoSource)
+
+	tests := []struct {
+		name        string
+		req         *library.MergeAuthorsRequest
+		expected    *library.MergeAuthorsResponse
+		expectedErr codes.Code
+	}{
+		{
+			name:        "no sources",
+			req:         &library.MergeAuthorsRequest{TargetId: targetID},
+			expected:    nil,
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name:        "target among sources",
+			req:         &library.MergeAuthorsRequest{SourceIds: []string{targetID}, TargetId: targetID},
+			expected:    nil,
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name:        "not found",
+			req:         &library.MergeAuthorsRequest{SourceIds: []string{missingID}, TargetId: targetID},
+			expected:    nil,
+			expectedErr: codes.NotFound,
+		},
+		{
+			name: SUCCESS,
+			req:  &library.MergeAuthorsRequest{SourceIds: []string{sourceID}, TargetId: targetID},
+			expected: &library.MergeAuthorsResponse{
+				Author: &library.Author{Id: targetID, Name: SUCCESS},
+			},
+			expectedErr: codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := target.MergeAuthors(t.Context(), test.req)
+			require.Equal(t, test.expected, actual)
+			require.Equal(t, test.expectedErr, status.Code(err))
+		})
+	}
+}
+
+func TestMergeBooks(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	sourceID := uuid.New().String()
+	targetID := uuid.New().String()
+	authorID := uuid.New().String()
+
+	bookMock.EXPECT().MergeBooks(gomock.Any(), []string{sourceID}, targetID).Return(entity.Book{
+		ID:        targetID,
+		Name:      SUCCESS,
+		AuthorIDs: []string{authorID},
+	}, nil)
+
+	tests := []struct {
+		name        string
+		req         *library.MergeBooksRequest
+		expected    *library.MergeBooksResponse
+		expectedErr codes.Code
+	}{
+		{
+			name:        "invalid target",
+			req:         &library.MergeBooksRequest{SourceIds: []string{sourceID}, TargetId: "invalid id"},
+			expected:    nil,
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name: SUCCESS,
+			req:  &library.MergeBooksRequest{SourceIds: []string{sourceID}, TargetId: targetID},
+			expected: &library.MergeBooksResponse{
+				Book: &library.Book{
+					Id:        targetID,
+					Name:      SUCCESS,
+					AuthorId:  []string{authorID},
+					CreatedAt: timestamppb.New(time.Time{}),
+					UpdatedAt: timestamppb.New(time.Time{}),
+				},
+			},
+			expectedErr: codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := target.MergeBooks(t.Context(), test.req)
+			require.Equal(t, test.expected, actual)
+			require.Equal(t, test.expectedErr, status.Code(err))
+		})
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	successID := uuid.New().String()
+	failureID := uuid.New().String()
+
+	bookMock.EXPECT().DeleteBook(gomock.Any(), successID).Return(nil)
+	bookMock.EXPECT().DeleteBook(gomock.Any(), failureID).Return(entity.ErrBookNotFound)
+	bookMock.EXPECT().RestoreBook(gomock.Any(), successID).Return(nil)
+	authorMock.EXPECT().DeleteAuthor(gomock.Any(), successID).Return(nil)
+	authorMock.EXPECT().RestoreAuthor(gomock.Any(), failureID).Return(entity.ErrAuthorNotFound)
+
+	tests := []struct {
+		name        string
+		call        func() error
+		expectedErr codes.Code
+	}{
+		{
+			name: "delete book",
+			call: func() error {
+				_, err := target.DeleteBook(t.Context(), &library.DeleteBookRequest{Id: successID})
+				return err
+			},
+			expectedErr: codes.OK,
+		},
+		{
+			name: "delete missing book",
+			call: func() error {
+				_, err := target.DeleteBook(t.Context(), &library.DeleteBookRequest{Id: failureID})
+				return err
+			},
+			expectedErr: codes.NotFound,
+		},
+		{
+			name: "restore book",
+			call: func() error {
+				_, err := target.RestoreBook(t.Context(), &library.RestoreBookRequest{Id: successID})
+				return err
+			},
+			expectedErr: codes.OK,
+		},
+		{
+			name: "delete author",
+			call: func() error {
+				_, err := target.DeleteAuthor(t.Context(), &library.DeleteAuthorRequest{Id: successID})
+				return err
+			},
+			expectedErr: codes.OK,
+		},
+		{
+			name: "restore missing author",
+			call: func() error {
+				_, err := target.RestoreAuthor(t.Context(), &library.RestoreAuthorRequest{Id: failureID})
+				return err
+			},
+			expectedErr: codes.NotFound,
+		},
+		{
+			name: "invalid id",
+			call: func() error {
+				_, err := target.RestoreAuthor(t.Context(), &library.RestoreAuthorRequest{Id: "invalid id"})
+				return err
+			},
+			expectedErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expectedErr, status.Code(test.call()))
+		})
+	}
+}
+
+func TestListDeleted(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	deletedAt := time.Now()
+	book := entity.Book{ID: uuid.New().String(), Name: SUCCESS, DeletedAt: deletedAt}
+	author := entity.Author{ID: uuid.New().String(), Name: SUCCESS, DeletedAt: deletedAt}
+
+	bookMock.EXPECT().ListDeletedBooks(gomock.Any(), 10).Return([]entity.Book{book}, nil)
+	authorMock.EXPECT().ListDeletedAuthors(gomock.Any(), 10).Return([]entity.Author{author}, nil)
+
+	actual, err := target.ListDeleted(t.Context(), &library.ListDeletedRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, &library.ListDeletedResponse{
+		Books: []*library.Book{{
+			Id:        book.ID,
+			Name:      book.Name,
+			CreatedAt: timestamppb.New(time.Time{}),
+			UpdatedAt: timestamppb.New(time.Time{}),
+			DeletedAt: timestamppb.New(deletedAt),
+		}},
+		Authors: []*library.Author{{
+			Id:        author.ID,
+			Name:      author.Name,
+			DeletedAt: timestamppb.New(deletedAt),
+		}},
+	}, actual)
+
+	_, err = target.ListDeleted(t.Context(), &library.ListDeletedRequest{Limit: 1001})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	id := uuid.New().String()
+	changedAt := time.Now()
+	entries := []entity.HistoryEntry{{
+		Operation: entity.HistoryOperationUpdate,
+		Actor:     SUCCESS,
+		ChangedAt: changedAt,
+		Changes:   []entity.FieldChange{{Field: "name", Old: `"old"`, New: `"new"`}},
+	}}
+	expected := []*library.HistoryEntry{{
+		Operation: library.HistoryOperation_HISTORY_OPERATION_UPDATE,
+		Actor:     SUCCESS,
+		ChangedAt: timestamppb.New(changedAt),
+		Changes:   []*library.FieldChange{{Field: "name", OldValue: `"old"`, NewValue: `"new"`}},
+	}}
+
+	bookMock.EXPECT().GetBookHistory(gomock.Any(), id, 5).Return(entries, nil)
+	authorMock.EXPECT().GetAuthorHistory(gomock.Any(), id, 0).Return(nil, entity.ErrAuthorNotFound)
+
+	books, err := target.GetBookHistory(t.Context(), &library.GetBookHistoryRequest{Id: id, Limit: 5})
+	require.NoError(t, err)
+	require.Equal(t, expected, books.GetEntries())
+
+	_, err = target.GetAuthorHistory(t.Context(), &library.GetAuthorHistoryRequest{Id: id})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = target.GetBookHistory(t.Context(), &library.GetBookHistoryRequest{Id: FAILURE})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetBookInfoAsOf(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	asOf := time.Now().Add(-time.Hour).UTC()
+	book := entity.Book{ID: uuid.New().String(), Name: SUCCESS}
+
+	bookMock.EXPECT().GetBookAsOf(gomock.Any(), book.ID, asOf).Return(book, nil)
+
+	actual, err := target.GetBookInfo(t.Context(), &library.GetBookInfoRequest{Id: book.ID, AsOf: timestamppb.New(asOf)})
+	require.NoError(t, err)
+	require.Equal(t, book.Name, actual.GetBook().GetName())
+}
+
+type importStreamStub struct {
+	grpc.ServerStream
+	requests []*library.ImportCatalogRequest
+	response *library.ImportCatalogResponse
+}
+
+func (s *importStreamStub) Context() context.Context {
+	return context.Background()
+}
+
+func (s *importStreamStub) Recv() (*library.ImportCatalogRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+
+	return req, nil
+}
+
+func (s *importStreamStub) SendAndClose(response *library.ImportCatalogResponse) error {
+	s.response = response
+	return nil
+}
+
+func TestImportCatalog(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	options := &library.ImportCatalogRequest{Payload: &library.ImportCatalogRequest_Options{Options: &library.ImportOptions{
+		Format:   library.CatalogFormat_CATALOG_FORMAT_JSONL,
+		DryRun:   true,
+		ImportId: SUCCESS,
+	}}}
+	chunk := func(data string) *library.ImportCatalogRequest {
+		return &library.ImportCatalogRequest{Payload: &library.ImportCatalogRequest_Chunk{Chunk: []byte(data)}}
+	}
+
+	bookMock.EXPECT().ImportCatalog(gomock.Any(), entity.ImportOptions{
+		Format:   entity.CatalogFormatJSONL,
+		DryRun:   true,
+		ImportID: SUCCESS,
+	}, gomock.Any()).DoAndReturn(func(_ context.Context, _ entity.ImportOptions, r io.Reader) (entity.ImportReport, error) {
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "first\nsecond\n", string(data))
+
+		return entity.ImportReport{Rows: 2, BooksImported: 1, Errors: []entity.ImportRowError{{Row: 2, Message: FAILURE}}, DryRun: true}, nil
+	})
+
+	stream := &importStreamStub{requests: []*library.ImportCatalogRequest{options, chunk("first\nsec"), chunk("ond\n")}}
+	require.NoError(t, target.ImportCatalog(stream))
+	require.Equal(t, &library.ImportCatalogResponse{
+		Rows:          2,
+		BooksImported: 1,
+		Errors:        []*library.ImportRowError{{Row: 2, Message: FAILURE}},
+		DryRun:        true,
+	}, stream.response)
+
+	bookMock.EXPECT().ImportCatalog(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.ImportOptions, r io.Reader) (entity.ImportReport, error) {
+			_, err := io.ReadAll(r)
+			return entity.ImportReport{}, err
+		})
+
+	err := target.ImportCatalog(&importStreamStub{requests: []*library.ImportCatalogRequest{options, chunk("first"), options}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	err = target.ImportCatalog(&importStreamStub{requests: []*library.ImportCatalogRequest{chunk("first")}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type exportStreamStub struct {
+	grpc.ServerStream
+	chunks [][]byte
+}
+
+func (s *exportStreamStub) Context() context.Context {
+	return context.Background()
+}
+
+func (s *exportStreamStub) Send(response *library.ExportCatalogResponse) error {
+	s.chunks = append(s.chunks, bytes.Clone(response.GetChunk()))
+	return nil
+}
+
+func TestExportCatalog(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	bookMock.EXPECT().ExportCatalog(gomock.Any(), entity.ExportOptions{Format: entity.CatalogFormatCSV, Gzip: true}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.ExportOptions, w io.Writer) error {
+			_, err := io.WriteString(w, SUCCESS)
+			return err
+		})
+
+	stream := &exportStreamStub{}
+	err := target.ExportCatalog(&library.ExportCatalogRequest{Format: library.CatalogFormat_CATALOG_FORMAT_CSV, Gzip: true}, stream)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(SUCCESS)}, stream.chunks)
+
+	err = target.ExportCatalog(&library.ExportCatalogRequest{}, &exportStreamStub{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetBookCitation(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorUseCase(control)
+	bookMock := mocks.NewMockBooksUseCase(control)
+
+	target := New(zaptest.NewLogger(t), bookMock, authorMock)
+
+	bookID := uuid.New().String()
+	missingID := uuid.New().String()
+
+	bookMock.EXPECT().GetBookCitation(gomock.Any(), bookID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, format entity.CitationFormat) ([]byte, error) {
+			return []byte(format), nil
+		}).AnyTimes()
+	bookMock.EXPECT().GetBookCitation(gomock.Any(), missingID, gomock.Any()).Return(nil, entity.ErrBookNotFound)
+
+	tests := []struct {
+		name                string
+		req                 *library.GetBookCitationRequest
+		accept              string
+		expectedErr         codes.Code
+		expectedContentType string
+		expectedData        string
+	}{
+		{
+			name:        "invalid id",
+			req:         &library.GetBookCitationRequest{Id: "invalid id"},
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name:        "undefined format",
+			req:         &library.GetBookCitationRequest{Id: bookID, Format: 42},
+			expectedErr: codes.InvalidArgument,
+		},
+		{
+			name:        "not found",
+			req:         &library.GetBookCitationRequest{Id: missingID},
+			expectedErr: codes.NotFound,
+		},
+		{
+			name:                "default format",
+			req:                 &library.GetBookCitationRequest{Id: bookID},
+			expectedContentType: "application/x-bibtex; charset=utf-8",
+			expectedData:        "bibtex",
+		},
+		{
+			name:                "format from request",
+			req:                 &library.GetBookCitationRequest{Id: bookID, Format: library.CitationFormat_CITATION_FORMAT_RIS},
+			accept:              "application/ld+json",
+			expectedContentType: "application/x-research-info-systems; charset=utf-8",
+			expectedData:        "ris",
+		},
+		{
+			name:                "format from accept",
+			req:                 &library.GetBookCitationRequest{Id: bookID},
+			accept:              "application/ld+json;q=0.5, application/vnd.citationstyles.csl+json",
+			expectedContentType: "application/vnd.citationstyles.csl+json; charset=utf-8",
+			expectedData:        "csl-json",
+		},
+		{
+			name:        "nothing acceptable",
+			req:         &library.GetBookCitationRequest{Id: bookID},
+			accept:      "text/html",
+			expectedErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+
+			if test.accept != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("grpcgateway-accept", test.accept))
+			}
+
+			actual, err := target.GetBookCitation(ctx, test.req)
+			require.Equal(t, test.expectedErr, status.Code(err))
+
+			if test.expectedErr == codes.OK {
+				require.Equal(t, test.expectedContentType, actual.GetContentType())
+				require.Equal(t, test.expectedData, string(actual.GetData()))
+			}
+		})
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	t.Parallel()
+
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(ActorHeader, "bob"))
+
+	_, ok := usecase.PrincipalFromContext(withActor(ctx))
+	require.False(t, ok)
+
+	authenticated := usecase.ContextWithPrincipal(ctx, entity.Principal{Subject: "alice", Method: entity.AuthMethodJWT})
+	require.Equal(t, authenticated, withActor(authenticated))
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) DeleteAuthor(ctx context.Context, req *library.DeleteAuthorRequest) (*library.DeleteAuthorResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	err := i.authorUseCase.DeleteAuthor(ctx, req.GetId())
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.DeleteAuthorResponse{}, nil
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) DeleteBook(ctx context.Context, req *library.DeleteBookRequest) (*library.DeleteBookResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	err := i.booksUseCase.DeleteBook(ctx, req.GetId())
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.DeleteBookResponse{}, nil
+}
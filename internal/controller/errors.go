@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/project/library/internal/entity"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain is the domain of the ErrorInfo reasons of the service.
+const ErrorDomain = "library"
+
+var errorCodes = map[entity.ErrorKind]codes.Code{
+	entity.KindInternal:           codes.Internal,
+	entity.KindInvalidArgument:    codes.InvalidArgument,
+	entity.KindNotFound:           codes.NotFound,
+	entity.KindAlreadyExists:      codes.AlreadyExists,
+	entity.KindFailedPrecondition: codes.FailedPrecondition,
+	entity.KindConflict:           codes.Aborted,
+	entity.KindUnavailable:        codes.Unavailable,
+}
+
+// convertErr maps the domain errors to a status with the details the error
+// carries: ResourceInfo for the entity, BadRequest for the field, ErrorInfo
+// with the reason and RetryInfo. Any other error is logged and reported as
+// Internal without its message, which may come from the database.
+func (i *implementation) convertErr(err error) error {
+	var domain *entity.Error
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case !errors.As(err, &domain):
+		i.logger.Error("unexpected error", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	code, ok := errorCodes[domain.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+
+	message := err.Error()
+
+	if code == codes.Internal || code == codes.Unavailable {
+		i.logger.Error("server error", zap.Error(err))
+		message = domain.Error()
+	}
+
+	var details []protoadapt.MessageV1
+
+	if domain.Resource != "" {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: domain.Resource,
+			ResourceName: domain.ID,
+			Description:  domain.Message,
+		})
+	}
+
+	if domain.Field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       domain.Field,
+				Description: message,
+				Reason:      domain.Reason,
+			}},
+		})
+	}
+
+	details = append(details, &errdetails.ErrorInfo{
+		Reason: domain.Reason,
+		Domain: ErrorDomain,
+	})
+
+	if domain.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(domain.RetryAfter)})
+	}
+
+	return withDetails(status.New(code, message), details...)
+}
+
+// withDetails falls back to the bare status if the details can't be
+// marshaled.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// validationError is implemented by the errors protoc-gen-validate
+// generates for every message.
+type validationError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiError is implemented by the errors ValidateAll returns.
+type multiError interface {
+	AllErrors() []error
+}
+
+// validationErr reports the violations ValidateAll found as BadRequest
+// field violations, the fields named as in the proto.
+func validationErr(err error) error {
+	violations := fieldViolations("", err)
+
+	if len(violations) == 0 {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return withDetails(status.New(codes.InvalidArgument, err.Error()),
+		&errdetails.BadRequest{FieldViolations: violations},
+		&errdetails.ErrorInfo{Reason: "INVALID_REQUEST", Domain: ErrorDomain},
+	)
+}
+
+func fieldViolations(prefix string, err error) []*errdetails.BadRequest_FieldViolation {
+	var multi multiError
+
+	if errors.As(err, &multi) {
+		var violations []*errdetails.BadRequest_FieldViolation
+
+		for _, single := range multi.AllErrors() {
+			violations = append(violations, fieldViolations(prefix, single)...)
+		}
+
+		return violations
+	}
+
+	var invalid validationError
+
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+
+	field := prefix + protoFieldName(invalid.Field())
+
+	// An embedded message reports its own violations as the cause.
+	if nested := fieldViolations(field+".", invalid.Cause()); len(nested) > 0 {
+		return nested
+	}
+
+	return []*errdetails.BadRequest_FieldViolation{{
+		Field:       field,
+		Description: invalid.Reason(),
+	}}
+}
+
+// protoFieldName turns the Go name protoc-gen-validate reports, such as
+// "SourceIds[0]", into the proto name "source_ids[0]".
+func protoFieldName(goName string) string {
+	var name strings.Builder
+
+	for i, r := range goName {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		name.WriteRune(r)
+	}
+
+	return name.String()
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"bufio"
+
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/entity"
+	"google.golang.org/grpc/status"
+)
+
+const exportChunkSize = 64 * 1024
+
+func (i *implementation) ExportCatalog(req *library.ExportCatalogRequest, out library.Library_ExportCatalogServer) error {
+	ctx := out.Context()
+
+	if err := req.ValidateAll(); err != nil {
+		return validationErr(err)
+	}
+
+	chunks := bufio.NewWriterSize(&exportStream{stream: out}, exportChunkSize)
+
+	err := i.booksUseCase.ExportCatalog(ctx, entity.ExportOptions{
+		Format: catalogFormats[req.GetFormat()],
+		Gzip:   req.GetGzip(),
+	}, chunks)
+
+	if err == nil {
+		err = chunks.Flush()
+	}
+
+	if err != nil {
+		// Errors of the stream already carry a status.
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		return i.convertErr(err)
+	}
+
+	return nil
+}
+
+// exportStream sends every write as one chunk.
+type exportStream struct {
+	stream library.Library_ExportCatalogServer
+}
+
+func (s *exportStream) Write(p []byte) (int, error) {
+	if err := s.stream.Send(&library.ExportCatalogResponse{Chunk: p}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
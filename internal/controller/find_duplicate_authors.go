@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) FindDuplicateAuthors(ctx context.Context, req *library.FindDuplicateAuthorsRequest) (*library.FindDuplicateAuthorsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	duplicates, err := i.authorUseCase.FindDuplicateAuthors(ctx, req.GetThreshold(), int(req.GetLimit()))
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	res := &library.FindDuplicateAuthorsResponse{
+		Duplicates: make([]*library.DuplicateAuthors, 0, len(duplicates)),
+	}
+
+	for _, duplicate := range duplicates {
+		res.Duplicates = append(res.Duplicates, &library.DuplicateAuthors{
+			First: &library.Author{
+				Id:   duplicate.First.ID,
+				Name: duplicate.First.Name,
+			},
+			Second: &library.Author{
+				Id:   duplicate.Second.ID,
+				Name: duplicate.Second.Name,
+			},
+			Similarity: duplicate.Similarity,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) GetAuthorHistory(ctx context.Context, req *library.GetAuthorHistoryRequest) (*library.GetAuthorHistoryResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	entries, err := i.authorUseCase.GetAuthorHistory(ctx, req.GetId(), int(req.GetLimit()))
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.GetAuthorHistoryResponse{
+		Entries: historyEntries(entries),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/citation"
+	"github.com/project/library/internal/entity"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var citationFormats = map[library.CitationFormat]entity.CitationFormat{
+	library.CitationFormat_CITATION_FORMAT_BIBTEX:   entity.CitationFormatBibTeX,
+	library.CitationFormat_CITATION_FORMAT_RIS:      entity.CitationFormatRIS,
+	library.CitationFormat_CITATION_FORMAT_CSL_JSON: entity.CitationFormatCSLJSON,
+	library.CitationFormat_CITATION_FORMAT_JSON_LD:  entity.CitationFormatJSONLD,
+}
+
+// acceptHeaders are the metadata keys the Accept header arrives under: as
+// set by gRPC clients and as forwarded by the gateway.
+var acceptHeaders = []string{"accept", "grpcgateway-accept"}
+
+// citationFormat takes the format from the request or, if it is
+// unspecified, negotiates it from the Accept metadata.
+func citationFormat(ctx context.Context, fromRequest library.CitationFormat) (entity.CitationFormat, bool) {
+	if format, ok := citationFormats[fromRequest]; ok {
+		return format, true
+	}
+
+	var accept []string
+
+	for _, key := range acceptHeaders {
+		accept = append(accept, metadata.ValueFromIncomingContext(ctx, key)...)
+	}
+
+	return citation.Negotiate(strings.Join(accept, ","))
+}
+
+func (i *implementation) GetBookCitation(ctx context.Context, req *library.GetBookCitationRequest) (*httpbody.HttpBody, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	format, ok := citationFormat(ctx, req.GetFormat())
+
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "no acceptable citation format")
+	}
+
+	data, err := i.booksUseCase.GetBookCitation(ctx, req.GetId(), format)
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &httpbody.HttpBody{
+		ContentType: citation.ContentType(format),
+		Data:        data,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) GetBookHistory(ctx context.Context, req *library.GetBookHistoryRequest) (*library.GetBookHistoryResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	entries, err := i.booksUseCase.GetBookHistory(ctx, req.GetId(), int(req.GetLimit()))
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.GetBookHistoryResponse{
+		Entries: historyEntries(entries),
+	}, nil
+}
@@ -2,44 +2,28 @@ package controller
 
 import (
 	"context"
-	"time"
 
 	"github.com/project/library/generated/api/library"
-	"go.opentelemetry.io/otel/trace"
-	"go.uber.org/zap"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/project/library/internal/entity"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func (i *implementation) GetBookInfo(ctx context.Context, req *library.GetBookInfoRequest) (ans *library.GetBookInfoResponse, erro error) {
-	with, err := durations.GetMetricWithLabelValues("GetBookInfo")
-	if err != nil {
-		i.logger.Error("Can't get duration metric", zap.Error(err))
+func (i *implementation) GetBookInfo(ctx context.Context, req *library.GetBookInfoRequest) (*library.GetBookInfoResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
 	}
 
-	var traceID = zap.String("traceID", trace.SpanFromContext(ctx).SpanContext().TraceID().String())
-	i.logger.Info("GetBookInfo called", traceID, zap.String("authorID", req.GetId()))
-	start := time.Now()
-
-	defer func() {
-		with.Observe(float64(time.Since(start).Milliseconds()))
-		bookResponseId := zap.String("authorResponseID", ans.GetBook().GetId())
-		if erro != nil {
-			i.logger.Error("GetBookInfo error", zap.Error(erro), traceID, bookResponseId)
-			trace.SpanFromContext(ctx).RecordError(erro)
-		} else {
-			i.logger.Info("GetBookInfo completed", traceID, bookResponseId)
-		}
-		trace.SpanFromContext(ctx).End()
-	}()
+	var (
+		book entity.Book
+		err  error
+	)
 
-	if err := req.ValidateAll(); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if req.GetAsOf() != nil {
+		book, err = i.booksUseCase.GetBookAsOf(ctx, req.GetId(), req.GetAsOf().AsTime())
+	} else {
+		book, err = i.booksUseCase.GetBook(ctx, req.GetId())
 	}
 
-	book, err := i.booksUseCase.GetBook(ctx, req.GetId())
-
 	if err != nil {
 		return nil, i.convertErr(err)
 	}
@@ -49,8 +33,10 @@ func (i *implementation) GetBookInfo(ctx context.Context, req *library.GetBookIn
 			Id:        book.ID,
 			Name:      book.Name,
 			AuthorId:  book.AuthorIDs,
+			Isbn:      book.ISBN,
+			Publisher: book.Publisher,
 			CreatedAt: timestamppb.New(book.CreatedAt),
-			UpdatedAt: timestamppb.New(book.CreatedAt),
+			UpdatedAt: timestamppb.New(book.UpdatedAt),
 		},
 	}, nil
 }
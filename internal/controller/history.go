@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/entity"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var historyOperations = map[entity.HistoryOperation]library.HistoryOperation{
+	entity.HistoryOperationCreate: library.HistoryOperation_HISTORY_OPERATION_CREATE,
+	entity.HistoryOperationUpdate: library.HistoryOperation_HISTORY_OPERATION_UPDATE,
+	entity.HistoryOperationDelete: library.HistoryOperation_HISTORY_OPERATION_DELETE,
+	entity.HistoryOperationLink:   library.HistoryOperation_HISTORY_OPERATION_LINK,
+	entity.HistoryOperationUnlink: library.HistoryOperation_HISTORY_OPERATION_UNLINK,
+}
+
+func historyEntries(entries []entity.HistoryEntry) []*library.HistoryEntry {
+	res := make([]*library.HistoryEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		changes := make([]*library.FieldChange, 0, len(entry.Changes))
+
+		for _, change := range entry.Changes {
+			changes = append(changes, &library.FieldChange{
+				Field:    change.Field,
+				OldValue: change.Old,
+				NewValue: change.New,
+			})
+		}
+
+		res = append(res, &library.HistoryEntry{
+			Operation: historyOperations[entry.Operation],
+			Actor:     entry.Actor,
+			ChangedAt: timestamppb.New(entry.ChangedAt),
+			Changes:   changes,
+		})
+	}
+
+	return res
+}
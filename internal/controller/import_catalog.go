@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"github.com/project/library/generated/api/library"
+	"github.com/project/library/internal/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var catalogFormats = map[library.CatalogFormat]entity.CatalogFormat{
+	library.CatalogFormat_CATALOG_FORMAT_CSV:     entity.CatalogFormatCSV,
+	library.CatalogFormat_CATALOG_FORMAT_JSONL:   entity.CatalogFormatJSONL,
+	library.CatalogFormat_CATALOG_FORMAT_MARC21:  entity.CatalogFormatMARC21,
+	library.CatalogFormat_CATALOG_FORMAT_MARCXML: entity.CatalogFormatMARCXML,
+}
+
+var errOptionsAfterChunk = status.Error(codes.InvalidArgument, "import options must come only in the first message")
+
+func (i *implementation) ImportCatalog(stream library.Library_ImportCatalogServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "import options expected: "+err.Error())
+	}
+
+	options := first.GetOptions()
+
+	if options == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the import options")
+	}
+
+	if err := options.ValidateAll(); err != nil {
+		return validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	report, err := i.booksUseCase.ImportCatalog(ctx, entity.ImportOptions{
+		Format:    catalogFormats[options.GetFormat()],
+		DryRun:    options.GetDryRun(),
+		ImportID:  options.GetImportId(),
+		BatchSize: int(options.GetBatchSize()),
+	}, &importStream{stream: stream})
+
+	if err != nil {
+		// Errors of the stream already carry a status.
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		return i.convertErr(err)
+	}
+
+	rowErrors := make([]*library.ImportRowError, 0, len(report.Errors))
+
+	for _, rowErr := range report.Errors {
+		rowErrors = append(rowErrors, &library.ImportRowError{
+			Row:     uint64(rowErr.Row),
+			Message: rowErr.Message,
+		})
+	}
+
+	return stream.SendAndClose(&library.ImportCatalogResponse{
+		Rows:           uint64(report.Rows),
+		BooksImported:  uint64(report.BooksImported),
+		AuthorsCreated: uint64(report.AuthorsCreated),
+		RowsSkipped:    uint64(report.RowsSkipped),
+		Errors:         rowErrors,
+		Checkpoint:     uint64(report.Checkpoint),
+		DryRun:         report.DryRun,
+	})
+}
+
+// importStream reads the file from the chunks following the options.
+type importStream struct {
+	stream library.Library_ImportCatalogServer
+	chunk  []byte
+}
+
+func (s *importStream) Read(p []byte) (int, error) {
+	for len(s.chunk) == 0 {
+		req, err := s.stream.Recv()
+
+		if err != nil {
+			return 0, err
+		}
+
+		if req.GetOptions() != nil {
+			return 0, errOptionsAfterChunk
+		}
+
+		s.chunk = req.GetChunk()
+	}
+
+	n := copy(p, s.chunk)
+	s.chunk = s.chunk[n:]
+
+	return n, nil
+}
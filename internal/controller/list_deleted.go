@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *implementation) ListDeleted(ctx context.Context, req *library.ListDeletedRequest) (*library.ListDeletedResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	books, err := i.booksUseCase.ListDeletedBooks(ctx, int(req.GetLimit()))
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	authors, err := i.authorUseCase.ListDeletedAuthors(ctx, int(req.GetLimit()))
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	res := &library.ListDeletedResponse{
+		Books:   make([]*library.Book, 0, len(books)),
+		Authors: make([]*library.Author, 0, len(authors)),
+	}
+
+	for _, book := range books {
+		res.Books = append(res.Books, &library.Book{
+			Id:        book.ID,
+			Name:      book.Name,
+			AuthorId:  book.AuthorIDs,
+			CreatedAt: timestamppb.New(book.CreatedAt),
+			UpdatedAt: timestamppb.New(book.UpdatedAt),
+			DeletedAt: timestamppb.New(book.DeletedAt),
+		})
+	}
+
+	for _, author := range authors {
+		res.Authors = append(res.Authors, &library.Author{
+			Id:        author.ID,
+			Name:      author.Name,
+			DeletedAt: timestamppb.New(author.DeletedAt),
+		})
+	}
+
+	return res, nil
+}
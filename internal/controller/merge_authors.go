@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) MergeAuthors(ctx context.Context, req *library.MergeAuthorsRequest) (*library.MergeAuthorsResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	author, err := i.authorUseCase.MergeAuthors(ctx, req.GetSourceIds(), req.GetTargetId())
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.MergeAuthorsResponse{
+		Author: &library.Author{
+			Id:   author.ID,
+			Name: author.Name,
+		},
+	}, nil
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *implementation) MergeBooks(ctx context.Context, req *library.MergeBooksRequest) (*library.MergeBooksResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	book, err := i.booksUseCase.MergeBooks(ctx, req.GetSourceIds(), req.GetTargetId())
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.MergeBooksResponse{
+		Book: &library.Book{
+			Id:        book.ID,
+			Name:      book.Name,
+			AuthorId:  book.AuthorIDs,
+			Isbn:      book.ISBN,
+			Publisher: book.Publisher,
+			CreatedAt: timestamppb.New(book.CreatedAt),
+			UpdatedAt: timestamppb.New(book.UpdatedAt),
+		},
+	}, nil
+}
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/project/library/generated/api/library"
+)
+
+func (i *implementation) RestoreAuthor(ctx context.Context, req *library.RestoreAuthorRequest) (*library.RestoreAuthorResponse, error) {
+	if err := req.ValidateAll(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	ctx = withActor(ctx)
+
+	err := i.authorUseCase.RestoreAuthor(ctx, req.GetId())
+
+	if err != nil {
+		return nil, i.convertErr(err)
+	}
+
+	return &library.RestoreAuthorResponse{}, nil
+}
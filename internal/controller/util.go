@@ -1,37 +1,42 @@
 package controller
 
 import (
-	"github.com/pkg/errors"
-	"github.com/project/library/internal/entity"
-	"github.com/prometheus/client_golang/prometheus"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"context"
+
+	"github.com/project/library/internal/usecase/library"
+	"google.golang.org/grpc/metadata"
 )
 
-func (i *implementation) convertErr(err error) error {
-	if err == nil {
-		return nil
-	}
-	switch {
-	case errors.Is(err, entity.ErrAuthorNotFound):
-		return status.Error(codes.NotFound, err.Error())
-	case errors.Is(err, entity.ErrBookNotFound):
-		return status.Error(codes.NotFound, err.Error())
-	default:
-		return status.Error(codes.Internal, err.Error())
+// IdempotencyKeyHeader is also forwarded by the gateway as gRPC metadata.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// withIdempotencyKey takes the key from the request field or, if it is empty,
+// from the Idempotency-Key metadata.
+func withIdempotencyKey(ctx context.Context, fromRequest string) context.Context {
+	key := fromRequest
+
+	if key == "" {
+		if values := metadata.ValueFromIncomingContext(ctx, IdempotencyKeyHeader); len(values) > 0 {
+			key = values[0]
+		}
 	}
+
+	return library.ContextWithIdempotencyKey(ctx, key)
 }
 
-var durations = prometheus.NewHistogramVec(
-	prometheus.HistogramOpts{
-		Name:    "library_durations_ms",
-		Help:    "Durations in ms",
-		Buckets: prometheus.DefBuckets,
-	},
-	[]string{
-		"lever",
-	})
-
-func init() {
-	prometheus.MustRegister(durations)
+// ActorHeader names who makes the change in the history. It is also
+// forwarded by the gateway as gRPC metadata. An authenticated caller can't
+// override its own subject with it.
+const ActorHeader = "X-Actor"
+
+func withActor(ctx context.Context) context.Context {
+	if _, ok := library.PrincipalFromContext(ctx); ok {
+		return ctx
+	}
+
+	if values := metadata.ValueFromIncomingContext(ctx, ActorHeader); len(values) > 0 {
+		return library.ContextWithActor(ctx, values[0])
+	}
+
+	return ctx
 }
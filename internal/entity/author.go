@@ -1,19 +1,43 @@
 package entity
 
 import (
+	"fmt"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 type Author struct {
-	ID        string
-	Name      string
+	ID   string
+	Name string
+	// Profile is loaded by GetAuthorInfo only.
+	Profile   AuthorProfile
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DeletedAt is zero unless the author is in the trash.
+	DeletedAt time.Time
+}
+
+// AuthorDuplicate is a pair of authors whose normalized names are similar.
+type AuthorDuplicate struct {
+	First      Author
+	Second     Author
+	Similarity float64
 }
 
 var (
-	ErrAuthorNotFound      = errors.New("author not found")
-	ErrAuthorAlreadyExists = errors.New("author already exists")
+	ErrAuthorNotFound      = NewError(KindNotFound, "AUTHOR_NOT_FOUND", "author not found").OfResource(ResourceAuthor)
+	ErrAuthorAlreadyExists = NewError(KindAlreadyExists, "AUTHOR_ALREADY_EXISTS", "author already exists").OfResource(ResourceAuthor)
 )
+
+// AuthorAlreadyExistsError carries the ID of the author that already has the
+// same normalized name. It matches ErrAuthorAlreadyExists with errors.Is.
+type AuthorAlreadyExistsError struct {
+	ExistingID string
+}
+
+func (e *AuthorAlreadyExistsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAuthorAlreadyExists, e.ExistingID)
+}
+
+func (e *AuthorAlreadyExistsError) Unwrap() error {
+	return ErrAuthorAlreadyExists.WithID(e.ExistingID)
+}
@@ -2,19 +2,22 @@ package entity
 
 import (
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 type Book struct {
 	ID        string
 	Name      string
 	AuthorIDs []string
+	// ISBN is normalized by NormalizeISBN, empty when unknown.
+	ISBN      string
+	Publisher string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DeletedAt is zero unless the book is in the trash.
+	DeletedAt time.Time
 }
 
 var (
-	ErrBookNotFound      = errors.New("book not found")
-	ErrBookAlreadyExists = errors.New("book already exists")
+	ErrBookNotFound      = NewError(KindNotFound, "BOOK_NOT_FOUND", "book not found").OfResource(ResourceBook)
+	ErrBookAlreadyExists = NewError(KindAlreadyExists, "BOOK_ALREADY_EXISTS", "book already exists").OfResource(ResourceBook)
 )
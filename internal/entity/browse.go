@@ -0,0 +1,11 @@
+package entity
+
+// BookFilter selects live books for browsing, newest first: the books of an
+// author, the books with Query in the title or in an author's name, or all
+// of them.
+type BookFilter struct {
+	AuthorID string
+	Query    string
+	Offset   int
+	Limit    int
+}
@@ -0,0 +1,52 @@
+package entity
+
+// CatalogFormat names a file format of catalog imports and exports.
+type CatalogFormat string
+
+const (
+	CatalogFormatCSV     CatalogFormat = "csv"
+	CatalogFormatJSONL   CatalogFormat = "jsonl"
+	CatalogFormatMARC21  CatalogFormat = "marc"
+	CatalogFormatMARCXML CatalogFormat = "marcxml"
+)
+
+// ImportOptions configure a catalog import.
+type ImportOptions struct {
+	Format CatalogFormat
+	// DryRun validates the records and resolves the authors without writing.
+	DryRun bool
+	// ImportID names the checkpoint of a resumable import; an import without
+	// an ID starts from the first row every time.
+	ImportID  string
+	BatchSize int
+}
+
+// ImportRowError describes a row that was not imported.
+type ImportRowError struct {
+	Row     int
+	Message string
+}
+
+// ImportReport sums up a catalog import. Rows counts every row read,
+// including the ones skipped because an earlier run already committed them.
+type ImportReport struct {
+	Rows           int
+	BooksImported  int
+	AuthorsCreated int
+	RowsSkipped    int
+	Errors         []ImportRowError
+	// Checkpoint is the last row committed under ImportID.
+	Checkpoint int
+	DryRun     bool
+}
+
+// ExportOptions configure a catalog export.
+type ExportOptions struct {
+	Format CatalogFormat
+	Gzip   bool
+}
+
+var (
+	ErrUnknownCatalogFormat     = NewError(KindInvalidArgument, "UNKNOWN_CATALOG_FORMAT", "unknown catalog format").WithField("format")
+	ErrImportCheckpointConflict = NewError(KindConflict, "IMPORT_CHECKPOINT_CONFLICT", "import checkpoint was moved by another run")
+)
@@ -0,0 +1,13 @@
+package entity
+
+// CitationFormat names a format books are cited in.
+type CitationFormat string
+
+const (
+	CitationFormatBibTeX  CitationFormat = "bibtex"
+	CitationFormatRIS     CitationFormat = "ris"
+	CitationFormatCSLJSON CitationFormat = "csl-json"
+	CitationFormatJSONLD  CitationFormat = "json-ld"
+)
+
+var ErrUnknownCitationFormat = NewError(KindInvalidArgument, "UNKNOWN_CITATION_FORMAT", "unknown citation format").WithField("format")
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrorKind tells what a caller can do about an error; the transport maps it
+// to a status code.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalidArgument
+	KindNotFound
+	KindAlreadyExists
+	KindFailedPrecondition
+	// KindConflict is a concurrent change; the whole operation may be
+	// retried.
+	KindConflict
+	// KindUnavailable is a transient failure; the call may be retried as is.
+	KindUnavailable
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInvalidArgument:
+		return "invalid argument"
+	case KindNotFound:
+		return "not found"
+	case KindAlreadyExists:
+		return "already exists"
+	case KindFailedPrecondition:
+		return "failed precondition"
+	case KindConflict:
+		return "conflict"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// The resource types errors refer to.
+const (
+	ResourceAuthor = "library.Author"
+	ResourceBook   = "library.Book"
+)
+
+// Error is an error of the domain. Reason is a stable UPPER_SNAKE_CASE
+// identifier clients can switch on. Resource and ID name the entity the
+// error is about, Field the input that is invalid and RetryAfter how long
+// to wait before retrying.
+//
+// Errors match by reason with errors.Is, so the copies WithID, WithField and
+// WithRetryAfter return still match the sentinel they were made from.
+type Error struct {
+	Kind       ErrorKind
+	Reason     string
+	Message    string
+	Resource   string
+	ID         string
+	Field      string
+	RetryAfter time.Duration
+}
+
+func NewError(kind ErrorKind, reason string, message string) *Error {
+	return &Error{Kind: kind, Reason: reason, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.ID != "" {
+		return fmt.Sprintf("%s: %s", e.Message, e.ID)
+	}
+
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.Reason == e.Reason
+}
+
+// OfResource returns a copy about the resource type.
+func (e *Error) OfResource(resource string) *Error {
+	result := *e
+	result.Resource = resource
+
+	return &result
+}
+
+// WithID returns a copy about the entity with the ID.
+func (e *Error) WithID(id string) *Error {
+	result := *e
+	result.ID = id
+
+	return &result
+}
+
+// WithField returns a copy about the input field, named as in the API.
+func (e *Error) WithField(field string) *Error {
+	result := *e
+	result.Field = field
+
+	return &result
+}
+
+func (e *Error) WithRetryAfter(retryAfter time.Duration) *Error {
+	result := *e
+	result.RetryAfter = retryAfter
+
+	return &result
+}
+
+// ErrStorageUnavailable wraps the errors of the storage that go away on a
+// retry: lost connections, serialization failures and deadlocks.
+var ErrStorageUnavailable = NewError(KindUnavailable, "STORAGE_UNAVAILABLE", "storage is temporarily unavailable").
+	WithRetryAfter(time.Second)
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorIs(t *testing.T) {
+	t.Parallel()
+
+	notFound := ErrBookNotFound.WithID("42")
+	require.ErrorIs(t, notFound, ErrBookNotFound)
+	require.NotErrorIs(t, notFound, ErrAuthorNotFound)
+	require.Equal(t, "book not found: 42", notFound.Error())
+	require.Empty(t, ErrBookNotFound.ID, "the sentinel is not changed")
+
+	wrapped := fmt.Errorf("get book: %w", ErrStorageUnavailable.WithRetryAfter(time.Minute))
+	require.ErrorIs(t, wrapped, ErrStorageUnavailable)
+
+	var domain *Error
+	require.ErrorAs(t, wrapped, &domain)
+	require.Equal(t, KindUnavailable, domain.Kind)
+	require.Equal(t, time.Minute, domain.RetryAfter)
+
+	require.NotErrorIs(t, errors.New("book not found"), ErrBookNotFound)
+}
+
+func TestAuthorAlreadyExistsError(t *testing.T) {
+	t.Parallel()
+
+	err := error(&AuthorAlreadyExistsError{ExistingID: "42"})
+	require.ErrorIs(t, err, ErrAuthorAlreadyExists)
+
+	var domain *Error
+	require.ErrorAs(t, err, &domain)
+	require.Equal(t, KindAlreadyExists, domain.Kind)
+	require.Equal(t, ResourceAuthor, domain.Resource)
+	require.Equal(t, "42", domain.ID)
+}
+
+func TestNormalizeProfileField(t *testing.T) {
+	t.Parallel()
+
+	_, err := AuthorProfile{BirthDate: "1828", DeathDate: "1910-13"}.Normalize("42")
+	require.ErrorIs(t, err, ErrInvalidDate)
+
+	var domain *Error
+	require.ErrorAs(t, err, &domain)
+	require.Equal(t, "profile.death_date", domain.Field)
+}
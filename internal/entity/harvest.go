@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// BookRecord is a book as catalog harvesters see it: with the names of its
+// authors and a datestamp, the time of its last change. A deleted record,
+// whether in the trash, purged or merged away, carries only the ID and the
+// time of deletion.
+type BookRecord struct {
+	Book
+	Authors   []string
+	Deleted   bool
+	Datestamp time.Time
+}
+
+// HarvestQuery selects the book records with From <= Datestamp < Until that
+// come after the cursor in the order of datestamps and IDs. Zero bounds and
+// a zero cursor are open.
+type HarvestQuery struct {
+	From  time.Time
+	Until time.Time
+	After HarvestCursor
+	Limit int
+}
+
+// HarvestCursor is the position of a record in a harvest.
+type HarvestCursor struct {
+	Datestamp time.Time
+	ID        string
+}
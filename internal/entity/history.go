@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type HistoryOperation string
+
+const (
+	HistoryOperationCreate HistoryOperation = "create"
+	HistoryOperationUpdate HistoryOperation = "update"
+	HistoryOperationDelete HistoryOperation = "delete"
+	// HistoryOperationLink and HistoryOperationUnlink add and remove an
+	// author of a book.
+	HistoryOperationLink   HistoryOperation = "link"
+	HistoryOperationUnlink HistoryOperation = "unlink"
+)
+
+// FieldChange holds JSON-encoded values; Old is empty for a new field and
+// New is empty for a removed one.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+type HistoryEntry struct {
+	Operation HistoryOperation
+	Actor     string
+	ChangedAt time.Time
+	Changes   []FieldChange
+}
@@ -0,0 +1,6 @@
+package entity
+
+var (
+	ErrIdempotencyKeyNotFound = NewError(KindNotFound, "IDEMPOTENCY_KEY_NOT_FOUND", "idempotency key not found")
+	ErrIdempotencyKeyReused   = NewError(KindFailedPrecondition, "IDEMPOTENCY_KEY_REUSED", "idempotency key reused with a different request").WithField("idempotency_key")
+)
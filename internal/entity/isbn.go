@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidISBN = NewError(KindInvalidArgument, "INVALID_ISBN", "invalid ISBN").WithField("isbn")
+
+// NormalizeISBN strips the hyphens and spaces from an ISBN-10 or ISBN-13 and
+// checks its check digit.
+func NormalizeISBN(isbn string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch {
+	case len(normalized) == 10 && isbn10Valid(normalized):
+		return normalized, nil
+	case len(normalized) == 13 && isbn13Valid(normalized):
+		return normalized, nil
+	default:
+		return "", errors.Wrapf(ErrInvalidISBN, "%q", isbn)
+	}
+}
+
+func isbn10Valid(isbn string) bool {
+	sum := 0
+
+	for i, r := range isbn {
+		var digit int
+
+		switch {
+		case r >= '0' && r <= '9':
+			digit = int(r - '0')
+		case r == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+
+		sum += (10 - i) * digit
+	}
+
+	return sum%11 == 0
+}
+
+func isbn13Valid(isbn string) bool {
+	sum := 0
+
+	for i, r := range isbn {
+		if r < '0' || r > '9' {
+			return false
+		}
+
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+
+		sum += weight * int(r-'0')
+	}
+
+	return sum%10 == 0
+}
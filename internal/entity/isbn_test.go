@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "isbn-13 with hyphens",
+			input:    "978-0-14-044793-4",
+			expected: "9780140447934",
+		},
+		{
+			name:     "isbn-10 with check digit x",
+			input:    "0-8044-2957-x",
+			expected: "080442957X",
+		},
+		{
+			name:  "wrong check digit",
+			input: "9780140447935",
+			err:   ErrInvalidISBN,
+		},
+		{
+			name:  "wrong length",
+			input: "12345",
+			err:   ErrInvalidISBN,
+		},
+		{
+			name:  "x inside isbn-10",
+			input: "08044X9570",
+			err:   ErrInvalidISBN,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := NormalizeISBN(test.input)
+			require.ErrorIs(t, err, test.err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
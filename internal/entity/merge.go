@@ -0,0 +1,13 @@
+package entity
+
+// Merge is the outbox payload of a merge: the sources were deleted and now
+// redirect to the target.
+type Merge struct {
+	SourceIDs []string `json:"source_ids"`
+	TargetID  string   `json:"target_id"`
+}
+
+var (
+	ErrMergeWithoutSources = NewError(KindInvalidArgument, "MERGE_WITHOUT_SOURCES", "merge has no sources").WithField("source_ids")
+	ErrMergeIntoSource     = NewError(KindInvalidArgument, "MERGE_INTO_SOURCE", "merge target is one of the sources").WithField("target_id")
+)
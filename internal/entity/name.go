@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var folder = cases.Fold()
+
+// NormalizeAuthorName returns the key two names are compared by: NFKC
+// normalized, case folded and with whitespace runs collapsed to one space.
+func NormalizeAuthorName(name string) string {
+	return strings.Join(strings.Fields(folder.String(norm.NFKC.String(name))), " ")
+}
+
+// AuthorNamePattern is the rule author names are registered by: words of
+// letters, marks and digits in any script, joined by single spaces,
+// hyphens, apostrophes or initials' periods. It is repeated in
+// library.proto, the two must match.
+const AuthorNamePattern = `^[\p{L}\p{N}][\p{L}\p{M}\p{N}]*(?:(?:\. ?| |[-'’])[\p{L}\p{N}][\p{L}\p{M}\p{N}]*)*\.?$`
+
+// CanonicalAuthorName is the form author names are stored in: NFC
+// normalized, so a name typed with combining marks and the same name typed
+// with precomposed letters are saved the same.
+func CanonicalAuthorName(name string) string {
+	return norm.NFC.String(name)
+}
+
+// AuthorSortKey orders authors by their sort name in ASCII:
+// "marquez, gabriel garcia".
+func AuthorSortKey(name string) string {
+	return strings.Join(strings.Fields(Transliterate(SplitPersonName(name).SortName())), " ")
+}
+
+// AuthorSearchKey is the name in lowercase ASCII with punctuation turned
+// into spaces, so "García Márquez", "garcia marquez" and "Garcia-Marquez"
+// find each other, as do Достоевский and dostoevsky.
+func AuthorSearchKey(name string) string {
+	return strings.Join(strings.FieldsFunc(Transliterate(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// NameSimilarity compares two normalized names the way pg_trgm similarity()
+// does: the share of trigrams the names have in common.
+func NameSimilarity(a string, b string) float64 {
+	first, second := trigrams(a), trigrams(b)
+
+	if len(first) == 0 && len(second) == 0 {
+		return 0
+	}
+
+	common := 0
+
+	for trigram := range first {
+		if _, ok := second[trigram]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(first)+len(second)-common)
+}
+
+func trigrams(s string) map[string]struct{} {
+	result := make(map[string]struct{})
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return result
+}
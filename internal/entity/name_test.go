@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAuthorName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "case and whitespace",
+			input:    "  Leo \t TOLSTOY ",
+			expected: "leo tolstoy",
+		},
+		{
+			name:     "composed and decomposed forms",
+			input:    "Gabriel Garci\u0301a Ma\u0301rquez",
+			expected: "gabriel garc\u00eda m\u00e1rquez",
+		},
+		{
+			name:     "compatibility forms",
+			input:    "Ｈｅｌｌｏ",
+			expected: "hello",
+		},
+		{
+			name:     "case folding",
+			input:    "STRASSE Straße",
+			expected: "strasse strasse",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expected, NormalizeAuthorName(test.input))
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 1.0, NameSimilarity("leo tolstoy", "leo tolstoy"), 1e-9)
+	require.InDelta(t, 0.0, NameSimilarity("", ""), 1e-9)
+	require.InDelta(t, 0.0, NameSimilarity("abc", "xyz"), 1e-9)
+	// pg_trgm: SELECT similarity('word', 'two words') = 0.36363637
+	require.InDelta(t, 0.363636, NameSimilarity("word", "two words"), 1e-6)
+	require.Greater(t, NameSimilarity("leo tolstoy", "lev tolstoy"), NameSimilarity("leo tolstoy", "anton chekhov"))
+}
+
+func TestSplitPersonName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want PersonName
+	}{
+		{"Leo Tolstoy", PersonName{Given: "Leo", Family: "Tolstoy"}},
+		{"Homer", PersonName{Family: "Homer"}},
+		{"", PersonName{}},
+		{"Ludwig van Beethoven", PersonName{Given: "Ludwig", Particle: "van", Family: "Beethoven"}},
+		{"Johann Wolfgang von Goethe", PersonName{Given: "Johann Wolfgang", Particle: "von", Family: "Goethe"}},
+		{"Charles de la Vallée", PersonName{Given: "Charles", Particle: "de la", Family: "Vallée"}},
+		{"van Gogh", PersonName{Particle: "van", Family: "Gogh"}},
+		{"Martin Luther King Jr.", PersonName{Given: "Martin Luther", Family: "King", Suffix: "Jr."}},
+		{"Sammy Davis, Jr.", PersonName{Given: "Sammy", Family: "Davis", Suffix: "Jr."}},
+		{"Henry Ford II", PersonName{Given: "Henry", Family: "Ford", Suffix: "II"}},
+		{"Malcolm Jr", PersonName{Given: "Malcolm", Family: "Jr"}},
+		{"Simon & Garfunkel", PersonName{Family: "Simon & Garfunkel"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.want, SplitPersonName(test.name))
+		})
+	}
+}
+
+func TestAuthorNamePattern(t *testing.T) {
+	t.Parallel()
+
+	pattern := regexp.MustCompile(AuthorNamePattern)
+
+	valid := []string{
+		"Leo Tolstoy", "Фёдор Достоевский", "Gabriel García Márquez", "Gabriel Garci\u0301a Ma\u0301rquez",
+		"Flann O'Brien", "Flann O’Brien", "Jean-Paul Sartre", "J. R. R. Tolkien", "J.R.R. Tolkien",
+		"Martin Luther King Jr.", "村上春樹", "Νίκος Καζαντζάκης", "Author 2",
+	}
+
+	for _, name := range valid {
+		require.True(t, pattern.MatchString(name), name)
+	}
+
+	invalid := []string{"", " Leo", "Leo  Tolstoy", "Leo Tolstoy ", "Leo_Tolstoy", "-Leo", "O''Brien", "Leo\nTolstoy", "\u0301Leo"}
+
+	for _, name := range invalid {
+		require.False(t, pattern.MatchString(name), name)
+	}
+}
+
+func TestCanonicalAuthorName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Gabriel Garc\u00eda M\u00e1rquez", CanonicalAuthorName("Gabriel Garci\u0301a Ma\u0301rquez"))
+}
+
+func TestAuthorKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		sortName  string
+		sortKey   string
+		searchKey string
+	}{
+		{"Gabriel García Márquez", "Márquez, Gabriel García", "marquez, gabriel garcia", "gabriel garcia marquez"},
+		{"Фёдор Достоевский", "Достоевский, Фёдор", "dostoevsky, fyodor", "fyodor dostoevsky"},
+		{"Лев Толстой", "Толстой, Лев", "tolstoy, lev", "lev tolstoy"},
+		{"Ludwig van Beethoven", "Beethoven, Ludwig van", "beethoven, ludwig van", "ludwig van beethoven"},
+		{"Martin Luther King Jr.", "King, Martin Luther, Jr.", "king, martin luther, jr.", "martin luther king jr"},
+		{"Flann O’Brien", "O’Brien, Flann", "o'brien, flann", "flann o brien"},
+		{"Jean-Paul Sartre", "Sartre, Jean-Paul", "sartre, jean-paul", "jean paul sartre"},
+		{"Νίκος Καζαντζάκης", "Καζαντζάκης, Νίκος", "kazantzakis, nikos", "nikos kazantzakis"},
+		{"Søren Kierkegaard", "Kierkegaard, Søren", "kierkegaard, soren", "soren kierkegaard"},
+		{"Homer", "Homer", "homer", "homer"},
+		{"村上春樹", "村上春樹", "村上春樹", "村上春樹"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.sortName, SplitPersonName(test.name).SortName())
+			require.Equal(t, test.sortKey, AuthorSortKey(test.name))
+			require.Equal(t, test.searchKey, AuthorSearchKey(test.name))
+		})
+	}
+}
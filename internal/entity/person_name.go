@@ -0,0 +1,101 @@
+package entity
+
+import "strings"
+
+// PersonName is a personal name split into the parts citations and sort
+// names keep apart: "Ludwig van Beethoven" has the given name Ludwig, the
+// particle van and the family name Beethoven.
+type PersonName struct {
+	Given    string
+	Particle string
+	Family   string
+	Suffix   string
+}
+
+var particles = map[string]struct{}{
+	"da": {}, "de": {}, "del": {}, "della": {}, "der": {}, "di": {}, "du": {},
+	"la": {}, "le": {}, "ten": {}, "ter": {}, "van": {}, "von": {}, "zu": {},
+}
+
+var suffixes = map[string]struct{}{
+	"jr": {}, "jr.": {}, "sr": {}, "sr.": {}, "ii": {}, "iii": {}, "iv": {},
+}
+
+// SplitPersonName splits a name written given name first. The last word is
+// the family name, the lowercase particles before it go with it and a
+// trailing Jr., Sr. or roman numeral is the suffix. A single word is a
+// family name, and so is a whole name joining people with "and" or "&", as
+// a group's is.
+func SplitPersonName(name string) PersonName {
+	words := strings.Fields(name)
+
+	var result PersonName
+
+	for _, word := range words {
+		if word == "&" || strings.EqualFold(word, "and") {
+			result.Family = strings.Join(words, " ")
+
+			return result
+		}
+	}
+
+	if len(words) > 2 {
+		if _, ok := suffixes[strings.ToLower(strings.TrimSuffix(words[len(words)-1], ","))]; ok {
+			result.Suffix = words[len(words)-1]
+			words = words[:len(words)-1]
+			words[len(words)-1] = strings.TrimSuffix(words[len(words)-1], ",")
+		}
+	}
+
+	if len(words) == 0 {
+		return result
+	}
+
+	result.Family = words[len(words)-1]
+	words = words[:len(words)-1]
+	start := len(words)
+
+	for start > 0 && isParticle(words[start-1]) {
+		start--
+	}
+
+	result.Particle = strings.Join(words[start:], " ")
+	result.Given = strings.Join(words[:start], " ")
+
+	return result
+}
+
+func isParticle(word string) bool {
+	_, ok := particles[word]
+
+	return ok
+}
+
+// FamilyWithParticle is the family name as sorted and cited inverted,
+// "van Beethoven".
+func (n PersonName) FamilyWithParticle() string {
+	if n.Particle == "" {
+		return n.Family
+	}
+
+	return n.Particle + " " + n.Family
+}
+
+// SortName is the name inverted the way catalogs file it, family name
+// first: "Márquez, Gabriel García", "Beethoven, Ludwig van",
+// "King, Martin Luther, Jr.".
+func (n PersonName) SortName() string {
+	given := strings.TrimSpace(n.Given + " " + n.Particle)
+
+	parts := []string{n.Family}
+
+	if given != "" {
+		parts = append(parts, given)
+	}
+
+	if n.Suffix != "" {
+		parts = append(parts, n.Suffix)
+	}
+
+	return strings.Join(parts, ", ")
+}
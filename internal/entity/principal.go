@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AuthMethod string
+
+const (
+	AuthMethodJWT    AuthMethod = "jwt"
+	AuthMethodAPIKey AuthMethod = "api_key"
+)
+
+// Role grants access to RPCs. The roles are ordered: a librarian may do
+// whatever a reader may and an admin whatever a librarian may.
+type Role string
+
+const (
+	RoleReader    Role = "reader"
+	RoleLibrarian Role = "librarian"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleReader:    1,
+	RoleLibrarian: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole accepts the role names in any case.
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRole, name)
+	}
+
+	return role, nil
+}
+
+// Includes reports whether the role may do whatever required may.
+func (r Role) Includes(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	Subject string
+	Method  AuthMethod
+	Roles   []Role
+}
+
+// HasRole reports whether one of the roles of the principal includes
+// required.
+func (p Principal) HasRole(required Role) bool {
+	for _, role := range p.Roles {
+		if role.Includes(required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// APIKey is stored by the hash of the key; the key itself is shown once,
+// when it is created.
+type APIKey struct {
+	ID        string
+	Name      string
+	Subject   string
+	Roles     []Role
+	CreatedAt time.Time
+	// ExpiresAt and RevokedAt are zero for a key that never expires and a
+	// key that is not revoked.
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// Active reports whether the key may be used at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt))
+}
+
+var (
+	ErrAPIKeyNotFound = NewError(KindNotFound, "API_KEY_NOT_FOUND", "api key not found")
+	ErrUnknownRole    = NewError(KindInvalidArgument, "UNKNOWN_ROLE", "unknown role")
+)
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	t.Parallel()
+
+	role, err := ParseRole(" Librarian ")
+	require.NoError(t, err)
+	require.Equal(t, RoleLibrarian, role)
+
+	_, err = ParseRole("owner")
+	require.ErrorIs(t, err, ErrUnknownRole)
+}
+
+func TestPrincipalHasRole(t *testing.T) {
+	t.Parallel()
+
+	librarian := Principal{Subject: "alice", Roles: []Role{RoleReader, RoleLibrarian}}
+	require.True(t, librarian.HasRole(RoleReader))
+	require.True(t, librarian.HasRole(RoleLibrarian))
+	require.False(t, librarian.HasRole(RoleAdmin))
+
+	require.True(t, Principal{Roles: []Role{RoleAdmin}}.HasRole(RoleLibrarian))
+	require.False(t, Principal{Roles: []Role{"owner"}}.HasRole(RoleReader))
+	require.False(t, Principal{}.HasRole(RoleReader))
+}
@@ -0,0 +1,193 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuthorProfile is what is known about an author beside the name.
+type AuthorProfile struct {
+	Biography string
+	// BirthDate and DeathDate are ISO 8601 dates, possibly reduced to the
+	// month or the year: "1828-09-09", "1828-09", "1828".
+	BirthDate string
+	DeathDate string
+	// Nationality is an ISO 3166-1 alpha-2 country code.
+	Nationality string
+	// AlternateNames are other spellings, translations and transliterations
+	// of the name. The author search finds them.
+	AlternateNames []string
+	// PseudonymOf is the ID of the author writing under this name.
+	PseudonymOf string
+	// Pseudonyms are the IDs of the authors that are pseudonyms of this one.
+	// GetAuthorInfo fills them in, updates ignore them.
+	Pseudonyms []string
+	// VIAF, ISNI and WikidataID identify the author in the Virtual
+	// International Authority File, the International Standard Name
+	// Identifier register and Wikidata.
+	VIAF       string
+	ISNI       string
+	WikidataID string
+}
+
+var (
+	ErrInvalidDate          = NewError(KindInvalidArgument, "INVALID_DATE", "invalid date")
+	ErrDeathBeforeBirth     = NewError(KindInvalidArgument, "DEATH_BEFORE_BIRTH", "death date before birth date").WithField("profile.death_date")
+	ErrInvalidNationality   = NewError(KindInvalidArgument, "INVALID_NATIONALITY", "invalid nationality").WithField("profile.nationality")
+	ErrInvalidAlternateName = NewError(KindInvalidArgument, "INVALID_ALTERNATE_NAME", "invalid alternate name").WithField("profile.alternate_names")
+	ErrPseudonymOfSelf      = NewError(KindInvalidArgument, "PSEUDONYM_OF_SELF", "an author can't be a pseudonym of itself").WithField("profile.pseudonym_of")
+	ErrPseudonymOfNotFound  = NewError(KindInvalidArgument, "PSEUDONYM_OF_NOT_FOUND", "pseudonym_of author not found").WithField("profile.pseudonym_of")
+	ErrInvalidVIAF          = NewError(KindInvalidArgument, "INVALID_VIAF", "invalid VIAF ID").WithField("profile.viaf")
+	ErrInvalidISNI          = NewError(KindInvalidArgument, "INVALID_ISNI", "invalid ISNI").WithField("profile.isni")
+	ErrInvalidWikidataID    = NewError(KindInvalidArgument, "INVALID_WIKIDATA_ID", "invalid Wikidata ID").WithField("profile.wikidata_id")
+)
+
+var (
+	nationalityPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	viafPattern        = regexp.MustCompile(`^[1-9][0-9]{0,21}$`)
+	wikidataIDPattern  = regexp.MustCompile(`^Q[1-9][0-9]*$`)
+	authorNamePattern  = regexp.MustCompile(AuthorNamePattern)
+)
+
+// dateLayouts are the layouts of the reduced precision dates by length.
+var dateLayouts = map[int]string{
+	len("2006"):       "2006",
+	len("2006-01"):    "2006-01",
+	len("2006-01-02"): "2006-01-02",
+}
+
+// Normalize checks the profile of the author with the ID and returns it in
+// the form it is stored in: codes uppercased, identifiers stripped of
+// spaces and hyphens and alternate names NFC normalized without repeats.
+func (p AuthorProfile) Normalize(authorID string) (AuthorProfile, error) {
+	p.Biography = strings.TrimSpace(p.Biography)
+	p.Pseudonyms = nil
+
+	dates := []struct{ field, date string }{
+		{"profile.birth_date", p.BirthDate},
+		{"profile.death_date", p.DeathDate},
+	}
+
+	for _, d := range dates {
+		if err := validateDate(d.date); err != nil {
+			return AuthorProfile{}, errors.Wrapf(ErrInvalidDate.WithField(d.field), "%q", d.date)
+		}
+	}
+
+	if p.BirthDate != "" && p.DeathDate != "" {
+		n := min(len(p.BirthDate), len(p.DeathDate))
+
+		if p.DeathDate[:n] < p.BirthDate[:n] {
+			return AuthorProfile{}, errors.Wrapf(ErrDeathBeforeBirth, "%s, %s", p.BirthDate, p.DeathDate)
+		}
+	}
+
+	if p.Nationality = strings.ToUpper(p.Nationality); p.Nationality != "" && !nationalityPattern.MatchString(p.Nationality) {
+		return AuthorProfile{}, errors.Wrapf(ErrInvalidNationality, "%q", p.Nationality)
+	}
+
+	alternateNames := make([]string, 0, len(p.AlternateNames))
+
+	for _, name := range p.AlternateNames {
+		name = CanonicalAuthorName(name)
+
+		if !authorNamePattern.MatchString(name) {
+			return AuthorProfile{}, errors.Wrapf(ErrInvalidAlternateName, "%q", name)
+		}
+
+		if !containsFold(alternateNames, name) {
+			alternateNames = append(alternateNames, name)
+		}
+	}
+
+	p.AlternateNames = alternateNames
+
+	if p.PseudonymOf != "" && p.PseudonymOf == authorID {
+		return AuthorProfile{}, ErrPseudonymOfSelf
+	}
+
+	var err error
+
+	if p.VIAF, err = normalizeIdentifier(p.VIAF, viafPattern.MatchString, ErrInvalidVIAF); err != nil {
+		return AuthorProfile{}, err
+	}
+
+	if p.ISNI, err = normalizeIdentifier(p.ISNI, isniValid, ErrInvalidISNI); err != nil {
+		return AuthorProfile{}, err
+	}
+
+	if p.WikidataID, err = normalizeIdentifier(p.WikidataID, wikidataIDPattern.MatchString, ErrInvalidWikidataID); err != nil {
+		return AuthorProfile{}, err
+	}
+
+	return p, nil
+}
+
+func validateDate(date string) error {
+	if date == "" {
+		return nil
+	}
+
+	layout, ok := dateLayouts[len(date)]
+
+	if !ok {
+		return ErrInvalidDate
+	}
+
+	if _, err := time.Parse(layout, date); err != nil {
+		return ErrInvalidDate
+	}
+
+	return nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, existing := range names {
+		if NormalizeAuthorName(existing) == NormalizeAuthorName(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeIdentifier drops spaces and hyphens and uppercases the
+// identifier before checking it.
+func normalizeIdentifier(identifier string, valid func(string) bool, invalid error) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(identifier))
+
+	if normalized != "" && !valid(normalized) {
+		return "", errors.Wrapf(invalid, "%q", identifier)
+	}
+
+	return normalized, nil
+}
+
+// isniValid checks the length and the ISO 7064 MOD 11-2 check character
+// of an ISNI.
+func isniValid(isni string) bool {
+	if len(isni) != 16 {
+		return false
+	}
+
+	total := 0
+
+	for _, r := range isni[:15] {
+		if r < '0' || r > '9' {
+			return false
+		}
+
+		total = (total + int(r-'0')) * 2
+	}
+
+	check := (12 - total%11) % 11
+
+	if check == 10 {
+		return isni[15] == 'X'
+	}
+
+	return int(isni[15]-'0') == check
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorProfileNormalize(t *testing.T) {
+	t.Parallel()
+
+	const authorID = "0191c3a5-7a1c-7b5e-9d3e-000000000001"
+
+	tests := []struct {
+		name     string
+		profile  AuthorProfile
+		expected AuthorProfile
+		err      error
+	}{
+		{
+			name: "normalized",
+			profile: AuthorProfile{
+				Biography:      "  Russian writer.\n",
+				BirthDate:      "1821-11-11",
+				DeathDate:      "1881-02",
+				Nationality:    "ru",
+				AlternateNames: []string{"Фёдор Достоевский", "Fedor Dostoevskii", "fedor dostoevskii"},
+				Pseudonyms:     []string{"0191c3a5-7a1c-7b5e-9d3e-000000000002"},
+				VIAF:           "104722723",
+				ISNI:           "0000 0001 2281 955x",
+				WikidataID:     "q991",
+			},
+			expected: AuthorProfile{
+				Biography:      "Russian writer.",
+				BirthDate:      "1821-11-11",
+				DeathDate:      "1881-02",
+				Nationality:    "RU",
+				AlternateNames: []string{"Фёдор Достоевский", "Fedor Dostoevskii"},
+				VIAF:           "104722723",
+				ISNI:           "000000012281955X",
+				WikidataID:     "Q991",
+			},
+		},
+		{
+			name:     "empty",
+			expected: AuthorProfile{AlternateNames: []string{}},
+		},
+		{
+			name:     "same year",
+			profile:  AuthorProfile{BirthDate: "1900-05", DeathDate: "1900"},
+			expected: AuthorProfile{BirthDate: "1900-05", DeathDate: "1900", AlternateNames: []string{}},
+		},
+		{name: "invalid date", profile: AuthorProfile{BirthDate: "1821-02-30"}, err: ErrInvalidDate},
+		{name: "invalid date layout", profile: AuthorProfile{DeathDate: "11.02.1881"}, err: ErrInvalidDate},
+		{name: "death before birth", profile: AuthorProfile{BirthDate: "1881", DeathDate: "1821-11"}, err: ErrDeathBeforeBirth},
+		{name: "invalid nationality", profile: AuthorProfile{Nationality: "RUS"}, err: ErrInvalidNationality},
+		{name: "invalid alternate name", profile: AuthorProfile{AlternateNames: []string{"Fedor  D"}}, err: ErrInvalidAlternateName},
+		{name: "pseudonym of self", profile: AuthorProfile{PseudonymOf: authorID}, err: ErrPseudonymOfSelf},
+		{name: "invalid VIAF", profile: AuthorProfile{VIAF: "0104722723"}, err: ErrInvalidVIAF},
+		{name: "invalid ISNI check character", profile: AuthorProfile{ISNI: "0000000122819550"}, err: ErrInvalidISNI},
+		{name: "invalid ISNI length", profile: AuthorProfile{ISNI: "00000001228195"}, err: ErrInvalidISNI},
+		{name: "invalid Wikidata ID", profile: AuthorProfile{WikidataID: "P31"}, err: ErrInvalidWikidataID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := test.profile.Normalize(authorID)
+			require.ErrorIs(t, err, test.err)
+
+			if test.err == nil {
+				require.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
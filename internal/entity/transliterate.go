@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations spell the letters that don't decompose to a Latin base
+// letter and marks in ASCII, after BGN/PCGN for Cyrillic and ELOT 743 for
+// Greek.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ł': "l", 'ı': "i", 'ħ': "h", 'ŋ': "ng", '’': "'",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Transliterate lowercases text and spells it in ASCII where it knows how:
+// marks are dropped from Latin letters and Cyrillic and Greek are
+// romanized. Scripts it has no table for are kept as they are.
+func Transliterate(text string) string {
+	runes := []rune(norm.NFC.String(strings.ToLower(text)))
+
+	var result strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// "-ий" and "-ый" end names as "-y": Достоевский is Dostoevsky.
+		if (r == 'и' || r == 'ы') && i+1 < len(runes) && runes[i+1] == 'й' &&
+			(i+2 == len(runes) || !unicode.IsLetter(runes[i+2])) {
+			result.WriteString("y")
+			i++
+
+			continue
+		}
+
+		if ascii, ok := transliterations[r]; ok {
+			result.WriteString(ascii)
+			continue
+		}
+
+		for _, d := range norm.NFKD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+
+			if ascii, ok := transliterations[d]; ok {
+				result.WriteString(ascii)
+			} else {
+				result.WriteRune(d)
+			}
+		}
+	}
+
+	return result.String()
+}
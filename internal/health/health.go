@@ -0,0 +1,219 @@
+// Package health runs the readiness checks of the service in the background
+// and reports their result through the gRPC health service and the /healthz
+// and /readyz endpoints of the gateway.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// LivenessPath answers as long as the process serves HTTP,
+	// ReadinessPath only while every check passes.
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+
+	DefaultTimeout = 2 * time.Second
+)
+
+// Check returns nil while the dependency it checks is usable.
+type Check func(ctx context.Context) error
+
+type (
+	Option func(options *options)
+
+	options struct {
+		checks   []namedCheck
+		services []string
+		timeout  time.Duration
+	}
+
+	namedCheck struct {
+		name  string
+		check Check
+	}
+)
+
+// WithCheck adds a check reported under the name.
+func WithCheck(name string, check Check) Option {
+	return func(options *options) {
+		options.checks = append(options.checks, namedCheck{name: name, check: check})
+	}
+}
+
+// WithService reports the status under the gRPC service name too, not only
+// under the empty name standing for the whole server.
+func WithService(name string) Option {
+	return func(options *options) {
+		options.services = append(options.services, name)
+	}
+}
+
+// WithTimeout bounds every run of a check.
+func WithTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		if timeout > 0 {
+			options.timeout = timeout
+		}
+	}
+}
+
+// Report is the result of the last run of the checks: the failures by the
+// name of the check. The service isn't ready before the first run and
+// after Shutdown.
+type Report struct {
+	Ready    bool              `json:"ready"`
+	Draining bool              `json:"draining,omitempty"`
+	Checks   map[string]string `json:"checks"`
+}
+
+type Checker struct {
+	logger *zap.Logger
+	server *grpchealth.Server
+	options
+
+	mu     sync.RWMutex
+	report Report
+}
+
+func New(logger *zap.Logger, opts ...Option) *Checker {
+	c := &Checker{
+		logger:  logger,
+		server:  grpchealth.NewServer(),
+		options: options{timeout: DefaultTimeout},
+		report:  Report{Checks: map[string]string{}},
+	}
+
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+
+	c.setServing(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+// Register adds grpc.health.v1.Health to the server.
+func (c *Checker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, c.server)
+}
+
+// Start runs the checks now and then every interval until the context is
+// done.
+func (c *Checker) Start(ctx context.Context, interval time.Duration) {
+	c.Run(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run runs every check once and publishes the report.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Ready: true, Checks: make(map[string]string, len(c.checks))}
+
+	for _, check := range c.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := check.check(checkCtx)
+		cancel()
+
+		if err != nil {
+			report.Ready = false
+			report.Checks[check.name] = err.Error()
+			continue
+		}
+
+		report.Checks[check.name] = "ok"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.report.Draining {
+		return c.report
+	}
+
+	if report.Ready != c.report.Ready {
+		c.logger.Info("readiness changed", zap.Bool("ready", report.Ready), zap.Any("checks", report.Checks))
+	}
+
+	c.report = report
+
+	if report.Ready {
+		c.setServing(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		c.setServing(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return report
+}
+
+// Report is the result of the last run.
+func (c *Checker) Report() Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.report
+}
+
+// Shutdown reports NOT_SERVING from now on, so that load balancers stop
+// sending calls while the servers drain.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.report.Ready = false
+	c.report.Draining = true
+	c.server.Shutdown()
+}
+
+func (c *Checker) setServing(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.server.SetServingStatus("", status)
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+// Liveness serves LivenessPath.
+func (c *Checker) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// Readiness serves ReadinessPath: the last report, with 503 Service
+// Unavailable unless it is ready.
+func (c *Checker) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		report := c.Report()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const service = "library.Library"
+
+func servingStatus(t *testing.T, c *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	response, err := c.server.Check(t.Context(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return response.GetStatus()
+}
+
+func readiness(t *testing.T, c *Checker) (int, Report) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	c.Readiness().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+
+	return recorder.Code, report
+}
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	var databaseErr error
+
+	c := New(zaptest.NewLogger(t),
+		WithService(service),
+		WithCheck("database", func(context.Context) error { return databaseErr }),
+		WithCheck("migrations", func(context.Context) error { return nil }),
+	)
+
+	code, report := readiness(t, c)
+	require.Equal(t, http.StatusServiceUnavailable, code, "not ready before the first run")
+	require.False(t, report.Ready)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, c, ""))
+
+	c.Run(t.Context())
+
+	code, report = readiness(t, c)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, Report{Ready: true, Checks: map[string]string{"database": "ok", "migrations": "ok"}}, report)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, c, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, c, service))
+
+	databaseErr = errors.New("connection refused")
+	c.Run(t.Context())
+
+	code, report = readiness(t, c)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "connection refused", report.Checks["database"])
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, c, service))
+
+	databaseErr = nil
+	c.Run(t.Context())
+	c.Shutdown()
+	c.Run(t.Context())
+
+	code, report = readiness(t, c)
+	require.Equal(t, http.StatusServiceUnavailable, code, "not ready while draining")
+	require.True(t, report.Draining)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, c, ""))
+
+	recorder := httptest.NewRecorder()
+	c.Liveness().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code, "alive while draining")
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	c := New(zaptest.NewLogger(t),
+		WithTimeout(10*time.Millisecond),
+		WithCheck("outbox", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+
+	report := c.Run(t.Context())
+	require.False(t, report.Ready)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["outbox"])
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	runs := make(chan struct{}, 10)
+
+	c := New(zaptest.NewLogger(t), WithCheck("database", func(context.Context) error {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+
+		return nil
+	}))
+	c.Start(ctx, 5*time.Millisecond)
+
+	require.True(t, c.Report().Ready, "the first run completes before Start returns")
+
+	<-runs
+	<-runs
+}
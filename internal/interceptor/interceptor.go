@@ -0,0 +1,18 @@
+// Package interceptor holds the gRPC server interceptors every call goes
+// through regardless of the method: access logging, metrics and panic
+// recovery. The handlers contain only the business logic.
+package interceptor
+
+import "strings"
+
+// splitMethod splits "/library.Library/AddBook" into the service and the
+// method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "unknown", fullMethod
+}
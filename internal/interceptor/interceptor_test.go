@@ -0,0 +1,197 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const addBook = "/library.Library/AddBook"
+
+type stubStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stubStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestSplitMethod(t *testing.T) {
+	t.Parallel()
+
+	service, method := splitMethod(addBook)
+	require.Equal(t, "library.Library", service)
+	require.Equal(t, "AddBook", method)
+
+	service, method = splitMethod("AddBook")
+	require.Equal(t, "unknown", service)
+	require.Equal(t, "AddBook", method)
+}
+
+func TestLogging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		err   error
+		level zapcore.Level
+		code  string
+	}{
+		{name: "ok", level: zapcore.InfoLevel, code: "OK"},
+		{name: "client error", err: status.Error(codes.NotFound, "book not found"), level: zapcore.WarnLevel, code: "NotFound"},
+		{name: "server error", err: status.Error(codes.Internal, "database is down"), level: zapcore.ErrorLevel, code: "Internal"},
+		{name: "plain error", err: errors.New("boom"), level: zapcore.ErrorLevel, code: "Unknown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			target := NewLogging(zap.New(core))
+
+			_, err := target.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: addBook},
+				func(context.Context, any) (any, error) { return nil, test.err })
+			require.ErrorIs(t, err, test.err)
+
+			entries := logs.All()
+			require.Len(t, entries, 1)
+			require.Equal(t, "access", entries[0].LoggerName)
+			require.Equal(t, test.level, entries[0].Level)
+
+			fields := entries[0].ContextMap()
+			require.Equal(t, "library.Library", fields["grpc.service"])
+			require.Equal(t, "AddBook", fields["grpc.method"])
+			require.Equal(t, test.code, fields["grpc.code"])
+			require.Contains(t, fields, "duration")
+		})
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+
+		core, logs := observer.New(zapcore.DebugLevel)
+		target := NewLogging(zap.New(core))
+
+		err := target.Stream()(nil, stubStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/library.Library/ExportCatalog"},
+			func(any, grpc.ServerStream) error { return status.Error(codes.InvalidArgument, "unknown format") })
+		require.Error(t, err)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+		require.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		require.Equal(t, "ExportCatalog", entries[0].ContextMap()["grpc.method"])
+	})
+}
+
+func metricValue(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if matches(metric, labels) {
+				if family.GetType() == dto.MetricType_HISTOGRAM {
+					return float64(metric.GetHistogram().GetSampleCount())
+				}
+
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func matches(metric *dto.Metric, labels map[string]string) bool {
+	for _, pair := range metric.GetLabel() {
+		if labels[pair.GetName()] != pair.GetValue() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	target := NewMetrics(registry)
+
+	ok := func(context.Context, any) (any, error) { return nil, nil }
+	notFound := func(context.Context, any) (any, error) { return nil, status.Error(codes.NotFound, "book not found") }
+
+	for _, handler := range []grpc.UnaryHandler{ok, ok, notFound} {
+		_, _ = target.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: addBook}, handler)
+	}
+
+	_ = target.Stream()(nil, stubStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/library.Library/ExportCatalog"},
+		func(any, grpc.ServerStream) error { return nil })
+
+	labels := func(method string, code string) map[string]string {
+		return map[string]string{"grpc_service": "library.Library", "grpc_method": method, "grpc_code": code}
+	}
+
+	require.InDelta(t, 2, metricValue(t, registry, "library_grpc_requests_total", labels("AddBook", "OK")), 0)
+	require.InDelta(t, 1, metricValue(t, registry, "library_grpc_requests_total", labels("AddBook", "NotFound")), 0)
+	require.InDelta(t, 1, metricValue(t, registry, "library_grpc_requests_total", labels("ExportCatalog", "OK")), 0)
+	require.InDelta(t, 2, metricValue(t, registry, "library_grpc_request_duration_seconds", labels("AddBook", "OK")), 0)
+
+	require.Panics(t, func() { NewMetrics(registry) }, "registered twice")
+}
+
+func TestRecovery(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	target := NewRecovery(zap.New(core))
+
+	resp, err := target.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: addBook},
+		func(context.Context, any) (any, error) { panic("nil map") })
+	require.Nil(t, resp)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.NotContains(t, err.Error(), "nil map")
+
+	err = target.Stream()(nil, stubStream{ctx: t.Context()}, &grpc.StreamServerInfo{FullMethod: "/library.Library/ExportCatalog"},
+		func(any, grpc.ServerStream) error { panic(errors.New("closed channel")) })
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	require.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	require.Equal(t, addBook, entries[0].ContextMap()["method"])
+	require.Equal(t, "nil map", entries[0].ContextMap()["panic"])
+	require.Contains(t, entries[0].ContextMap()["stack"], "TestRecovery")
+
+	t.Run("passes errors and results through", func(t *testing.T) {
+		t.Parallel()
+
+		want := status.Error(codes.NotFound, "book not found")
+		_, err := target.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: addBook},
+			func(context.Context, any) (any, error) { return nil, want })
+		require.ErrorIs(t, err, want)
+
+		resp, err := target.Unary()(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: addBook},
+			func(context.Context, any) (any, error) { return "book", nil })
+		require.NoError(t, err)
+		require.Equal(t, "book", resp)
+	})
+}
@@ -0,0 +1,87 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logging writes one access log entry per call with the method, the status
+// code, the duration, the trace and the peer.
+type Logging struct {
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewLogging(logger *zap.Logger) *Logging {
+	return &Logging{
+		logger: logger.Named("access"),
+		now:    time.Now,
+	}
+}
+
+func (l *Logging) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := l.now()
+		resp, err := handler(ctx, req)
+		l.log(ctx, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func (l *Logging) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := l.now()
+		err := handler(srv, stream)
+		l.log(stream.Context(), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func (l *Logging) log(ctx context.Context, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	code := status.Code(err)
+
+	fields := []zap.Field{
+		zap.String("grpc.service", service),
+		zap.String("grpc.method", method),
+		zap.String("grpc.code", code.String()),
+		zap.Duration("duration", l.now().Sub(start)),
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		fields = append(fields, zap.String("trace_id", spanContext.TraceID().String()))
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, zap.String("peer", p.Addr.String()))
+	}
+
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	l.logger.Log(level(code), "grpc call", fields...)
+}
+
+// level logs the errors of the server as errors, those of the client as
+// warnings.
+func level(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK:
+		return zapcore.InfoLevel
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded, codes.Unimplemented:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.WarnLevel
+	}
+}
@@ -0,0 +1,69 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics records the rate, the errors and the duration of the calls,
+// labelled by the service, the method and the status code.
+type Metrics struct {
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	now       func() time.Time
+}
+
+// NewMetrics registers the collectors with registerer; it panics when they
+// are registered twice.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	labels := []string{"grpc_service", "grpc_method", "grpc_code"}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "library_grpc_requests_total",
+			Help: "Total number of gRPC calls handled by the server",
+		}, labels),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "library_grpc_request_duration_seconds",
+			Help:    "Duration of the gRPC calls handled by the server",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		now: time.Now,
+	}
+
+	registerer.MustRegister(m.requests, m.durations)
+
+	return m
+}
+
+func (m *Metrics) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := m.now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func (m *Metrics) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := m.now()
+		err := handler(srv, stream)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func (m *Metrics) observe(fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	code := status.Code(err).String()
+
+	m.requests.WithLabelValues(service, method, code).Inc()
+	m.durations.WithLabelValues(service, method, code).Observe(m.now().Sub(start).Seconds())
+}
@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery turns a panic in a handler into codes.Internal, so a single call
+// can't take the server down. The panic and the stack are logged; the
+// client only learns that the call failed.
+type Recovery struct {
+	logger *zap.Logger
+}
+
+func NewRecovery(logger *zap.Logger) *Recovery {
+	return &Recovery{logger: logger}
+}
+
+func (r *Recovery) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer r.recover(info.FullMethod, &err)
+
+		return handler(ctx, req)
+	}
+}
+
+func (r *Recovery) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer r.recover(info.FullMethod, &err)
+
+		return handler(srv, stream)
+	}
+}
+
+func (r *Recovery) recover(fullMethod string, err *error) {
+	recovered := recover()
+
+	if recovered == nil {
+		return
+	}
+
+	r.logger.Error("panic in grpc handler",
+		zap.String("method", fullMethod),
+		zap.Any("panic", recovered),
+		zap.StackSkip("stack", 1),
+	)
+
+	*err = status.Error(codes.Internal, "internal error")
+}
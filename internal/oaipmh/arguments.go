@@ -0,0 +1,161 @@
+package oaipmh
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	verbIdentify            = "Identify"
+	verbListMetadataFormats = "ListMetadataFormats"
+	verbListSets            = "ListSets"
+	verbGetRecord           = "GetRecord"
+	verbListIdentifiers     = "ListIdentifiers"
+	verbListRecords         = "ListRecords"
+)
+
+type argument int
+
+const (
+	optional argument = iota
+	required
+	// exclusive arguments come with the verb alone.
+	exclusive
+)
+
+var listArguments = map[string]argument{
+	"metadataPrefix":  required,
+	"from":            optional,
+	"until":           optional,
+	"set":             optional,
+	"resumptionToken": exclusive,
+}
+
+var verbArguments = map[string]map[string]argument{
+	verbIdentify:            {},
+	verbListMetadataFormats: {"identifier": optional},
+	verbListSets:            {"resumptionToken": exclusive},
+	verbGetRecord:           {"identifier": required, "metadataPrefix": required},
+	verbListIdentifiers:     listArguments,
+	verbListRecords:         listArguments,
+}
+
+func checkArguments(form url.Values, rules map[string]argument) error {
+	hasExclusive := false
+
+	for name, values := range form {
+		if name == "verb" {
+			continue
+		}
+
+		rule, ok := rules[name]
+
+		if !ok {
+			return badArgument("illegal argument " + name)
+		}
+
+		if len(values) > 1 {
+			return badArgument("repeated argument " + name)
+		}
+
+		hasExclusive = hasExclusive || rule == exclusive
+	}
+
+	if hasExclusive {
+		if len(form) > 2 {
+			return badArgument("resumptionToken is an exclusive argument")
+		}
+
+		return nil
+	}
+
+	for name, rule := range rules {
+		if rule == required && !form.Has(name) {
+			return badArgument("missing argument " + name)
+		}
+	}
+
+	return nil
+}
+
+const (
+	// Granularity is the finest datestamp granularity of the repository.
+	Granularity = "YYYY-MM-DDThh:mm:ssZ"
+
+	secondLayout = "2006-01-02T15:04:05Z"
+	dayLayout    = "2006-01-02"
+)
+
+func formatDatestamp(t time.Time) string {
+	return t.UTC().Format(secondLayout)
+}
+
+// parseDatestamp returns the time a datestamp starts at and its length,
+// a second or a day.
+func parseDatestamp(value string) (time.Time, time.Duration, error) {
+	if t, err := time.ParseInLocation(secondLayout, value, time.UTC); err == nil {
+		return t, time.Second, nil
+	}
+
+	t, err := time.ParseInLocation(dayLayout, value, time.UTC)
+
+	if err != nil {
+		return time.Time{}, 0, badArgument("illegal datestamp " + value)
+	}
+
+	return t, 24 * time.Hour, nil
+}
+
+// parseInterval turns the inclusive from and until arguments into the
+// half-open interval of HarvestQuery.
+func parseInterval(from string, until string) (time.Time, time.Time, error) {
+	var (
+		start, end          time.Time
+		fromStep, untilStep time.Duration
+		err                 error
+	)
+
+	if from != "" {
+		if start, fromStep, err = parseDatestamp(from); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if until != "" {
+		if end, untilStep, err = parseDatestamp(until); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+
+		end = end.Add(untilStep)
+	}
+
+	if from != "" && until != "" {
+		if fromStep != untilStep {
+			return time.Time{}, time.Time{}, badArgument("from and until have different granularities")
+		}
+
+		if !start.Before(end) {
+			return time.Time{}, time.Time{}, badArgument("from is after until")
+		}
+	}
+
+	return start, end, nil
+}
+
+func (h *handler) identifier(bookID string) string {
+	return "oai:" + h.identity.RepositoryIdentifier + ":" + bookID
+}
+
+// bookID returns the book ID in an item identifier.
+func (h *handler) bookID(identifier string) (string, error) {
+	id, ok := strings.CutPrefix(identifier, "oai:"+h.identity.RepositoryIdentifier+":")
+
+	if !ok || uuid.Validate(id) != nil {
+		return "", &oaiError{Code: codeIDDoesNotExist, Message: "unknown identifier " + identifier}
+	}
+
+	return id, nil
+}
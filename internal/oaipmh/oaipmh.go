@@ -0,0 +1,179 @@
+// Package oaipmh serves the books to catalog harvesters over OAI-PMH 2.0
+// with unqualified Dublin Core (oai_dc) metadata.
+package oaipmh
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/project/library/internal/entity"
+	"go.uber.org/zap"
+)
+
+// Harvester is the part of the books use case the handler needs.
+type Harvester interface {
+	ListBookRecords(ctx context.Context, query entity.HarvestQuery) ([]entity.BookRecord, error)
+	GetBookRecord(ctx context.Context, bookID string) (entity.BookRecord, error)
+	EarliestDatestamp(ctx context.Context) (time.Time, error)
+}
+
+// Identity describes the repository in Identify responses.
+type Identity struct {
+	RepositoryName string
+	// BaseURL is the URL harvesters send requests to; when empty it is
+	// taken from the request.
+	BaseURL     string
+	AdminEmails []string
+	// RepositoryIdentifier names the repository in the item identifiers,
+	// oai:<RepositoryIdentifier>:<book ID>.
+	RepositoryIdentifier string
+}
+
+// DefaultPageSize is the number of records in a list response before it is
+// continued with a resumption token.
+const DefaultPageSize = 100
+
+type (
+	Option func(options *options)
+
+	options struct {
+		pageSize int
+		now      func() time.Time
+	}
+)
+
+// WithPageSize sets the number of records per list response.
+func WithPageSize(pageSize int) Option {
+	return func(options *options) {
+		if pageSize > 0 {
+			options.pageSize = pageSize
+		}
+	}
+}
+
+// WithClock sets the source of response dates.
+func WithClock(now func() time.Time) Option {
+	return func(options *options) {
+		options.now = now
+	}
+}
+
+type handler struct {
+	logger   *zap.Logger
+	books    Harvester
+	identity Identity
+	options
+}
+
+func New(logger *zap.Logger, books Harvester, identity Identity, opts ...Option) http.Handler {
+	h := &handler{
+		logger:   logger,
+		books:    books,
+		identity: identity,
+		options:  options{pageSize: DefaultPageSize, now: time.Now},
+	}
+
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	res := &response{
+		ResponseDate: formatDatestamp(h.now()),
+		Request:      request{BaseURL: h.baseURL(r)},
+	}
+
+	err := h.serve(r, res)
+
+	var oaiErr *oaiError
+
+	switch {
+	case errors.As(err, &oaiErr):
+		res.Errors = []*oaiError{oaiErr}
+	case err != nil:
+		h.logger.Error("OAI-PMH request failed", zap.String("verb", res.Request.Verb), zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	res.prepare()
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if _, err := w.Write([]byte(xml.Header)); err == nil {
+		err = encoder.Encode(res)
+
+		if err != nil {
+			h.logger.Error("can not write OAI-PMH response", zap.Error(err))
+		}
+	}
+}
+
+func (h *handler) serve(r *http.Request, res *response) error {
+	if err := r.ParseForm(); err != nil {
+		return badArgument("can not parse the arguments")
+	}
+
+	verbs := r.Form["verb"]
+
+	if len(verbs) != 1 {
+		return &oaiError{Code: codeBadVerb, Message: "exactly one verb is required"}
+	}
+
+	rules, ok := verbArguments[verbs[0]]
+
+	if !ok {
+		return &oaiError{Code: codeBadVerb, Message: "illegal verb " + verbs[0]}
+	}
+
+	if err := checkArguments(r.Form, rules); err != nil {
+		return err
+	}
+
+	res.Request.echo(verbs[0], r.Form)
+	ctx := r.Context()
+
+	switch verbs[0] {
+	case verbIdentify:
+		return h.identify(ctx, res)
+	case verbListMetadataFormats:
+		return h.listMetadataFormats(ctx, r.Form, res)
+	case verbListSets:
+		return &oaiError{Code: codeNoSetHierarchy, Message: "sets are not supported"}
+	case verbGetRecord:
+		return h.getRecord(ctx, r.Form, res)
+	default:
+		return h.list(ctx, verbs[0], r.Form, res)
+	}
+}
+
+func (h *handler) baseURL(r *http.Request) string {
+	if h.identity.BaseURL != "" {
+		return h.identity.BaseURL
+	}
+
+	scheme := "http"
+
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.Path
+}
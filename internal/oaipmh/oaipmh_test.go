@@ -0,0 +1,280 @@
+package oaipmh
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project/library/generated/mocks"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	liveID    = "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a"
+	deletedID = "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b"
+	thirdID   = "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5c"
+)
+
+var (
+	now     = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	changed = time.Date(2026, 2, 1, 10, 30, 0, 123456000, time.UTC)
+
+	liveBook = entity.BookRecord{
+		Book: entity.Book{
+			ID:        liveID,
+			Name:      "War and Peace",
+			ISBN:      "9780140447934",
+			Publisher: "Penguin Classics",
+		},
+		Authors:   []string{"Leo Tolstoy"},
+		Datestamp: changed,
+	}
+	deletedBook = entity.BookRecord{
+		Book:      entity.Book{ID: deletedID},
+		Deleted:   true,
+		Datestamp: changed.Add(time.Second),
+	}
+	thirdBook = entity.BookRecord{
+		Book:      entity.Book{ID: thirdID, Name: "Anna Karenina"},
+		Datestamp: changed.Add(time.Hour),
+	}
+)
+
+// parsed is the part of a response the tests look at.
+type parsed struct {
+	ResponseDate string `xml:"responseDate"`
+	Request      struct {
+		Verb    string `xml:"verb,attr"`
+		BaseURL string `xml:",chardata"`
+	} `xml:"request"`
+	Error *struct {
+		Code string `xml:"code,attr"`
+	} `xml:"error"`
+	Identify struct {
+		RepositoryName    string `xml:"repositoryName"`
+		EarliestDatestamp string `xml:"earliestDatestamp"`
+		DeletedRecord     string `xml:"deletedRecord"`
+	} `xml:"Identify"`
+	Records []struct {
+		Header struct {
+			Status     string `xml:"status,attr"`
+			Identifier string `xml:"identifier"`
+			Datestamp  string `xml:"datestamp"`
+		} `xml:"header"`
+		Title       string   `xml:"metadata>dc>title"`
+		Creators    []string `xml:"metadata>dc>creator"`
+		Publisher   string   `xml:"metadata>dc>publisher"`
+		Identifiers []string `xml:"metadata>dc>identifier"`
+	} `xml:"ListRecords>record"`
+	Record struct {
+		Header struct {
+			Status     string `xml:"status,attr"`
+			Identifier string `xml:"identifier"`
+		} `xml:"header"`
+		Title string `xml:"metadata>dc>title"`
+	} `xml:"GetRecord>record"`
+	Headers []struct {
+		Identifier string `xml:"identifier"`
+	} `xml:"ListIdentifiers>header"`
+	Token *struct {
+		Cursor int    `xml:"cursor,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"ListRecords>resumptionToken"`
+	Formats []string `xml:"ListMetadataFormats>metadataFormat>metadataPrefix"`
+}
+
+func newHandler(t *testing.T) (http.Handler, *mocks.MockBooksUseCase) {
+	t.Helper()
+
+	books := mocks.NewMockBooksUseCase(gomock.NewController(t))
+	handler := New(zaptest.NewLogger(t), books, Identity{
+		RepositoryName:       "Library",
+		BaseURL:              "https://library.example.org/oai",
+		AdminEmails:          []string{"admin@library.example.org"},
+		RepositoryIdentifier: "library.example.org",
+	}, WithPageSize(2), WithClock(func() time.Time { return now }))
+
+	return handler, books
+}
+
+func get(t *testing.T, handler http.Handler, query string) parsed {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/oai?"+query, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "text/xml; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	var result parsed
+	require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &result), recorder.Body.String())
+
+	return result
+}
+
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+	books.EXPECT().EarliestDatestamp(gomock.Any()).Return(changed, nil)
+
+	result := get(t, handler, "verb=Identify")
+	require.Nil(t, result.Error)
+	require.Equal(t, "2026-03-01T12:00:00Z", result.ResponseDate)
+	require.Equal(t, "Identify", result.Request.Verb)
+	require.Equal(t, "https://library.example.org/oai", result.Request.BaseURL)
+	require.Equal(t, "Library", result.Identify.RepositoryName)
+	require.Equal(t, "2026-02-01T10:30:00Z", result.Identify.EarliestDatestamp)
+	require.Equal(t, "persistent", result.Identify.DeletedRecord)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+	books.EXPECT().GetBookRecord(gomock.Any(), thirdID).Return(entity.BookRecord{}, entity.ErrBookNotFound).Times(2)
+	books.EXPECT().ListBookRecords(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{name: "no verb", query: "", code: codeBadVerb},
+		{name: "unknown verb", query: "verb=Harvest", code: codeBadVerb},
+		{name: "repeated verb", query: "verb=Identify&verb=Identify", code: codeBadVerb},
+		{name: "illegal argument", query: "verb=Identify&metadataPrefix=oai_dc", code: codeBadArgument},
+		{name: "missing argument", query: "verb=ListRecords", code: codeBadArgument},
+		{name: "repeated argument", query: "verb=ListRecords&metadataPrefix=oai_dc&metadataPrefix=oai_dc", code: codeBadArgument},
+		{name: "token not exclusive", query: "verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=x", code: codeBadArgument},
+		{name: "bad datestamp", query: "verb=ListRecords&metadataPrefix=oai_dc&from=yesterday", code: codeBadArgument},
+		{name: "mixed granularities", query: "verb=ListRecords&metadataPrefix=oai_dc&from=2026-01-01&until=2026-02-01T00:00:00Z", code: codeBadArgument},
+		{name: "from after until", query: "verb=ListRecords&metadataPrefix=oai_dc&from=2026-02-02&until=2026-02-01", code: codeBadArgument},
+		{name: "bad token", query: "verb=ListIdentifiers&resumptionToken=x", code: codeBadResumptionToken},
+		{name: "unknown format", query: "verb=ListRecords&metadataPrefix=marc21", code: codeCannotDisseminateFormat},
+		{name: "sets", query: "verb=ListSets", code: codeNoSetHierarchy},
+		{name: "set argument", query: "verb=ListRecords&metadataPrefix=oai_dc&set=fiction", code: codeNoSetHierarchy},
+		{name: "no records", query: "verb=ListRecords&metadataPrefix=oai_dc", code: codeNoRecordsMatch},
+		{name: "foreign identifier", query: "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:other.org:" + liveID, code: codeIDDoesNotExist},
+		{name: "unknown book", query: "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:library.example.org:" + thirdID, code: codeIDDoesNotExist},
+		{name: "unknown book formats", query: "verb=ListMetadataFormats&identifier=oai:library.example.org:" + thirdID, code: codeIDDoesNotExist},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := get(t, handler, test.query)
+			require.NotNil(t, result.Error)
+			require.Equal(t, test.code, result.Error.Code)
+
+			if test.code == codeBadVerb || test.code == codeBadArgument {
+				require.Empty(t, result.Request.Verb)
+			}
+		})
+	}
+}
+
+func TestListRecords(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+
+	books.EXPECT().ListBookRecords(gomock.Any(), entity.HarvestQuery{From: from, Until: until, Limit: 3}).
+		Return([]entity.BookRecord{liveBook, deletedBook, thirdBook}, nil)
+	books.EXPECT().ListBookRecords(gomock.Any(), entity.HarvestQuery{
+		From:  from,
+		Until: until,
+		After: entity.HarvestCursor{Datestamp: deletedBook.Datestamp, ID: deletedID},
+		Limit: 3,
+	}).Return([]entity.BookRecord{thirdBook}, nil)
+
+	first := get(t, handler, "verb=ListRecords&metadataPrefix=oai_dc&from=2026-02-01&until=2026-02-01")
+	require.Nil(t, first.Error)
+	require.Len(t, first.Records, 2)
+
+	live := first.Records[0]
+	require.Equal(t, "oai:library.example.org:"+liveID, live.Header.Identifier)
+	require.Equal(t, "2026-02-01T10:30:00Z", live.Header.Datestamp)
+	require.Empty(t, live.Header.Status)
+	require.Equal(t, "War and Peace", live.Title)
+	require.Equal(t, []string{"Leo Tolstoy"}, live.Creators)
+	require.Equal(t, "Penguin Classics", live.Publisher)
+	require.Equal(t, []string{"urn:uuid:" + liveID, "urn:isbn:9780140447934"}, live.Identifiers)
+
+	deleted := first.Records[1]
+	require.Equal(t, "deleted", deleted.Header.Status)
+	require.Empty(t, deleted.Title)
+
+	require.NotNil(t, first.Token)
+	require.Equal(t, 0, first.Token.Cursor)
+	require.NotEmpty(t, first.Token.Value)
+
+	second := get(t, handler, "verb=ListRecords&resumptionToken="+url.QueryEscape(first.Token.Value))
+	require.Nil(t, second.Error)
+	require.Len(t, second.Records, 1)
+	require.Equal(t, "Anna Karenina", second.Records[0].Title)
+	require.NotNil(t, second.Token)
+	require.Equal(t, 2, second.Token.Cursor)
+	require.Empty(t, second.Token.Value)
+}
+
+func TestListIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+	books.EXPECT().ListBookRecords(gomock.Any(), entity.HarvestQuery{From: changed.Truncate(time.Second), Limit: 3}).
+		Return([]entity.BookRecord{liveBook, deletedBook}, nil)
+
+	result := get(t, handler, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2026-02-01T10:30:00Z")
+	require.Nil(t, result.Error)
+	require.Len(t, result.Headers, 2)
+	require.Equal(t, "oai:library.example.org:"+deletedID, result.Headers[1].Identifier)
+}
+
+func TestGetRecord(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+	books.EXPECT().GetBookRecord(gomock.Any(), liveID).Return(liveBook, nil).Times(2)
+	books.EXPECT().GetBookRecord(gomock.Any(), deletedID).Return(deletedBook, nil)
+
+	result := get(t, handler, "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:library.example.org:"+liveID)
+	require.Nil(t, result.Error)
+	require.Equal(t, "War and Peace", result.Record.Title)
+
+	result = get(t, handler, "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:library.example.org:"+deletedID)
+	require.Nil(t, result.Error)
+	require.Equal(t, "deleted", result.Record.Header.Status)
+
+	result = get(t, handler, "verb=ListMetadataFormats&identifier=oai:library.example.org:"+liveID)
+	require.Nil(t, result.Error)
+	require.Equal(t, []string{"oai_dc"}, result.Formats)
+}
+
+func TestPostAndFailures(t *testing.T) {
+	t.Parallel()
+
+	handler, books := newHandler(t)
+	books.EXPECT().EarliestDatestamp(gomock.Any()).Return(time.Time{}, context.DeadlineExceeded)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/oai", strings.NewReader("verb=Identify"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/oai", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
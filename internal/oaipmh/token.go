@@ -0,0 +1,44 @@
+package oaipmh
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// resumption is the state of an incomplete list carried by its resumption
+// tokens. The list goes on after the last record sent, so the tokens don't
+// expire and changes between the requests don't shift it.
+type resumption struct {
+	MetadataPrefix string    `json:"m"`
+	From           time.Time `json:"f"`
+	Until          time.Time `json:"u"`
+	Datestamp      time.Time `json:"d"`
+	ID             string    `json:"i"`
+	Cursor         int       `json:"c"`
+}
+
+func (r resumption) encode() string {
+	data, _ := json.Marshal(r)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeResumption(token string) (resumption, error) {
+	errBadToken := &oaiError{Code: codeBadResumptionToken, Message: "illegal resumption token"}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return resumption{}, errBadToken
+	}
+
+	var r resumption
+
+	if err := json.Unmarshal(data, &r); err != nil || uuid.Validate(r.ID) != nil || r.MetadataPrefix != metadataPrefixDC || r.Cursor < 0 {
+		return resumption{}, errBadToken
+	}
+
+	return r, nil
+}
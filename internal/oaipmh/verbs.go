@@ -0,0 +1,207 @@
+package oaipmh
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/project/library/internal/entity"
+)
+
+var dcFormat = metadataFormat{
+	MetadataPrefix:    metadataPrefixDC,
+	Schema:            dcSchema,
+	MetadataNamespace: dcNamespace,
+}
+
+func (h *handler) identify(ctx context.Context, res *response) error {
+	earliest, err := h.books.EarliestDatestamp(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	res.Identify = &identify{
+		RepositoryName:    h.identity.RepositoryName,
+		BaseURL:           res.Request.BaseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmails:       h.identity.AdminEmails,
+		EarliestDatestamp: formatDatestamp(earliest),
+		// Purged and merged books are still reported from their history.
+		DeletedRecord: "persistent",
+		Granularity:   Granularity,
+	}
+
+	return nil
+}
+
+func (h *handler) listMetadataFormats(ctx context.Context, form url.Values, res *response) error {
+	if form.Has("identifier") {
+		if _, err := h.getBookRecord(ctx, form.Get("identifier")); err != nil {
+			return err
+		}
+	}
+
+	res.ListMetadataFormats = &listMetadataFormats{MetadataFormats: []metadataFormat{dcFormat}}
+
+	return nil
+}
+
+func (h *handler) getRecord(ctx context.Context, form url.Values, res *response) error {
+	book, err := h.getBookRecord(ctx, form.Get("identifier"))
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkMetadataPrefix(form.Get("metadataPrefix")); err != nil {
+		return err
+	}
+
+	res.GetRecord = &getRecord{Record: h.record(book)}
+
+	return nil
+}
+
+func (h *handler) getBookRecord(ctx context.Context, identifier string) (entity.BookRecord, error) {
+	bookID, err := h.bookID(identifier)
+
+	if err != nil {
+		return entity.BookRecord{}, err
+	}
+
+	book, err := h.books.GetBookRecord(ctx, bookID)
+
+	if errors.Is(err, entity.ErrBookNotFound) {
+		return entity.BookRecord{}, &oaiError{Code: codeIDDoesNotExist, Message: "unknown identifier " + identifier}
+	}
+
+	return book, err
+}
+
+// list serves ListIdentifiers and ListRecords, a page at a time.
+func (h *handler) list(ctx context.Context, verb string, form url.Values, res *response) error {
+	state, err := h.listState(form)
+
+	if err != nil {
+		return err
+	}
+
+	books, err := h.books.ListBookRecords(ctx, entity.HarvestQuery{
+		From:  state.From,
+		Until: state.Until,
+		After: entity.HarvestCursor{Datestamp: state.Datestamp, ID: state.ID},
+		Limit: h.pageSize + 1,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(books) == 0 {
+		return &oaiError{Code: codeNoRecordsMatch, Message: "no records match the arguments"}
+	}
+
+	var token *resumptionToken
+
+	switch {
+	case len(books) > h.pageSize:
+		books = books[:h.pageSize]
+		last := books[len(books)-1]
+		next := state
+		next.Datestamp, next.ID, next.Cursor = last.Datestamp, last.ID, state.Cursor+len(books)
+		token = &resumptionToken{Cursor: state.Cursor, Token: next.encode()}
+	case form.Has("resumptionToken"):
+		// The last part of an incomplete list ends with an empty token.
+		token = &resumptionToken{Cursor: state.Cursor}
+	}
+
+	if verb == verbListIdentifiers {
+		res.ListIdentifiers = &listIdentifiers{ResumptionToken: token}
+
+		for _, book := range books {
+			res.ListIdentifiers.Headers = append(res.ListIdentifiers.Headers, h.header(book))
+		}
+
+		return nil
+	}
+
+	res.ListRecords = &listRecords{ResumptionToken: token}
+
+	for _, book := range books {
+		res.ListRecords.Records = append(res.ListRecords.Records, h.record(book))
+	}
+
+	return nil
+}
+
+func (h *handler) listState(form url.Values) (resumption, error) {
+	if form.Has("resumptionToken") {
+		return decodeResumption(form.Get("resumptionToken"))
+	}
+
+	if err := checkMetadataPrefix(form.Get("metadataPrefix")); err != nil {
+		return resumption{}, err
+	}
+
+	if form.Has("set") {
+		return resumption{}, &oaiError{Code: codeNoSetHierarchy, Message: "sets are not supported"}
+	}
+
+	from, until, err := parseInterval(form.Get("from"), form.Get("until"))
+
+	if err != nil {
+		return resumption{}, err
+	}
+
+	return resumption{MetadataPrefix: metadataPrefixDC, From: from, Until: until}, nil
+}
+
+func checkMetadataPrefix(prefix string) error {
+	if prefix != metadataPrefixDC {
+		return &oaiError{Code: codeCannotDisseminateFormat, Message: "unsupported metadata format " + prefix}
+	}
+
+	return nil
+}
+
+func (h *handler) header(book entity.BookRecord) header {
+	result := header{
+		Identifier: h.identifier(book.ID),
+		Datestamp:  formatDatestamp(book.Datestamp),
+	}
+
+	if book.Deleted {
+		result.Status = "deleted"
+	}
+
+	return result
+}
+
+// record maps a book to Dublin Core; a deleted book has only the header.
+func (h *handler) record(book entity.BookRecord) record {
+	result := record{Header: h.header(book)}
+
+	if book.Deleted {
+		return result
+	}
+
+	dc := dublinCore{
+		OAIDC:          dcNamespace,
+		DC:             dcElementNamespace,
+		SchemaLocation: dcNamespace + " " + dcSchema,
+		Title:          book.Name,
+		Creators:       book.Authors,
+		Publisher:      book.Publisher,
+		Type:           "Text",
+		Identifiers:    []string{"urn:uuid:" + book.ID},
+	}
+
+	if book.ISBN != "" {
+		dc.Identifiers = append(dc.Identifiers, "urn:isbn:"+book.ISBN)
+	}
+
+	result.Metadata = &metadata{DC: dc}
+
+	return result
+}
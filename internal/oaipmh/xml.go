@@ -0,0 +1,155 @@
+package oaipmh
+
+import (
+	"encoding/xml"
+	"net/url"
+)
+
+const (
+	namespace          = "http://www.openarchives.org/OAI/2.0/"
+	schemaLocation     = namespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	xsiNamespace       = "http://www.w3.org/2001/XMLSchema-instance"
+	metadataPrefixDC   = "oai_dc"
+	dcNamespace        = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	dcSchema           = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	dcElementNamespace = "http://purl.org/dc/elements/1.1/"
+)
+
+const (
+	codeBadArgument             = "badArgument"
+	codeBadResumptionToken      = "badResumptionToken"
+	codeBadVerb                 = "badVerb"
+	codeCannotDisseminateFormat = "cannotDisseminateFormat"
+	codeIDDoesNotExist          = "idDoesNotExist"
+	codeNoRecordsMatch          = "noRecordsMatch"
+	codeNoSetHierarchy          = "noSetHierarchy"
+)
+
+// oaiError is an OAI-PMH error; it is sent in the response, which still
+// has status 200.
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+func (e *oaiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func badArgument(message string) *oaiError {
+	return &oaiError{Code: codeBadArgument, Message: message}
+}
+
+type response struct {
+	XMLName        xml.Name `xml:"http://www.openarchives.org/OAI/2.0/ OAI-PMH"`
+	XSI            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate   string   `xml:"responseDate"`
+	Request        request  `xml:"request"`
+
+	Errors              []*oaiError          `xml:"error"`
+	Identify            *identify            `xml:"Identify"`
+	ListMetadataFormats *listMetadataFormats `xml:"ListMetadataFormats"`
+	GetRecord           *getRecord           `xml:"GetRecord"`
+	ListIdentifiers     *listIdentifiers     `xml:"ListIdentifiers"`
+	ListRecords         *listRecords         `xml:"ListRecords"`
+}
+
+// prepare fills in the namespace attributes and, as the protocol requires,
+// drops the request arguments after a badVerb or badArgument error.
+func (r *response) prepare() {
+	r.XSI = xsiNamespace
+	r.SchemaLocation = schemaLocation
+
+	for _, err := range r.Errors {
+		if err.Code == codeBadVerb || err.Code == codeBadArgument {
+			r.Request = request{BaseURL: r.Request.BaseURL}
+		}
+	}
+}
+
+type request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+func (r *request) echo(verb string, form url.Values) {
+	r.Verb = verb
+	r.Identifier = form.Get("identifier")
+	r.MetadataPrefix = form.Get("metadataPrefix")
+	r.From = form.Get("from")
+	r.Until = form.Get("until")
+	r.Set = form.Get("set")
+	r.ResumptionToken = form.Get("resumptionToken")
+}
+
+type identify struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmails       []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+type metadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type listMetadataFormats struct {
+	MetadataFormats []metadataFormat `xml:"metadataFormat"`
+}
+
+type getRecord struct {
+	Record record `xml:"record"`
+}
+
+type listIdentifiers struct {
+	Headers         []header         `xml:"header"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken"`
+}
+
+type listRecords struct {
+	Records         []record         `xml:"record"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken"`
+}
+
+type resumptionToken struct {
+	Cursor int    `xml:"cursor,attr"`
+	Token  string `xml:",chardata"`
+}
+
+type record struct {
+	Header   header    `xml:"header"`
+	Metadata *metadata `xml:"metadata"`
+}
+
+type header struct {
+	Status     string `xml:"status,attr,omitempty"`
+	Identifier string `xml:"identifier"`
+	Datestamp  string `xml:"datestamp"`
+}
+
+type metadata struct {
+	DC dublinCore `xml:"oai_dc:dc"`
+}
+
+type dublinCore struct {
+	OAIDC          string   `xml:"xmlns:oai_dc,attr"`
+	DC             string   `xml:"xmlns:dc,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          string   `xml:"dc:title"`
+	Creators       []string `xml:"dc:creator"`
+	Publisher      string   `xml:"dc:publisher,omitempty"`
+	Type           string   `xml:"dc:type"`
+	Identifiers    []string `xml:"dc:identifier"`
+}
@@ -0,0 +1,192 @@
+package opds
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/project/library/internal/entity"
+)
+
+const (
+	dcNamespace = "http://purl.org/dc/terms/"
+
+	navigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	acquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	bookInfoType    = "application/json"
+
+	relSelf       = "self"
+	relStart      = "start"
+	relUp         = "up"
+	relNext       = "next"
+	relPrevious   = "previous"
+	relFirst      = "first"
+	relSearch     = "search"
+	relAlternate  = "alternate"
+	relRelated    = "related"
+	relSubsection = "subsection"
+	relSortNew    = "http://opds-spec.org/sort/new"
+	relBorrow     = "http://opds-spec.org/acquisition/borrow"
+)
+
+// renderer writes a feed in one of the OPDS versions.
+type renderer interface {
+	prefix() string
+	// searchParameter names the query parameter of the search terms.
+	searchParameter() string
+	render(w http.ResponseWriter, f feed, now time.Time) error
+}
+
+type atomRenderer struct{}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	DC      string      `xml:"xmlns:dc,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      string       `xml:"title"`
+	ID         string       `xml:"id"`
+	Updated    string       `xml:"updated"`
+	Authors    []atomPerson `xml:"author"`
+	Identifier string       `xml:"dc:identifier,omitempty"`
+	Publisher  string       `xml:"dc:publisher,omitempty"`
+	Content    *atomContent `xml:"content"`
+	Links      []atomLink   `xml:"link"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+func (atomRenderer) prefix() string {
+	return Prefix
+}
+
+func (atomRenderer) searchParameter() string {
+	return "q"
+}
+
+func (a atomRenderer) render(w http.ResponseWriter, f feed, now time.Time) error {
+	updated := now.UTC().Format(time.RFC3339)
+	selfType := navigationType
+
+	if f.kind == acquisitionFeed {
+		selfType = acquisitionType
+	}
+
+	result := atomFeed{
+		DC:      dcNamespace,
+		ID:      f.id,
+		Title:   f.title,
+		Updated: updated,
+		Author:  atomPerson{Name: f.author},
+		Links: []atomLink{
+			{Rel: relSelf, Href: Prefix + f.pagePath(f.page), Type: selfType},
+			{Rel: relStart, Href: Prefix, Type: navigationType},
+			{Rel: relSearch, Href: openSearchPath, Type: openSearchType},
+		},
+	}
+
+	for _, link := range pageLinks(f) {
+		result.Links = append(result.Links, atomLink{Rel: link.rel, Href: Prefix + link.path, Type: selfType})
+	}
+
+	for _, entry := range f.navigation {
+		entryType := navigationType
+
+		if entry.kind == acquisitionFeed {
+			entryType = acquisitionType
+		}
+
+		result.Entries = append(result.Entries, atomEntry{
+			Title:   entry.title,
+			ID:      entry.id,
+			Updated: updated,
+			Content: &atomContent{Type: "text", Text: entry.content},
+			Links:   []atomLink{{Rel: entry.rel, Href: Prefix + entry.path, Type: entryType}},
+		})
+	}
+
+	for _, book := range f.books {
+		result.Entries = append(result.Entries, a.bookEntry(book))
+	}
+
+	w.Header().Set("Content-Type", selfType+";charset=utf-8")
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(result)
+}
+
+func (atomRenderer) bookEntry(book entity.BookRecord) atomEntry {
+	entry := atomEntry{
+		Title:     book.Name,
+		ID:        "urn:uuid:" + book.ID,
+		Updated:   book.UpdatedAt.UTC().Format(time.RFC3339),
+		Publisher: book.Publisher,
+		Links: []atomLink{
+			{Rel: relAlternate, Href: bookInfoPath + book.ID, Type: bookInfoType},
+			{Rel: relBorrow, Href: bookInfoPath + book.ID, Type: bookInfoType},
+		},
+	}
+
+	if book.ISBN != "" {
+		entry.Identifier = "urn:isbn:" + book.ISBN
+	}
+
+	for i, name := range book.Authors {
+		path := Prefix + "/authors/" + book.AuthorIDs[i]
+		entry.Authors = append(entry.Authors, atomPerson{Name: name, URI: path})
+		entry.Links = append(entry.Links, atomLink{Rel: relRelated, Href: path, Type: acquisitionType, Title: name})
+	}
+
+	return entry
+}
+
+type pageLink struct {
+	rel  string
+	path string
+}
+
+// pageLinks returns the links to the first, previous and next pages.
+func pageLinks(f feed) []pageLink {
+	var links []pageLink
+
+	if f.page > 1 {
+		links = append(links,
+			pageLink{rel: relFirst, path: f.pagePath(1)},
+			pageLink{rel: relPrevious, path: f.pagePath(f.page - 1)},
+		)
+	}
+
+	if f.more {
+		links = append(links, pageLink{rel: relNext, path: f.pagePath(f.page + 1)})
+	}
+
+	return links
+}
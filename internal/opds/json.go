@@ -0,0 +1,128 @@
+package opds
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/project/library/internal/entity"
+)
+
+const (
+	opds2Type       = "application/opds+json"
+	publicationType = "http://schema.org/Book"
+)
+
+type jsonRenderer struct{}
+
+type opds2Feed struct {
+	Metadata   opds2Metadata `json:"metadata"`
+	Links      []opds2Link   `json:"links"`
+	Navigation []opds2Link   `json:"navigation,omitempty"`
+	// Publications is a pointer so an empty acquisition feed still has
+	// the collection.
+	Publications *[]publication `json:"publications,omitempty"`
+}
+
+type opds2Metadata struct {
+	Title        string    `json:"title"`
+	Modified     time.Time `json:"modified"`
+	ItemsPerPage int       `json:"itemsPerPage,omitempty"`
+	CurrentPage  int       `json:"currentPage,omitempty"`
+}
+
+type opds2Link struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type publication struct {
+	Metadata publicationMetadata `json:"metadata"`
+	Links    []opds2Link         `json:"links"`
+}
+
+type publicationMetadata struct {
+	Type       string        `json:"@type"`
+	Title      string        `json:"title"`
+	Identifier string        `json:"identifier"`
+	Author     []contributor `json:"author,omitempty"`
+	Publisher  string        `json:"publisher,omitempty"`
+	Modified   time.Time     `json:"modified"`
+}
+
+type contributor struct {
+	Name  string      `json:"name"`
+	Links []opds2Link `json:"links,omitempty"`
+}
+
+func (jsonRenderer) prefix() string {
+	return V2Prefix
+}
+
+func (jsonRenderer) searchParameter() string {
+	return "query"
+}
+
+func (j jsonRenderer) render(w http.ResponseWriter, f feed, now time.Time) error {
+	result := opds2Feed{
+		Metadata: opds2Metadata{Title: f.title, Modified: now.UTC()},
+		Links: []opds2Link{
+			{Rel: relSelf, Href: V2Prefix + f.pagePath(f.page), Type: opds2Type},
+			{Rel: relStart, Href: V2Prefix, Type: opds2Type},
+			{Rel: relSearch, Href: V2Prefix + "/search{?query}", Type: opds2Type, Templated: true},
+		},
+	}
+
+	for _, link := range pageLinks(f) {
+		result.Links = append(result.Links, opds2Link{Rel: link.rel, Href: V2Prefix + link.path, Type: opds2Type})
+	}
+
+	for _, entry := range f.navigation {
+		result.Navigation = append(result.Navigation, opds2Link{Rel: entry.rel, Href: V2Prefix + entry.path, Type: opds2Type, Title: entry.title})
+	}
+
+	if f.kind == acquisitionFeed {
+		publications := make([]publication, 0, len(f.books))
+
+		for _, book := range f.books {
+			publications = append(publications, j.publication(book))
+		}
+
+		result.Publications = &publications
+		result.Metadata.CurrentPage = f.page
+		result.Metadata.ItemsPerPage = f.pageSize
+	}
+
+	w.Header().Set("Content-Type", opds2Type)
+
+	return json.NewEncoder(w).Encode(result)
+}
+
+func (jsonRenderer) publication(book entity.BookRecord) publication {
+	result := publication{
+		Metadata: publicationMetadata{
+			Type:       publicationType,
+			Title:      book.Name,
+			Identifier: "urn:uuid:" + book.ID,
+			Publisher:  book.Publisher,
+			Modified:   book.UpdatedAt.UTC(),
+		},
+		Links: []opds2Link{{Rel: relBorrow, Href: bookInfoPath + book.ID, Type: bookInfoType}},
+	}
+
+	if book.ISBN != "" {
+		result.Metadata.Identifier = "urn:isbn:" + book.ISBN
+	}
+
+	for i, name := range book.Authors {
+		result.Metadata.Author = append(result.Metadata.Author, contributor{
+			Name:  name,
+			Links: []opds2Link{{Href: V2Prefix + "/authors/" + book.AuthorIDs[i], Type: opds2Type}},
+		})
+	}
+
+	return result
+}
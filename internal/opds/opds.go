@@ -0,0 +1,304 @@
+// Package opds serves the catalog to e-reader apps as OPDS 1.2 (Atom) and
+// OPDS 2.0 (JSON) feeds: a navigation root, the newest books, the authors
+// with their books and an OpenSearch search, all paginated.
+package opds
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/library"
+	"go.uber.org/zap"
+)
+
+const (
+	// Prefix is the path of the OPDS 1.2 feeds, V2Prefix of the OPDS 2.0
+	// ones.
+	Prefix   = "/opds"
+	V2Prefix = "/opds/v2"
+
+	// DefaultPageSize is the number of entries in a feed page.
+	DefaultPageSize = 20
+
+	// bookInfoPath is the REST resource the acquisition links point to.
+	bookInfoPath = "/v1/books/"
+)
+
+type (
+	Option func(options *options)
+
+	options struct {
+		title    string
+		pageSize int
+		now      func() time.Time
+	}
+)
+
+// WithTitle sets the title of the root feed and of the OpenSearch
+// description.
+func WithTitle(title string) Option {
+	return func(options *options) {
+		if title != "" {
+			options.title = title
+		}
+	}
+}
+
+// WithPageSize sets the number of entries per feed page.
+func WithPageSize(pageSize int) Option {
+	return func(options *options) {
+		if pageSize > 0 {
+			options.pageSize = pageSize
+		}
+	}
+}
+
+// WithClock sets the source of the feed update times.
+func WithClock(now func() time.Time) Option {
+	return func(options *options) {
+		options.now = now
+	}
+}
+
+type handler struct {
+	logger  *zap.Logger
+	books   library.BooksUseCase
+	authors library.AuthorUseCase
+	options
+}
+
+func New(logger *zap.Logger, books library.BooksUseCase, authors library.AuthorUseCase, opts ...Option) http.Handler {
+	h := &handler{
+		logger:  logger,
+		books:   books,
+		authors: authors,
+		options: options{title: "Library", pageSize: DefaultPageSize, now: time.Now},
+	}
+
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+
+	mux := http.NewServeMux()
+
+	for _, r := range []renderer{atomRenderer{}, jsonRenderer{}} {
+		mux.HandleFunc("GET "+r.prefix(), h.serve(r, h.root))
+		mux.HandleFunc("GET "+r.prefix()+"/new", h.serve(r, h.newest))
+		mux.HandleFunc("GET "+r.prefix()+"/authors", h.serve(r, h.authorList))
+		mux.HandleFunc("GET "+r.prefix()+"/authors/{id}", h.serve(r, h.authorBooks))
+		mux.HandleFunc("GET "+r.prefix()+"/search", h.serve(r, h.search))
+	}
+
+	mux.HandleFunc("GET "+openSearchPath, h.openSearch)
+
+	return mux
+}
+
+type feedKind int
+
+const (
+	navigationFeed feedKind = iota
+	acquisitionFeed
+)
+
+// feed is what the renderers turn into Atom or JSON. Paths are relative to
+// the prefix of the renderer.
+type feed struct {
+	id    string
+	title string
+	// author is the catalog itself, the author of the feed.
+	author string
+	kind   feedKind
+	path   string
+	// query is kept in the links to the other pages.
+	query    url.Values
+	page     int
+	pageSize int
+	more     bool
+
+	navigation []navigationEntry
+	books      []entity.BookRecord
+}
+
+type navigationEntry struct {
+	id      string
+	title   string
+	content string
+	path    string
+	kind    feedKind
+	rel     string
+}
+
+// pagePath returns the path of another page of the feed.
+func (f feed) pagePath(page int) string {
+	query := url.Values{}
+
+	for key, values := range f.query {
+		query[key] = values
+	}
+
+	if page > 1 {
+		query.Set("page", strconv.Itoa(page))
+	}
+
+	if len(query) == 0 {
+		return f.path
+	}
+
+	return f.path + "?" + query.Encode()
+}
+
+type builder func(r renderer, req *http.Request, page int) (feed, error)
+
+var errBadPage = errors.New("page must be a positive number")
+
+func (h *handler) serve(r renderer, build builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		page := 1
+
+		if value := req.URL.Query().Get("page"); value != "" {
+			var err error
+
+			if page, err = strconv.Atoi(value); err != nil || page < 1 {
+				http.Error(w, errBadPage.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		f, err := build(r, req, page)
+
+		switch {
+		case errors.Is(err, entity.ErrAuthorNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			h.logger.Error("can not build OPDS feed", zap.String("path", req.URL.Path), zap.Error(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		f.author = h.title
+		f.page = page
+		f.pageSize = h.pageSize
+
+		if err := r.render(w, f, h.now()); err != nil {
+			h.logger.Error("can not write OPDS feed", zap.String("path", req.URL.Path), zap.Error(err))
+		}
+	}
+}
+
+func (h *handler) root(_ renderer, _ *http.Request, _ int) (feed, error) {
+	return feed{
+		id:    "urn:library:opds:root",
+		title: h.title,
+		kind:  navigationFeed,
+		navigation: []navigationEntry{
+			{
+				id:      "urn:library:opds:new",
+				title:   "Newest books",
+				content: "The books added last",
+				path:    "/new",
+				kind:    acquisitionFeed,
+				rel:     relSortNew,
+			},
+			{
+				id:      "urn:library:opds:authors",
+				title:   "Authors",
+				content: "The books by author",
+				path:    "/authors",
+				kind:    navigationFeed,
+				rel:     relSubsection,
+			},
+		},
+	}, nil
+}
+
+func (h *handler) newest(_ renderer, req *http.Request, page int) (feed, error) {
+	return h.bookFeed(req, page, feed{id: "urn:library:opds:new", title: "Newest books", path: "/new"}, entity.BookFilter{})
+}
+
+func (h *handler) search(r renderer, req *http.Request, page int) (feed, error) {
+	query := req.URL.Query().Get(r.searchParameter())
+	result := feed{
+		id:    "urn:library:opds:search",
+		title: "Search: " + query,
+		path:  "/search",
+		query: url.Values{r.searchParameter(): {query}},
+	}
+
+	return h.bookFeed(req, page, result, entity.BookFilter{Query: query})
+}
+
+func (h *handler) authorBooks(_ renderer, req *http.Request, page int) (feed, error) {
+	id := req.PathValue("id")
+
+	// No author has an ID that isn't a UUID; the database would refuse it.
+	if _, err := uuid.Parse(id); err != nil {
+		return feed{}, entity.ErrAuthorNotFound
+	}
+
+	author, err := h.authors.GetAuthorInfo(req.Context(), id)
+
+	if err != nil {
+		return feed{}, err
+	}
+
+	result := feed{
+		id:    "urn:uuid:" + author.ID,
+		title: author.Name,
+		path:  "/authors/" + author.ID,
+	}
+
+	return h.bookFeed(req, page, result, entity.BookFilter{AuthorID: author.ID})
+}
+
+func (h *handler) bookFeed(req *http.Request, page int, result feed, filter entity.BookFilter) (feed, error) {
+	filter.Offset = (page - 1) * h.pageSize
+	filter.Limit = h.pageSize + 1
+
+	books, err := h.books.ListBooks(req.Context(), filter)
+
+	if err != nil {
+		return feed{}, err
+	}
+
+	result.kind = acquisitionFeed
+	result.more = len(books) > h.pageSize
+	result.books = books[:min(len(books), h.pageSize)]
+
+	return result, nil
+}
+
+func (h *handler) authorList(_ renderer, req *http.Request, page int) (feed, error) {
+	authors, err := h.authors.ListAuthors(req.Context(), (page-1)*h.pageSize, h.pageSize+1)
+
+	if err != nil {
+		return feed{}, err
+	}
+
+	result := feed{
+		id:    "urn:library:opds:authors",
+		title: "Authors",
+		kind:  navigationFeed,
+		path:  "/authors",
+		more:  len(authors) > h.pageSize,
+	}
+
+	for _, author := range authors[:min(len(authors), h.pageSize)] {
+		result.navigation = append(result.navigation, navigationEntry{
+			id:    "urn:uuid:" + author.ID,
+			title: author.Name,
+			path:  "/authors/" + author.ID,
+			kind:  acquisitionFeed,
+			rel:   relSubsection,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,237 @@
+package opds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project/library/generated/mocks"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	authorID = "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5a"
+	bookID   = "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5b"
+)
+
+var (
+	now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	book = entity.BookRecord{
+		Book: entity.Book{
+			ID:        bookID,
+			Name:      "War and Peace",
+			AuthorIDs: []string{authorID},
+			ISBN:      "9780140447934",
+			Publisher: "Penguin Classics",
+			UpdatedAt: now.Add(-time.Hour),
+		},
+		Authors: []string{"Leo Tolstoy"},
+	}
+	otherBook = entity.BookRecord{Book: entity.Book{ID: "0195f1f4-4b7c-7d2e-9c1a-3f1e2d3c4b5c", Name: "Anna Karenina"}}
+	author    = entity.Author{ID: authorID, Name: "Leo Tolstoy"}
+)
+
+func newHandler(t *testing.T) (http.Handler, *mocks.MockBooksUseCase, *mocks.MockAuthorUseCase) {
+	t.Helper()
+
+	control := gomock.NewController(t)
+	books := mocks.NewMockBooksUseCase(control)
+	authors := mocks.NewMockAuthorUseCase(control)
+
+	return New(zaptest.NewLogger(t), books, authors, WithTitle("Test Library"), WithPageSize(1), WithClock(func() time.Time { return now })), books, authors
+}
+
+func get(t *testing.T, handler http.Handler, target string, status int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, status, recorder.Code, recorder.Body.String())
+
+	return recorder
+}
+
+type parsedLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type parsedFeed struct {
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Author  string       `xml:"author>name"`
+	Links   []parsedLink `xml:"link"`
+	Entries []struct {
+		Title      string       `xml:"title"`
+		ID         string       `xml:"id"`
+		Authors    []string     `xml:"author>name"`
+		Identifier string       `xml:"identifier"`
+		Publisher  string       `xml:"publisher"`
+		Links      []parsedLink `xml:"link"`
+	} `xml:"entry"`
+}
+
+func parseAtom(t *testing.T, recorder *httptest.ResponseRecorder) parsedFeed {
+	t.Helper()
+
+	var result parsedFeed
+	require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &result), recorder.Body.String())
+
+	return result
+}
+
+func links(links []parsedLink) map[string]string {
+	result := make(map[string]string)
+
+	for _, link := range links {
+		result[link.Rel] = link.Href
+	}
+
+	return result
+}
+
+func TestRoot(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _ := newHandler(t)
+
+	recorder := get(t, handler, "/opds", http.StatusOK)
+	require.Equal(t, navigationType+";charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	feed := parseAtom(t, recorder)
+	require.Equal(t, "Test Library", feed.Title)
+	require.Equal(t, "Test Library", feed.Author)
+	require.Equal(t, "2026-03-01T12:00:00Z", feed.Updated)
+	require.Equal(t, openSearchPath, links(feed.Links)[relSearch])
+	require.Len(t, feed.Entries, 2)
+	require.Equal(t, map[string]string{relSortNew: "/opds/new"}, links(feed.Entries[0].Links))
+	require.Equal(t, map[string]string{relSubsection: "/opds/authors"}, links(feed.Entries[1].Links))
+
+	recorder = get(t, handler, "/opds/v2", http.StatusOK)
+	require.Equal(t, opds2Type, recorder.Header().Get("Content-Type"))
+
+	var v2 opds2Feed
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &v2))
+	require.Nil(t, v2.Publications)
+	require.Len(t, v2.Navigation, 2)
+	require.Equal(t, "/opds/v2/new", v2.Navigation[0].Href)
+}
+
+func TestNewest(t *testing.T) {
+	t.Parallel()
+
+	handler, books, _ := newHandler(t)
+	books.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{Limit: 2}).Return([]entity.BookRecord{book, otherBook}, nil)
+	books.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{Offset: 1, Limit: 2}).Return([]entity.BookRecord{otherBook}, nil)
+
+	feed := parseAtom(t, get(t, handler, "/opds/new", http.StatusOK))
+	require.Len(t, feed.Entries, 1)
+
+	entry := feed.Entries[0]
+	require.Equal(t, "War and Peace", entry.Title)
+	require.Equal(t, "urn:uuid:"+bookID, entry.ID)
+	require.Equal(t, []string{"Leo Tolstoy"}, entry.Authors)
+	require.Equal(t, "urn:isbn:9780140447934", entry.Identifier)
+	require.Equal(t, "Penguin Classics", entry.Publisher)
+	require.Equal(t, "/v1/books/"+bookID, links(entry.Links)[relBorrow])
+	require.Equal(t, "/opds/authors/"+authorID, links(entry.Links)[relRelated])
+
+	require.Equal(t, map[string]string{
+		relSelf:   "/opds/new",
+		relStart:  "/opds",
+		relSearch: openSearchPath,
+		relNext:   "/opds/new?page=2",
+	}, links(feed.Links))
+
+	feed = parseAtom(t, get(t, handler, "/opds/new?page=2", http.StatusOK))
+	require.Len(t, feed.Entries, 1)
+	require.Equal(t, map[string]string{
+		relSelf:     "/opds/new?page=2",
+		relStart:    "/opds",
+		relSearch:   openSearchPath,
+		relFirst:    "/opds/new",
+		relPrevious: "/opds/new",
+	}, links(feed.Links))
+}
+
+func TestSearchV2(t *testing.T) {
+	t.Parallel()
+
+	handler, books, _ := newHandler(t)
+	books.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{Query: "war", Limit: 2}).Return([]entity.BookRecord{book, otherBook}, nil)
+	books.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{Query: "nothing", Limit: 2}).Return(nil, nil)
+
+	var v2 opds2Feed
+	require.NoError(t, json.Unmarshal(get(t, handler, "/opds/v2/search?query=war", http.StatusOK).Body.Bytes(), &v2))
+	require.NotNil(t, v2.Publications)
+	require.Len(t, *v2.Publications, 1)
+
+	publication := (*v2.Publications)[0]
+	require.Equal(t, publicationType, publication.Metadata.Type)
+	require.Equal(t, "urn:isbn:9780140447934", publication.Metadata.Identifier)
+	require.Equal(t, "Leo Tolstoy", publication.Metadata.Author[0].Name)
+	require.Equal(t, "/opds/v2/authors/"+authorID, publication.Metadata.Author[0].Links[0].Href)
+	require.Equal(t, relBorrow, publication.Links[0].Rel)
+	require.Equal(t, 1, v2.Metadata.ItemsPerPage)
+
+	var next string
+
+	for _, link := range v2.Links {
+		if link.Rel == relNext {
+			next = link.Href
+		}
+	}
+
+	require.Equal(t, "/opds/v2/search?page=2&query=war", next)
+
+	body := get(t, handler, "/opds/v2/search?query=nothing", http.StatusOK).Body.String()
+	require.Contains(t, body, `"publications":[]`)
+}
+
+func TestAuthors(t *testing.T) {
+	t.Parallel()
+
+	unknownID := uuid.NewString()
+
+	handler, books, authors := newHandler(t)
+	authors.EXPECT().ListAuthors(gomock.Any(), 0, 2).Return([]entity.Author{author}, nil)
+	authors.EXPECT().GetAuthorInfo(gomock.Any(), authorID).Return(author, nil)
+	authors.EXPECT().GetAuthorInfo(gomock.Any(), unknownID).Return(entity.Author{}, entity.ErrAuthorNotFound)
+	books.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{AuthorID: authorID, Limit: 2}).Return([]entity.BookRecord{book}, nil)
+
+	feed := parseAtom(t, get(t, handler, "/opds/authors", http.StatusOK))
+	require.Len(t, feed.Entries, 1)
+	require.Equal(t, "Leo Tolstoy", feed.Entries[0].Title)
+	require.Equal(t, "/opds/authors/"+authorID, links(feed.Entries[0].Links)[relSubsection])
+	require.NotContains(t, links(feed.Links), relNext)
+
+	feed = parseAtom(t, get(t, handler, "/opds/authors/"+authorID, http.StatusOK))
+	require.Equal(t, "Leo Tolstoy", feed.Title)
+	require.Equal(t, "urn:uuid:"+authorID, feed.ID)
+	require.Len(t, feed.Entries, 1)
+
+	get(t, handler, "/opds/authors/"+unknownID, http.StatusNotFound)
+	get(t, handler, "/opds/authors/unknown", http.StatusNotFound)
+	get(t, handler, "/opds/new?page=0", http.StatusBadRequest)
+}
+
+func TestOpenSearch(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _ := newHandler(t)
+
+	var description openSearchDescription
+	require.NoError(t, xml.Unmarshal(get(t, handler, openSearchPath, http.StatusOK).Body.Bytes(), &description))
+	require.Equal(t, "Test Library", description.ShortName)
+	require.Equal(t, "http://example.com/opds/search?q={searchTerms}", description.URL.Template)
+}
@@ -0,0 +1,57 @@
+package opds
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	openSearchPath = Prefix + "/opensearch.xml"
+	openSearchType = "application/opensearchdescription+xml"
+)
+
+type openSearchDescription struct {
+	XMLName     xml.Name      `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName   string        `xml:"ShortName"`
+	Description string        `xml:"Description"`
+	URL         openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// openSearch describes the search of the OPDS 1.2 feeds. OpenSearch wants
+// an absolute template, so it is built from the request.
+func (h *handler) openSearch(w http.ResponseWriter, req *http.Request) {
+	scheme := "http"
+
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	description := openSearchDescription{
+		ShortName:   h.title,
+		Description: "Search the books by title and author",
+		URL: openSearchURL{
+			Type:     acquisitionType,
+			Template: scheme + "://" + req.Host + Prefix + "/search?q={searchTerms}",
+		},
+	}
+
+	w.Header().Set("Content-Type", openSearchType+";charset=utf-8")
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(description); err != nil {
+		h.logger.Error("can not write OpenSearch description", zap.Error(err))
+	}
+}
@@ -0,0 +1,107 @@
+// Package problem renders the gRPC errors the gateway receives as RFC 9457
+// problem documents, with the details of the status as extension members.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+const ContentType = "application/problem+json"
+
+// TypePrefix precedes the ErrorInfo reason in the type of a problem. A
+// problem without a reason has the type "about:blank".
+const TypePrefix = "urn:library:problem:"
+
+// Document is a problem document. Code is the gRPC status code; Reason,
+// Domain and Metadata come from ErrorInfo, Resource from ResourceInfo,
+// Violations from BadRequest and RetryAfter, in seconds, from RetryInfo.
+type Document struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail,omitempty"`
+	Instance   string            `json:"instance,omitempty"`
+	Code       string            `json:"code"`
+	Reason     string            `json:"reason,omitempty"`
+	Domain     string            `json:"domain,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Resource   *Resource         `json:"resource,omitempty"`
+	Violations []Violation       `json:"violations,omitempty"`
+	RetryAfter int64             `json:"retry_after,omitempty"`
+}
+
+type Resource struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type Violation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// FromStatus builds the document of a status for the request path.
+func FromStatus(st *status.Status, instance string) Document {
+	httpStatus := runtime.HTTPStatusFromCode(st.Code())
+
+	document := Document{
+		Type:     "about:blank",
+		Title:    http.StatusText(httpStatus),
+		Status:   httpStatus,
+		Detail:   st.Message(),
+		Instance: instance,
+		Code:     st.Code().String(),
+	}
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			document.Type = TypePrefix + detail.GetReason()
+			document.Reason = detail.GetReason()
+			document.Domain = detail.GetDomain()
+			document.Metadata = detail.GetMetadata()
+		case *errdetails.ResourceInfo:
+			document.Resource = &Resource{Type: detail.GetResourceType(), Name: detail.GetResourceName()}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				document.Violations = append(document.Violations, Violation{
+					Field:       violation.GetField(),
+					Description: violation.GetDescription(),
+					Reason:      violation.GetReason(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			// Retry-After counts whole seconds; round up so a client
+			// doesn't come back too early.
+			document.RetryAfter = int64(math.Ceil(detail.GetRetryDelay().AsDuration().Seconds()))
+		}
+	}
+
+	return document
+}
+
+// ErrorHandler is a runtime.ErrorHandlerFunc writing problem documents. It
+// sets Retry-After when the status has RetryInfo.
+func ErrorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	document := FromStatus(status.Convert(err), r.URL.Path)
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", ContentType)
+
+	if document.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(document.RetryAfter, 10))
+	}
+
+	w.WriteHeader(document.Status)
+	_ = json.NewEncoder(w).Encode(document)
+}
@@ -0,0 +1,104 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func render(t *testing.T, err error) (*httptest.ResponseRecorder, Document) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	ErrorHandler(t.Context(), nil, nil, recorder, httptest.NewRequest(http.MethodGet, "/v1/books/42", nil), err)
+
+	var document Document
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+
+	return recorder, document
+}
+
+func TestErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		st, err := status.New(codes.NotFound, "book not found").WithDetails(
+			&errdetails.ResourceInfo{ResourceType: "library.Book", ResourceName: "42"},
+			&errdetails.ErrorInfo{Reason: "BOOK_NOT_FOUND", Domain: "library"},
+		)
+		require.NoError(t, err)
+
+		recorder, document := render(t, st.Err())
+
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+		require.Equal(t, Document{
+			Type:     TypePrefix + "BOOK_NOT_FOUND",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "book not found",
+			Instance: "/v1/books/42",
+			Code:     "NotFound",
+			Reason:   "BOOK_NOT_FOUND",
+			Domain:   "library",
+			Resource: &Resource{Type: "library.Book", Name: "42"},
+		}, document)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+
+		st, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "name", Description: "value length must be at least 1 runes"},
+				{Field: "author_ids[0]", Description: "value must be a valid UUID"},
+			},
+		})
+		require.NoError(t, err)
+
+		recorder, document := render(t, st.Err())
+
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, "about:blank", document.Type)
+		require.Equal(t, []Violation{
+			{Field: "name", Description: "value length must be at least 1 runes"},
+			{Field: "author_ids[0]", Description: "value must be a valid UUID"},
+		}, document.Violations)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		st, err := status.New(codes.Unavailable, "storage is temporarily unavailable").WithDetails(
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(1500 * time.Millisecond)},
+		)
+		require.NoError(t, err)
+
+		recorder, document := render(t, st.Err())
+
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		require.Equal(t, "2", recorder.Header().Get("Retry-After"))
+		require.Equal(t, int64(2), document.RetryAfter)
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		t.Parallel()
+
+		recorder, document := render(t, errors.New("connection refused"))
+
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		require.Equal(t, "Unknown", document.Code)
+		require.Equal(t, "connection refused", document.Detail)
+	})
+}
@@ -0,0 +1,224 @@
+// Package tlsconfig builds the TLS configurations of the servers and the
+// clients. Certificates are read from disk and reloaded when the files
+// change, so a renewed certificate is picked up without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often at most the certificate files are
+// checked for changes.
+const DefaultReloadInterval = time.Minute
+
+var ErrNoCertificates = errors.New("no certificates found")
+
+// Reloader serves a certificate and key pair and rereads the files once they
+// change. A pair that fails to load leaves the previous one in use.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	checkedAt   time.Time
+}
+
+// NewReloader loads the pair and fails if it can't.
+func NewReloader(certFile string, keyFile string, interval time.Duration) (*Reloader, error) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		now:      time.Now,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *Reloader) current() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checkedAt) >= r.interval {
+		r.checkedAt = now
+
+		if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+			_ = r.loadLocked()
+		}
+	}
+
+	return r.certificate
+}
+
+func (r *Reloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkedAt = r.now()
+
+	return r.loadLocked()
+}
+
+func (r *Reloader) loadLocked() error {
+	modTime, err := r.latestModTime()
+
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return fmt.Errorf("can not load certificate %s: %w", r.certFile, err)
+	}
+
+	r.certificate = &certificate
+	r.modTime = modTime
+
+	return nil
+}
+
+// latestModTime covers both files: the key may be replaced after the
+// certificate.
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// LoadCertPool reads PEM certificates into a pool.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w in %s", ErrNoCertificates, path)
+	}
+
+	return pool, nil
+}
+
+type ServerOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile turns on the verification of client certificates
+	// against the CAs in the file; RequireClientCert rejects clients
+	// without one.
+	ClientCAFile      string
+	RequireClientCert bool
+	ReloadInterval    time.Duration
+}
+
+// Server returns nil when no certificate is configured: the listener stays
+// plaintext.
+func Server(options ServerOptions) (*tls.Config, error) {
+	if options.CertFile == "" {
+		return nil, nil
+	}
+
+	reloader, err := NewReloader(options.CertFile, options.KeyFile, options.ReloadInterval)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if options.ClientCAFile != "" {
+		if config.ClientCAs, err = LoadCertPool(options.ClientCAFile); err != nil {
+			return nil, err
+		}
+
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+
+		if options.RequireClientCert {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return config, nil
+}
+
+type ClientOptions struct {
+	// CAFile replaces the system roots the server is verified against.
+	CAFile     string
+	ServerName string
+	// CertFile and KeyFile are the client certificate for servers that
+	// verify clients.
+	CertFile       string
+	KeyFile        string
+	ReloadInterval time.Duration
+}
+
+func Client(options ClientOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: options.ServerName,
+	}
+
+	var err error
+
+	if options.CAFile != "" {
+		if config.RootCAs, err = LoadCertPool(options.CAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.CertFile != "" {
+		reloader, err := NewReloader(options.CertFile, options.KeyFile, options.ReloadInterval)
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return config, nil
+}
@@ -0,0 +1,308 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type authority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T) authority {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return authority{certificate: certificate, key: key}
+}
+
+// writeCA writes the certificate of the authority and returns the path.
+func (a authority) writeCA(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "ca.pem")
+	writePEM(t, path, "CERTIFICATE", a.certificate.Raw)
+
+	return path
+}
+
+// issue writes a certificate for name signed by the authority and returns
+// the paths of the certificate and the key.
+func (a authority) issue(t *testing.T, dir string, name string, serial int64) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func serial(t *testing.T, certificate *tls.Certificate) int64 {
+	t.Helper()
+
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+
+	return parsed.SerialNumber.Int64()
+}
+
+// handshake runs a TLS handshake over a loopback connection; net.Pipe is
+// unbuffered and deadlocks when both sides write an alert.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) error {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			serverErr <- err
+			return
+		}
+
+		defer conn.Close()
+
+		err = conn.(*tls.Conn).Handshake()
+
+		if err == nil {
+			// The client learns about a rejected certificate on its first
+			// read only.
+			_, err = conn.Write([]byte{1})
+		}
+
+		serverErr <- err
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), client)
+
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+
+	if serverErr := <-serverErr; err == nil {
+		err = serverErr
+	}
+
+	return err
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	caFile := ca.writeCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "localhost", 2)
+	clientCert, clientKey := ca.issue(t, dir, "client", 3)
+
+	t.Run("plaintext without a certificate", func(t *testing.T) {
+		t.Parallel()
+
+		config, err := Server(ServerOptions{})
+		require.NoError(t, err)
+		require.Nil(t, config)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Server(ServerOptions{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile})
+		require.Error(t, err)
+	})
+
+	t.Run("server certificate only", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := Server(ServerOptions{CertFile: certFile, KeyFile: keyFile})
+		require.NoError(t, err)
+		require.Equal(t, tls.NoClientCert, server.ClientAuth)
+
+		client, err := Client(ClientOptions{CAFile: caFile, ServerName: "localhost"})
+		require.NoError(t, err)
+		require.NoError(t, handshake(t, server, client))
+
+		client, err = Client(ClientOptions{CAFile: caFile, ServerName: "library.example"})
+		require.NoError(t, err)
+		require.Error(t, handshake(t, server, client))
+	})
+
+	t.Run("optional client certificate", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := Server(ServerOptions{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+		require.NoError(t, err)
+		require.Equal(t, tls.VerifyClientCertIfGiven, server.ClientAuth)
+
+		client, err := Client(ClientOptions{CAFile: caFile, ServerName: "localhost"})
+		require.NoError(t, err)
+		require.NoError(t, handshake(t, server, client))
+	})
+
+	t.Run("required client certificate", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := Server(ServerOptions{
+			CertFile:          certFile,
+			KeyFile:           keyFile,
+			ClientCAFile:      caFile,
+			RequireClientCert: true,
+		})
+		require.NoError(t, err)
+
+		client, err := Client(ClientOptions{CAFile: caFile, ServerName: "localhost"})
+		require.NoError(t, err)
+		require.Error(t, handshake(t, server, client))
+
+		client, err = Client(ClientOptions{CAFile: caFile, ServerName: "localhost", CertFile: clientCert, KeyFile: clientKey})
+		require.NoError(t, err)
+		require.NoError(t, handshake(t, server, client))
+	})
+
+	t.Run("client certificate from another CA", func(t *testing.T) {
+		t.Parallel()
+
+		otherDir := t.TempDir()
+		otherCert, otherKey := newAuthority(t).issue(t, otherDir, "client", 4)
+
+		server, err := Server(ServerOptions{
+			CertFile:          certFile,
+			KeyFile:           keyFile,
+			ClientCAFile:      caFile,
+			RequireClientCert: true,
+		})
+		require.NoError(t, err)
+
+		client, err := Client(ClientOptions{CAFile: caFile, ServerName: "localhost", CertFile: otherCert, KeyFile: otherKey})
+		require.NoError(t, err)
+		require.Error(t, handshake(t, server, client))
+	})
+}
+
+func TestReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	certFile, keyFile := ca.issue(t, dir, "localhost", 2)
+
+	reloader, err := NewReloader(certFile, keyFile, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+
+	current, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), serial(t, current))
+
+	// Renew the certificate with a later modification time.
+	ca.issue(t, dir, "localhost", 3)
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	current, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), serial(t, current), "checked again before the interval")
+
+	now = now.Add(time.Minute)
+
+	current, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), serial(t, current))
+
+	// A broken renewal keeps the previous certificate.
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	broken := later.Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, broken, broken))
+
+	now = now.Add(time.Minute)
+
+	current, err = reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), serial(t, current))
+}
+
+func TestLoadCertPool(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	_, err := LoadCertPool(empty)
+	require.ErrorIs(t, err, ErrNoCertificates)
+
+	_, err = LoadCertPool(filepath.Join(dir, "missing.pem"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	pool, err := LoadCertPool(newAuthority(t).writeCA(t, dir))
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+}
@@ -9,33 +9,45 @@ import (
 	"github.com/project/library/internal/entity"
 )
 
-func (l *libraryImpl) RegisterAuthor(ctx context.Context, authorName string) (entity.Author, error) {
+func (l *libraryImpl) RegisterAuthor(ctx context.Context, authorID string, authorName string) (entity.Author, error) {
+	authorName = entity.CanonicalAuthorName(authorName)
+	request := entity.Author{ID: authorID, Name: authorName}
+
+	authorID, err := l.newID(authorID)
+
+	if err != nil {
+		return entity.Author{}, err
+	}
+
 	var author entity.Author
 
-	err := l.transactor.WithTx(ctx, func(ctx context.Context) error {
-		var txErr error
-		author, txErr = l.authorRepository.CreateAuthor(ctx, entity.Author{
-			Name: authorName,
-		})
+	err = l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		return l.idempotent(ctx, idempotencyMethodRegisterAuthor, request, &author, func(ctx context.Context) error {
+			var txErr error
+			author, txErr = l.authorRepository.CreateAuthor(ctx, entity.Author{
+				ID:   authorID,
+				Name: authorName,
+			})
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		serialized, txErr := json.Marshal(author)
+			serialized, txErr := json.Marshal(author)
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		idempotencyKey := repository.OutboxKindAuthor.String() + "_" + author.ID
-		txErr = l.outboxRepository.SendMessage(ctx, idempotencyKey, repository.OutboxKindAuthor, serialized)
+			idempotencyKey := repository.OutboxKindAuthor.String() + "_" + author.ID
+			txErr = l.outboxRepository.SendMessage(ctx, idempotencyKey, repository.OutboxKindAuthor, serialized)
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		return nil
+			return nil
+		})
 	})
 
 	if err != nil {
@@ -45,17 +57,32 @@ func (l *libraryImpl) RegisterAuthor(ctx context.Context, authorName string) (en
 	return author, nil
 }
 
-func (l *libraryImpl) UpdateAuthor(ctx context.Context, authorID string, authorName string) error {
-	err := l.authorRepository.UpdateAuthor(ctx, entity.Author{
-		ID:   authorID,
-		Name: authorName,
-	})
+func (l *libraryImpl) UpdateAuthor(ctx context.Context, authorID string, authorName string, profile *entity.AuthorProfile) error {
+	if profile == nil {
+		return l.authorRepository.UpdateAuthor(ctx, entity.Author{
+			ID:   authorID,
+			Name: entity.CanonicalAuthorName(authorName),
+		})
+	}
+
+	normalized, err := profile.Normalize(authorID)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		txErr := l.authorRepository.UpdateAuthor(ctx, entity.Author{
+			ID:   authorID,
+			Name: entity.CanonicalAuthorName(authorName),
+		})
+
+		if txErr != nil {
+			return txErr
+		}
+
+		return l.authorRepository.UpdateAuthorProfile(ctx, authorID, normalized)
+	})
 }
 
 func (l *libraryImpl) GetAuthorBooks(ctx context.Context, authorID string) ([]entity.Book, error) {
@@ -77,3 +104,22 @@ func (l *libraryImpl) GetAuthorInfo(ctx context.Context, authorID string) (entit
 
 	return author, nil
 }
+
+const (
+	DefaultDuplicateThreshold = 0.6
+	DefaultDuplicateLimit     = 100
+)
+
+// FindDuplicateAuthors lists pairs of authors with similar names, most similar
+// first. Zero threshold and limit fall back to the defaults.
+func (l *libraryImpl) FindDuplicateAuthors(ctx context.Context, threshold float64, limit int) ([]entity.AuthorDuplicate, error) {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	if limit <= 0 {
+		limit = DefaultDuplicateLimit
+	}
+
+	return l.authorRepository.FindDuplicateAuthors(ctx, threshold, limit)
+}
@@ -12,39 +12,51 @@ import (
 	"github.com/project/library/internal/entity"
 )
 
-func (l *libraryImpl) RegisterBook(ctx context.Context, name string, authorIDs []string) (entity.Book, error) {
+func (l *libraryImpl) RegisterBook(ctx context.Context, bookID string, name string, authorIDs []string) (entity.Book, error) {
 	span := trace.SpanFromContext(ctx)
 	l.logger.Info("start to register book",
 		zap.String("trace_id", span.SpanContext().TraceID().String()),
 	)
 
+	request := entity.Book{ID: bookID, Name: name, AuthorIDs: authorIDs}
+
+	bookID, err := l.newID(bookID)
+
+	if err != nil {
+		span.RecordError(err)
+		return entity.Book{}, err
+	}
+
 	var book entity.Book
 
-	err := l.transactor.WithTx(ctx, func(ctx context.Context) error {
-		var txErr error
-		book, txErr = l.booksRepository.CreateBook(ctx, entity.Book{
-			Name:      name,
-			AuthorIDs: authorIDs,
-		})
+	err = l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		return l.idempotent(ctx, idempotencyMethodRegisterBook, request, &book, func(ctx context.Context) error {
+			var txErr error
+			book, txErr = l.booksRepository.CreateBook(ctx, entity.Book{
+				ID:        bookID,
+				Name:      name,
+				AuthorIDs: authorIDs,
+			})
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		serialized, txErr := json.Marshal(book)
+			serialized, txErr := json.Marshal(book)
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		idempotencyKey := repository.OutboxKindBook.String() + "_" + book.ID
-		txErr = l.outboxRepository.SendMessage(ctx, idempotencyKey, repository.OutboxKindBook, serialized)
+			idempotencyKey := repository.OutboxKindBook.String() + "_" + book.ID
+			txErr = l.outboxRepository.SendMessage(ctx, idempotencyKey, repository.OutboxKindBook, serialized)
 
-		if txErr != nil {
-			return txErr
-		}
+			if txErr != nil {
+				return txErr
+			}
 
-		return nil
+			return nil
+		})
 	})
 
 	if err != nil {
@@ -0,0 +1,27 @@
+package library
+
+import (
+	"context"
+
+	"github.com/project/library/internal/entity"
+)
+
+// DefaultBrowseLimit is used by ListBooks and ListAuthors when the limit is
+// zero.
+const DefaultBrowseLimit = 50
+
+func (l *libraryImpl) ListBooks(ctx context.Context, filter entity.BookFilter) ([]entity.BookRecord, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultBrowseLimit
+	}
+
+	return l.catalogRepository.ListBooks(ctx, filter)
+}
+
+func (l *libraryImpl) ListAuthors(ctx context.Context, offset int, limit int) ([]entity.Author, error) {
+	if limit <= 0 {
+		limit = DefaultBrowseLimit
+	}
+
+	return l.catalogRepository.ListAuthors(ctx, offset, limit)
+}
@@ -0,0 +1,46 @@
+package library
+
+import (
+	"context"
+
+	"github.com/project/library/internal/citation"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+func (l *libraryImpl) GetBookCitation(ctx context.Context, bookID string, format entity.CitationFormat) ([]byte, error) {
+	var (
+		book    entity.Book
+		authors []entity.Author
+	)
+
+	err := l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var txErr error
+
+		book, txErr = l.booksRepository.GetBook(ctx, bookID)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		authors = make([]entity.Author, 0, len(book.AuthorIDs))
+
+		for _, authorID := range book.AuthorIDs {
+			author, txErr := l.authorRepository.GetAuthorInfo(ctx, authorID)
+
+			if txErr != nil {
+				return txErr
+			}
+
+			authors = append(authors, author)
+		}
+
+		return nil
+	}, repository.WithReadOnly())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return citation.Render(format, book, authors)
+}
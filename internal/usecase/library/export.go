@@ -0,0 +1,94 @@
+package library
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/catalog"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func (l *libraryImpl) ExportCatalog(ctx context.Context, options entity.ExportOptions, w io.Writer) error {
+	var compressed *gzip.Writer
+
+	if options.Gzip {
+		compressed = gzip.NewWriter(w)
+		w = compressed
+	}
+
+	writer, err := catalog.NewWriter(options.Format, w)
+
+	if err != nil {
+		return err
+	}
+
+	var authors, books int
+
+	// The writes go to the client, so the transaction can't be re-run.
+	err = l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		names := make(map[string]string)
+
+		txErr := l.catalogRepository.ExportAuthors(ctx, func(author entity.Author) error {
+			names[author.ID] = author.Name
+			authors++
+
+			return writer.Write(catalog.Record{
+				Kind: catalog.RecordKindAuthor,
+				ID:   author.ID,
+				Name: author.Name,
+			})
+		})
+
+		if txErr != nil {
+			return txErr
+		}
+
+		return l.catalogRepository.ExportBooks(ctx, func(book entity.Book) error {
+			authorNames := make([]string, 0, len(book.AuthorIDs))
+
+			for _, authorID := range book.AuthorIDs {
+				authorNames = append(authorNames, names[authorID])
+			}
+
+			books++
+
+			return writer.Write(catalog.Record{
+				Kind:      catalog.RecordKindBook,
+				ID:        book.ID,
+				Name:      book.Name,
+				Authors:   authorNames,
+				AuthorIDs: book.AuthorIDs,
+				ISBN:      book.ISBN,
+				Publisher: book.Publisher,
+			})
+		})
+	}, repository.WithIsolation(pgx.RepeatableRead), repository.WithReadOnly(), repository.WithoutRetry())
+
+	if err != nil {
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	if compressed != nil {
+		if err = compressed.Close(); err != nil {
+			return err
+		}
+	}
+
+	l.logger.Info("catalog exported",
+		zap.String("trace_id", trace.SpanFromContext(ctx).SpanContext().TraceID().String()),
+		zap.String("format", string(options.Format)),
+		zap.Int("authors", authors),
+		zap.Int("books", books),
+	)
+
+	return nil
+}
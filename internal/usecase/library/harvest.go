@@ -0,0 +1,27 @@
+package library
+
+import (
+	"context"
+	"time"
+
+	"github.com/project/library/internal/entity"
+)
+
+// DefaultHarvestLimit is used by ListBookRecords when the limit is zero.
+const DefaultHarvestLimit = 100
+
+func (l *libraryImpl) ListBookRecords(ctx context.Context, query entity.HarvestQuery) ([]entity.BookRecord, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultHarvestLimit
+	}
+
+	return l.catalogRepository.ListBookRecords(ctx, query)
+}
+
+func (l *libraryImpl) GetBookRecord(ctx context.Context, bookID string) (entity.BookRecord, error) {
+	return l.catalogRepository.GetBookRecord(ctx, bookID)
+}
+
+func (l *libraryImpl) EarliestDatestamp(ctx context.Context) (time.Time, error) {
+	return l.catalogRepository.EarliestDatestamp(ctx)
+}
@@ -0,0 +1,37 @@
+package library
+
+import (
+	"context"
+	"time"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+// DefaultHistoryLimit is used by the history listings when limit is zero.
+const DefaultHistoryLimit = 100
+
+// ContextWithActor records actor as the author of the changes made with ctx.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return repository.ContextWithActor(ctx, actor)
+}
+
+func (l *libraryImpl) GetBookHistory(ctx context.Context, bookID string, limit int) ([]entity.HistoryEntry, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	return l.historyRepository.GetBookHistory(ctx, bookID, limit)
+}
+
+func (l *libraryImpl) GetAuthorHistory(ctx context.Context, authorID string, limit int) ([]entity.HistoryEntry, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	return l.historyRepository.GetAuthorHistory(ctx, authorID, limit)
+}
+
+func (l *libraryImpl) GetBookAsOf(ctx context.Context, bookID string, asOf time.Time) (entity.Book, error) {
+	return l.historyRepository.GetBookAsOf(ctx, bookID, asOf)
+}
@@ -0,0 +1,34 @@
+package library
+
+import (
+	"github.com/google/uuid"
+)
+
+var _ IDGenerator = (*uuidV7Generator)(nil)
+
+// uuidV7Generator produces time-ordered IDs, so keys created later sort after
+// keys created earlier in both storage backends.
+type uuidV7Generator struct{}
+
+func NewUUIDv7Generator() *uuidV7Generator {
+	return &uuidV7Generator{}
+}
+
+func (g *uuidV7Generator) NewID() (string, error) {
+	id, err := uuid.NewV7()
+
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// newID keeps a client-supplied ID and generates one otherwise.
+func (l *libraryImpl) newID(supplied string) (string, error) {
+	if supplied != "" {
+		return supplied, nil
+	}
+
+	return l.idGenerator.NewID()
+}
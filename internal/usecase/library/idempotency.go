@@ -0,0 +1,98 @@
+package library
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+const (
+	idempotencyMethodRegisterAuthor = "RegisterAuthor"
+	idempotencyMethodRegisterBook   = "RegisterBook"
+)
+
+type idempotencyKeyInjector struct{}
+
+// ContextWithIdempotencyKey marks the create calls made with ctx as retries of
+// one client request: the first response is stored and replayed afterwards.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, idempotencyKeyInjector{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyInjector{}).(string)
+	return key
+}
+
+// idempotent runs function unless a response for the same key is stored, in
+// which case it is decoded into response instead. It must be called inside a
+// transaction; function fills response on success.
+func (l *libraryImpl) idempotent(
+	ctx context.Context,
+	method string,
+	request any,
+	response any,
+	function func(ctx context.Context) error,
+) error {
+	key := idempotencyKeyFromContext(ctx)
+
+	if key == "" {
+		return function(ctx)
+	}
+
+	requestHash, err := hashRequest(request)
+
+	if err != nil {
+		return err
+	}
+
+	record, err := l.idempotencyRepository.GetResponse(ctx, method, key)
+
+	switch {
+	case err == nil:
+		if record.RequestHash != requestHash {
+			return entity.ErrIdempotencyKeyReused
+		}
+
+		return json.Unmarshal(record.Response, response)
+	case !errors.Is(err, entity.ErrIdempotencyKeyNotFound):
+		return err
+	}
+
+	if err = function(ctx); err != nil {
+		return err
+	}
+
+	serialized, err := json.Marshal(response)
+
+	if err != nil {
+		return err
+	}
+
+	return l.idempotencyRepository.SaveResponse(ctx, repository.IdempotencyRecord{
+		Method:      method,
+		Key:         key,
+		RequestHash: requestHash,
+		Response:    serialized,
+	})
+}
+
+func hashRequest(request any) (string, error) {
+	serialized, err := json.Marshal(request)
+
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,586 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/project/library/internal/catalog"
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultImportBatchSize is the number of rows an import commits at once when
+// the options leave it zero.
+const DefaultImportBatchSize = 1000
+
+type importRow struct {
+	row    int
+	record catalog.Record
+}
+
+// catalogImport is the state of one import carried across its batches.
+type catalogImport struct {
+	options entity.ImportOptions
+	report  entity.ImportReport
+	// authors maps normalized names to the IDs of the authors found or
+	// created so far and authorIDs holds the IDs of both, so a dry run
+	// doesn't count an author twice either.
+	authors   map[string]string
+	authorIDs map[string]struct{}
+	// newAuthors and newAuthorIDs hold the authors the batch being written
+	// has found or created. They join authors and authorIDs once its
+	// transaction commits, so a retried attempt doesn't take the authors of
+	// a rolled back one for written.
+	newAuthors   map[string]string
+	newAuthorIDs map[string]struct{}
+	// unlinkable holds the taken author IDs books can't be linked to: those
+	// of authors in the trash and of merged authors.
+	unlinkable map[string]struct{}
+	// bookIDs holds the book IDs supplied by the rows read so far.
+	bookIDs map[string]struct{}
+	batch   []importRow
+	// lastRow is the last row read, committed the last row of the last batch
+	// written.
+	lastRow   int
+	committed int
+}
+
+func (l *libraryImpl) ImportCatalog(ctx context.Context, options entity.ImportOptions, r io.Reader) (entity.ImportReport, error) {
+	reader, err := catalog.NewReader(options.Format, r)
+
+	if err != nil {
+		return entity.ImportReport{}, err
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultImportBatchSize
+	}
+
+	state := &catalogImport{
+		options:    options,
+		report:     entity.ImportReport{DryRun: options.DryRun, Errors: make([]entity.ImportRowError, 0)},
+		authors:    make(map[string]string),
+		authorIDs:  make(map[string]struct{}),
+		unlinkable: make(map[string]struct{}),
+		bookIDs:    make(map[string]struct{}),
+	}
+
+	if options.ImportID != "" {
+		if state.committed, err = l.catalogRepository.GetImportCheckpoint(ctx, options.ImportID); err != nil {
+			return state.report, err
+		}
+
+		state.report.Checkpoint = state.committed
+	}
+
+	for {
+		record, row, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *catalog.RowError
+
+		if err != nil && !errors.As(err, &rowErr) {
+			return state.report, err
+		}
+
+		state.report.Rows++
+
+		if row <= state.report.Checkpoint {
+			state.report.RowsSkipped++
+			continue
+		}
+
+		state.lastRow = row
+
+		if err == nil {
+			err = state.check(record)
+		}
+
+		if err != nil {
+			state.fail(row, err)
+			continue
+		}
+
+		state.batch = append(state.batch, importRow{row: row, record: record})
+
+		if len(state.batch) >= options.BatchSize {
+			if err := l.importBatch(ctx, state); err != nil {
+				return state.report, err
+			}
+		}
+	}
+
+	if state.lastRow > state.committed {
+		if err := l.importBatch(ctx, state); err != nil {
+			return state.report, err
+		}
+	}
+
+	l.logger.Info("catalog imported",
+		zap.String("trace_id", trace.SpanFromContext(ctx).SpanContext().TraceID().String()),
+		zap.String("import_id", options.ImportID),
+		zap.Bool("dry_run", options.DryRun),
+		zap.Int("rows", state.report.Rows),
+		zap.Int("books", state.report.BooksImported),
+		zap.Int("authors", state.report.AuthorsCreated),
+		zap.Int("errors", len(state.report.Errors)),
+	)
+
+	return state.report, nil
+}
+
+func (s *catalogImport) check(record catalog.Record) error {
+	if err := record.Validate(); err != nil {
+		return err
+	}
+
+	if record.ID == "" || record.IsAuthor() {
+		return nil
+	}
+
+	if _, ok := s.bookIDs[record.ID]; ok {
+		return entity.ErrBookAlreadyExists
+	}
+
+	s.bookIDs[record.ID] = struct{}{}
+
+	return nil
+}
+
+func (s *catalogImport) fail(row int, err error) {
+	s.report.Errors = append(s.report.Errors, entity.ImportRowError{Row: row, Message: err.Error()})
+}
+
+func (s *catalogImport) addAuthor(id string, name string) entity.Author {
+	s.newAuthorIDs[id] = struct{}{}
+
+	if key := entity.NormalizeAuthorName(name); s.authorID(key) == "" {
+		s.newAuthors[key] = id
+	}
+
+	return entity.Author{ID: id, Name: entity.CanonicalAuthorName(name)}
+}
+
+// authorID returns the ID of the author with the normalized name, if the
+// import has found or created one.
+func (s *catalogImport) authorID(key string) string {
+	if id := s.authors[key]; id != "" {
+		return id
+	}
+
+	return s.newAuthors[key]
+}
+
+func (s *catalogImport) hasAuthorID(id string) bool {
+	_, ok := s.authorIDs[id]
+
+	if !ok {
+		_, ok = s.newAuthorIDs[id]
+	}
+
+	return ok
+}
+
+func (s *catalogImport) isUnlinkable(id string) bool {
+	_, ok := s.unlinkable[id]
+	return ok
+}
+
+// resetBatch forgets the authors of an attempt to write the batch.
+func (s *catalogImport) resetBatch() {
+	s.newAuthors = make(map[string]string)
+	s.newAuthorIDs = make(map[string]struct{})
+}
+
+// commitBatch keeps the authors of the batch once it is written.
+func (s *catalogImport) commitBatch() {
+	maps.Copy(s.authors, s.newAuthors)
+	maps.Copy(s.authorIDs, s.newAuthorIDs)
+	s.resetBatch()
+}
+
+// authorNames returns the names the record refers to authors by.
+func authorNames(record catalog.Record) []string {
+	switch {
+	case record.IsAuthor() && record.ID == "":
+		return []string{record.Name}
+	case record.IsAuthor(), len(record.AuthorIDs) > 0:
+		return nil
+	default:
+		return record.Authors
+	}
+}
+
+// authorIDs returns the IDs the record refers to authors by.
+func authorIDs(record catalog.Record) []string {
+	if record.IsAuthor() && record.ID != "" {
+		return []string{record.ID}
+	}
+
+	return record.AuthorIDs
+}
+
+// importBatch writes the batch and the checkpoint in one transaction. A dry
+// run goes through the same lookups and writes nothing.
+func (l *libraryImpl) importBatch(ctx context.Context, state *catalogImport) error {
+	var (
+		authors []entity.Author
+		books   []entity.Book
+		failed  []entity.ImportRowError
+	)
+
+	err := l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		state.resetBatch()
+
+		var txErr error
+		authors, failed, txErr = l.resolveAuthors(ctx, state)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		var failedBooks []entity.ImportRowError
+		books, failedBooks, txErr = l.importBooks(ctx, state, failed)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		failed = append(failed, failedBooks...)
+
+		if state.options.DryRun {
+			return nil
+		}
+
+		if len(authors) > 0 {
+			if authors, txErr = l.catalogRepository.ImportAuthors(ctx, authors); txErr != nil {
+				return txErr
+			}
+		}
+
+		if len(books) > 0 {
+			if books, txErr = l.catalogRepository.ImportBooks(ctx, books); txErr != nil {
+				return txErr
+			}
+		}
+
+		for _, author := range authors {
+			if txErr = l.sendImported(ctx, repository.OutboxKindAuthor, author.ID, author); txErr != nil {
+				return txErr
+			}
+		}
+
+		for _, book := range books {
+			if txErr = l.sendImported(ctx, repository.OutboxKindBook, book.ID, book); txErr != nil {
+				return txErr
+			}
+		}
+
+		if state.options.ImportID == "" {
+			return nil
+		}
+
+		return l.catalogRepository.SaveImportCheckpoint(ctx, state.options.ImportID, state.lastRow)
+	})
+
+	if err != nil {
+		state.resetBatch()
+		return err
+	}
+
+	state.commitBatch()
+	state.report.AuthorsCreated += len(authors)
+	state.report.BooksImported += len(books)
+	state.report.Errors = append(state.report.Errors, failed...)
+	state.committed = state.lastRow
+	state.batch = state.batch[:0]
+
+	if state.options.ImportID != "" && !state.options.DryRun {
+		state.report.Checkpoint = state.lastRow
+	}
+
+	return nil
+}
+
+// resolveAuthors looks up the authors of the batch missing from the cache and
+// returns the ones to create. Authors referenced by ID are created with that
+// ID, the others are found by name or created with a new ID. Books referencing
+// an author in the trash or a merged author fail, and so do the rows supplying
+// the ID of an author whose name is taken when names are unique.
+func (l *libraryImpl) resolveAuthors(ctx context.Context, state *catalogImport) ([]entity.Author, []entity.ImportRowError, error) {
+	keys := make([]string, 0)
+	ids := make([]string, 0)
+
+	for _, row := range state.batch {
+		for _, name := range authorNames(row.record) {
+			if key := entity.NormalizeAuthorName(name); state.authorID(key) == "" {
+				keys = append(keys, key)
+			}
+		}
+
+		for _, id := range authorIDs(row.record) {
+			if !state.hasAuthorID(id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		taken, err := l.catalogRepository.FindAuthorIDs(ctx, uniqueIDs(ids))
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for id, linkable := range taken {
+			state.newAuthorIDs[id] = struct{}{}
+
+			if !linkable {
+				state.unlinkable[id] = struct{}{}
+			}
+		}
+	}
+
+	found := make(map[string]string)
+
+	if len(keys) > 0 {
+		var err error
+
+		if found, err = l.catalogRepository.FindAuthorsByName(ctx, uniqueIDs(keys)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	created := make([]entity.Author, 0)
+	failed := make([]entity.ImportRowError, 0)
+
+	for _, row := range state.batch {
+		record := row.record
+
+		if record.IsAuthor() && record.ID != "" {
+			if state.hasAuthorID(record.ID) {
+				failed = append(failed, entity.ImportRowError{Row: row.row, Message: entity.ErrAuthorAlreadyExists.Error()})
+				continue
+			}
+
+			created = append(created, state.addAuthor(record.ID, record.Name))
+
+			continue
+		}
+
+		if slices.ContainsFunc(record.AuthorIDs, state.isUnlinkable) {
+			failed = append(failed, entity.ImportRowError{Row: row.row, Message: entity.ErrAuthorNotFound.Error()})
+			continue
+		}
+
+		for i, id := range record.AuthorIDs {
+			if !state.hasAuthorID(id) {
+				created = append(created, state.addAuthor(id, record.Authors[i]))
+			}
+		}
+
+		for _, name := range authorNames(record) {
+			key := entity.NormalizeAuthorName(name)
+
+			if state.authorID(key) != "" {
+				continue
+			}
+
+			if id, ok := found[key]; ok {
+				state.newAuthors[key] = id
+				state.newAuthorIDs[id] = struct{}{}
+
+				continue
+			}
+
+			id, err := l.idGenerator.NewID()
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			created = append(created, state.addAuthor(id, name))
+		}
+	}
+
+	if len(created) == 0 {
+		return created, failed, nil
+	}
+
+	clashes, err := l.catalogRepository.FindAuthorNameClashes(ctx, created)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(clashes) > 0 {
+		created, failed = state.dropClashes(created, clashes, failed)
+	}
+
+	return created, failed, nil
+}
+
+// dropClashes drops the authors whose names are taken and fails the rows that
+// supply their IDs. The rows that name them link the author holding the name.
+func (s *catalogImport) dropClashes(created []entity.Author, clashes map[string]string,
+	failed []entity.ImportRowError,
+) ([]entity.Author, []entity.ImportRowError) {
+	for _, author := range created {
+		existingID, ok := clashes[author.ID]
+
+		if !ok {
+			continue
+		}
+
+		if key := entity.NormalizeAuthorName(author.Name); s.newAuthors[key] == author.ID {
+			s.newAuthors[key] = existingID
+		}
+
+		delete(s.newAuthorIDs, author.ID)
+		s.newAuthorIDs[existingID] = struct{}{}
+	}
+
+	skipped := make(map[int]struct{}, len(failed))
+
+	for _, rowErr := range failed {
+		skipped[rowErr.Row] = struct{}{}
+	}
+
+	for _, row := range s.batch {
+		if _, ok := skipped[row.row]; ok {
+			continue
+		}
+
+		for _, id := range authorIDs(row.record) {
+			if existingID, ok := clashes[id]; ok {
+				err := &entity.AuthorAlreadyExistsError{ExistingID: existingID}
+				failed = append(failed, entity.ImportRowError{Row: row.row, Message: err.Error()})
+
+				break
+			}
+		}
+	}
+
+	created = slices.DeleteFunc(created, func(author entity.Author) bool {
+		_, ok := clashes[author.ID]
+		return ok
+	})
+
+	return created, failed
+}
+
+// importBooks builds the books of the batch, reporting the rows whose IDs are
+// already taken. It skips the rows resolveAuthors has failed.
+func (l *libraryImpl) importBooks(ctx context.Context, state *catalogImport, failedAuthors []entity.ImportRowError) ([]entity.Book, []entity.ImportRowError, error) {
+	skipped := make(map[int]struct{}, len(failedAuthors))
+
+	for _, rowErr := range failedAuthors {
+		skipped[rowErr.Row] = struct{}{}
+	}
+
+	supplied := make([]string, 0)
+
+	for _, row := range state.batch {
+		if _, ok := skipped[row.row]; ok {
+			continue
+		}
+
+		if row.record.ID != "" && !row.record.IsAuthor() {
+			supplied = append(supplied, row.record.ID)
+		}
+	}
+
+	taken := make(map[string]struct{})
+
+	if len(supplied) > 0 {
+		ids, err := l.catalogRepository.FindBookIDs(ctx, supplied)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, id := range ids {
+			taken[id] = struct{}{}
+		}
+	}
+
+	books := make([]entity.Book, 0, len(state.batch))
+	failed := make([]entity.ImportRowError, 0)
+
+	for _, row := range state.batch {
+		if _, ok := skipped[row.row]; ok || row.record.IsAuthor() {
+			continue
+		}
+
+		if _, ok := taken[row.record.ID]; ok {
+			failed = append(failed, entity.ImportRowError{Row: row.row, Message: entity.ErrBookAlreadyExists.Error()})
+			continue
+		}
+
+		id, err := l.newID(row.record.ID)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		linkedIDs := row.record.AuthorIDs
+
+		if len(linkedIDs) == 0 {
+			linkedIDs = make([]string, 0, len(row.record.Authors))
+
+			for _, name := range row.record.Authors {
+				linkedIDs = append(linkedIDs, state.authorID(entity.NormalizeAuthorName(name)))
+			}
+		}
+
+		book := entity.Book{
+			ID:        id,
+			Name:      row.record.Name,
+			AuthorIDs: uniqueIDs(linkedIDs),
+			Publisher: row.record.Publisher,
+		}
+
+		if row.record.ISBN != "" {
+			// Validate has checked the ISBN already.
+			book.ISBN, _ = entity.NormalizeISBN(row.record.ISBN)
+		}
+
+		books = append(books, book)
+	}
+
+	return books, failed, nil
+}
+
+func (l *libraryImpl) sendImported(ctx context.Context, kind repository.OutboxKind, id string, value any) error {
+	serialized, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return l.outboxRepository.SendMessage(ctx, kind.String()+"_"+id, kind, serialized)
+}
+
+// uniqueIDs drops repeated IDs or names keeping the order.
+func uniqueIDs(ids []string) []string {
+	result := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
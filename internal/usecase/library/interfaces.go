@@ -2,6 +2,8 @@ package library
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/project/library/internal/entity"
 	"github.com/project/library/internal/usecase/repository"
@@ -11,16 +13,56 @@ import (
 //go:generate ../../../bin/mockgen -source=interfaces.go -destination=../../../generated/mocks/usecase_mock.go -package=mocks
 type (
 	AuthorUseCase interface {
-		RegisterAuthor(ctx context.Context, authorName string) (entity.Author, error)
-		UpdateAuthor(ctx context.Context, authorID string, authorName string) error
+		RegisterAuthor(ctx context.Context, authorID string, authorName string) (entity.Author, error)
+		// UpdateAuthor renames the author and, unless profile is nil, replaces
+		// its profile.
+		UpdateAuthor(ctx context.Context, authorID string, authorName string, profile *entity.AuthorProfile) error
 		GetAuthorBooks(ctx context.Context, authorID string) ([]entity.Book, error)
 		GetAuthorInfo(ctx context.Context, authorID string) (entity.Author, error)
+		FindDuplicateAuthors(ctx context.Context, threshold float64, limit int) ([]entity.AuthorDuplicate, error)
+		MergeAuthors(ctx context.Context, sourceIDs []string, targetID string) (entity.Author, error)
+		DeleteAuthor(ctx context.Context, authorID string) error
+		RestoreAuthor(ctx context.Context, authorID string) error
+		ListDeletedAuthors(ctx context.Context, limit int) ([]entity.Author, error)
+		GetAuthorHistory(ctx context.Context, authorID string, limit int) ([]entity.HistoryEntry, error)
+		// ListAuthors returns a page of the live authors by name.
+		ListAuthors(ctx context.Context, offset int, limit int) ([]entity.Author, error)
 	}
 
 	BooksUseCase interface {
-		RegisterBook(ctx context.Context, name string, authorIDs []string) (entity.Book, error)
+		RegisterBook(ctx context.Context, bookID string, name string, authorIDs []string) (entity.Book, error)
 		GetBook(ctx context.Context, bookID string) (entity.Book, error)
 		UpdateBook(ctx context.Context, bookID string, bookName string, authorIDs []string) error
+		MergeBooks(ctx context.Context, sourceIDs []string, targetID string) (entity.Book, error)
+		DeleteBook(ctx context.Context, bookID string) error
+		RestoreBook(ctx context.Context, bookID string) error
+		ListDeletedBooks(ctx context.Context, limit int) ([]entity.Book, error)
+		GetBookHistory(ctx context.Context, bookID string, limit int) ([]entity.HistoryEntry, error)
+		GetBookAsOf(ctx context.Context, bookID string, asOf time.Time) (entity.Book, error)
+		// ImportCatalog loads the records read from r in batches, creating the
+		// authors it can't find by name. Rows that fail validation are
+		// reported and skipped; an error aborts the import after the last
+		// committed batch.
+		ImportCatalog(ctx context.Context, options entity.ImportOptions, r io.Reader) (entity.ImportReport, error)
+		// ExportCatalog writes the live authors and then the live books with
+		// their authors to w, all read in one repeatable read transaction.
+		// ImportCatalog reads the output back.
+		ExportCatalog(ctx context.Context, options entity.ExportOptions, w io.Writer) error
+		// ListBookRecords, GetBookRecord and EarliestDatestamp serve catalog
+		// harvesting; deleted books are reported, not left out.
+		ListBookRecords(ctx context.Context, query entity.HarvestQuery) ([]entity.BookRecord, error)
+		GetBookRecord(ctx context.Context, bookID string) (entity.BookRecord, error)
+		EarliestDatestamp(ctx context.Context) (time.Time, error)
+		// ListBooks returns a page of the live books the filter selects,
+		// newest first, with the names of their authors.
+		ListBooks(ctx context.Context, filter entity.BookFilter) ([]entity.BookRecord, error)
+		// GetBookCitation renders the book with its authors, in the order the
+		// book lists them, in the citation format.
+		GetBookCitation(ctx context.Context, bookID string, format entity.CitationFormat) ([]byte, error)
+	}
+
+	IDGenerator interface {
+		NewID() (string, error)
 	}
 )
 
@@ -33,6 +75,11 @@ type libraryImpl struct {
 	booksRepository  repository.BooksRepository
 	outboxRepository repository.OutboxRepository
 	transactor       repository.Transactor
+	idGenerator      IDGenerator
+
+	idempotencyRepository repository.IdempotencyRepository
+	historyRepository     repository.HistoryRepository
+	catalogRepository     repository.CatalogRepository
 }
 
 func New(
@@ -41,6 +88,10 @@ func New(
 	booksRepository repository.BooksRepository,
 	outboxRepository repository.OutboxRepository,
 	transactor repository.Transactor,
+	idGenerator IDGenerator,
+	idempotencyRepository repository.IdempotencyRepository,
+	historyRepository repository.HistoryRepository,
+	catalogRepository repository.CatalogRepository,
 ) *libraryImpl {
 	return &libraryImpl{
 		logger:           logger,
@@ -48,5 +99,10 @@ func New(
 		booksRepository:  booksRepository,
 		outboxRepository: outboxRepository,
 		transactor:       transactor,
+		idGenerator:      idGenerator,
+
+		idempotencyRepository: idempotencyRepository,
+		historyRepository:     historyRepository,
+		catalogRepository:     catalogRepository,
 	}
 }
@@ -0,0 +1,109 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+// MergeAuthors folds the sources into the target in one transaction: books
+// move to the target, the sources are deleted and their IDs keep resolving to
+// the target through GetAuthorInfo.
+func (l *libraryImpl) MergeAuthors(ctx context.Context, sourceIDs []string, targetID string) (entity.Author, error) {
+	sourceIDs, err := mergeSources(sourceIDs, targetID)
+
+	if err != nil {
+		return entity.Author{}, err
+	}
+
+	var author entity.Author
+
+	err = l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if txErr := l.authorRepository.MergeAuthors(ctx, sourceIDs, targetID); txErr != nil {
+			return txErr
+		}
+
+		var txErr error
+		author, txErr = l.authorRepository.GetAuthorInfo(ctx, targetID)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		return l.sendMerge(ctx, repository.OutboxKindAuthorMerge, sourceIDs, targetID)
+	})
+
+	if err != nil {
+		return entity.Author{}, err
+	}
+
+	return author, nil
+}
+
+// MergeBooks folds the sources into the target in one transaction: the target
+// gets the union of the authors, the sources are deleted and their IDs keep
+// resolving to the target through GetBook.
+func (l *libraryImpl) MergeBooks(ctx context.Context, sourceIDs []string, targetID string) (entity.Book, error) {
+	sourceIDs, err := mergeSources(sourceIDs, targetID)
+
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	var book entity.Book
+
+	err = l.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if txErr := l.booksRepository.MergeBooks(ctx, sourceIDs, targetID); txErr != nil {
+			return txErr
+		}
+
+		var txErr error
+		book, txErr = l.booksRepository.GetBook(ctx, targetID)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		return l.sendMerge(ctx, repository.OutboxKindBookMerge, sourceIDs, targetID)
+	})
+
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	return book, nil
+}
+
+// mergeSources returns the sorted unique sources.
+func mergeSources(sourceIDs []string, targetID string) ([]string, error) {
+	if len(sourceIDs) == 0 {
+		return nil, entity.ErrMergeWithoutSources
+	}
+
+	if slices.Contains(sourceIDs, targetID) {
+		return nil, entity.ErrMergeIntoSource
+	}
+
+	sorted := slices.Clone(sourceIDs)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted), nil
+}
+
+func (l *libraryImpl) sendMerge(ctx context.Context, kind repository.OutboxKind, sourceIDs []string, targetID string) error {
+	serialized, err := json.Marshal(entity.Merge{
+		SourceIDs: sourceIDs,
+		TargetID:  targetID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// A source is deleted by its merge, so it identifies the merge.
+	idempotencyKey := kind.String() + "_" + sourceIDs[0]
+	return l.outboxRepository.SendMessage(ctx, idempotencyKey, kind, serialized)
+}
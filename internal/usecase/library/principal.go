@@ -0,0 +1,23 @@
+package library
+
+import (
+	"context"
+
+	"github.com/project/library/internal/entity"
+	"github.com/project/library/internal/usecase/repository"
+)
+
+type principalInjector struct{}
+
+// ContextWithPrincipal marks the calls made with ctx as made by principal.
+// The history records its subject as the author of the changes.
+func ContextWithPrincipal(ctx context.Context, principal entity.Principal) context.Context {
+	ctx = context.WithValue(ctx, principalInjector{}, principal)
+	return repository.ContextWithActor(ctx, principal.Subject)
+}
+
+// PrincipalFromContext returns the principal set by ContextWithPrincipal.
+func PrincipalFromContext(ctx context.Context) (entity.Principal, bool) {
+	principal, ok := ctx.Value(principalInjector{}).(entity.Principal)
+	return principal, ok
+}
@@ -0,0 +1,42 @@
+package library
+
+import (
+	"context"
+
+	"github.com/project/library/internal/entity"
+)
+
+// DefaultDeletedLimit is used by the trash listings when limit is zero.
+const DefaultDeletedLimit = 100
+
+func (l *libraryImpl) DeleteAuthor(ctx context.Context, authorID string) error {
+	return l.authorRepository.DeleteAuthor(ctx, authorID)
+}
+
+func (l *libraryImpl) RestoreAuthor(ctx context.Context, authorID string) error {
+	return l.authorRepository.RestoreAuthor(ctx, authorID)
+}
+
+func (l *libraryImpl) ListDeletedAuthors(ctx context.Context, limit int) ([]entity.Author, error) {
+	if limit <= 0 {
+		limit = DefaultDeletedLimit
+	}
+
+	return l.authorRepository.ListDeletedAuthors(ctx, limit)
+}
+
+func (l *libraryImpl) DeleteBook(ctx context.Context, bookID string) error {
+	return l.booksRepository.DeleteBook(ctx, bookID)
+}
+
+func (l *libraryImpl) RestoreBook(ctx context.Context, bookID string) error {
+	return l.booksRepository.RestoreBook(ctx, bookID)
+}
+
+func (l *libraryImpl) ListDeletedBooks(ctx context.Context, limit int) ([]entity.Book, error) {
+	if limit <= 0 {
+		limit = DefaultDeletedLimit
+	}
+
+	return l.booksRepository.ListDeletedBooks(ctx, limit)
+}
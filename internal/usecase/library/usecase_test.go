@@ -1,9 +1,15 @@
 package library
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/project/library/generated/mocks"
 	"github.com/project/library/internal/entity"
 	"github.com/project/library/internal/usecase/repository"
@@ -17,7 +23,21 @@ const FAILURE = "failure"
 
 type DumbTransactorImpl struct{}
 
-func (d *DumbTransactorImpl) WithTx(ctx context.Context, function func(ctx context.Context) error) error {
+func (d *DumbTransactorImpl) WithTx(ctx context.Context, function func(ctx context.Context) error, _ ...repository.TxOption) error {
+	return function(ctx)
+}
+
+// RetryingTransactorImpl re-runs the function once after a serialization
+// failure, as the transactor does by default.
+type RetryingTransactorImpl struct{}
+
+func (r *RetryingTransactorImpl) WithTx(ctx context.Context, function func(ctx context.Context) error, _ ...repository.TxOption) error {
+	var pgErr *pgconn.PgError
+
+	if err := function(ctx); !errors.As(err, &pgErr) || pgErr.Code != "40001" {
+		return err
+	}
+
 	return function(ctx)
 }
 
@@ -29,7 +49,7 @@ func TestRegisterAuthor(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	successAuthor := repository.CreateAuthor(SUCCESS)
 	failureAuthor := repository.CreateAuthor(FAILURE + "_1")
@@ -79,7 +99,7 @@ func TestRegisterAuthor(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			result, err := test.target.RegisterAuthor(t.Context(), test.authorID)
+			result, err := test.target.RegisterAuthor(t.Context(), "", test.authorID)
 			require.ErrorIs(t, test.expectedErr, err)
 			require.Equal(t, test.expected, result)
 		})
@@ -94,7 +114,7 @@ func TestUpdateAuthor(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	authorMock.EXPECT().UpdateAuthor(gomock.Any(), gomock.Cond(func(x entity.Author) bool {
 		return x.Name == SUCCESS
@@ -130,12 +150,53 @@ func TestUpdateAuthor(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			err := test.target.UpdateAuthor(t.Context(), test.authorID, test.authorName)
+			err := test.target.UpdateAuthor(t.Context(), test.authorID, test.authorName, nil)
 			require.ErrorIs(t, test.expectedErr, err)
 		})
 	}
 }
 
+func TestUpdateAuthorProfile(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, mocks.NewMockBooksRepository(control), mocks.NewMockOutboxRepository(control),
+		&DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	authorID, realID := uuid.NewString(), uuid.NewString()
+
+	authorMock.EXPECT().UpdateAuthor(gomock.Any(), entity.Author{ID: authorID, Name: "Mark Twain"}).Return(nil)
+	authorMock.EXPECT().UpdateAuthorProfile(gomock.Any(), authorID, entity.AuthorProfile{
+		BirthDate:      "1835-11-30",
+		DeathDate:      "1910",
+		Nationality:    "US",
+		AlternateNames: []string{"Марк Твен"},
+		PseudonymOf:    realID,
+		ISNI:           "000000012281955X",
+		WikidataID:     "Q7245",
+	}).Return(nil)
+
+	err := target.UpdateAuthor(t.Context(), authorID, "Mark Twain", &entity.AuthorProfile{
+		BirthDate:      "1835-11-30",
+		DeathDate:      "1910",
+		Nationality:    "us",
+		AlternateNames: []string{"Марк Твен", "марк твен"},
+		PseudonymOf:    realID,
+		Pseudonyms:     []string{uuid.NewString()},
+		ISNI:           "0000 0001 2281 955x",
+		WikidataID:     "q7245",
+	})
+	require.NoError(t, err)
+
+	err = target.UpdateAuthor(t.Context(), authorID, "Mark Twain", &entity.AuthorProfile{PseudonymOf: authorID})
+	require.ErrorIs(t, err, entity.ErrPseudonymOfSelf)
+
+	err = target.UpdateAuthor(t.Context(), authorID, "Mark Twain", &entity.AuthorProfile{BirthDate: "1910", DeathDate: "1835-11-30"})
+	require.ErrorIs(t, err, entity.ErrDeathBeforeBirth)
+}
+
 func TestGetAuthorBooks(t *testing.T) {
 	t.Parallel()
 
@@ -144,7 +205,7 @@ func TestGetAuthorBooks(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	books := []entity.Book{
 		repository.CreateBook("How to live in the beauty trash", SUCCESS),
@@ -196,7 +257,7 @@ func TestGetAuthorInfo(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	successAuthor := repository.CreateAuthor(SUCCESS)
 
@@ -244,7 +305,7 @@ func TestRegisterBook(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	successBook := repository.CreateBook(SUCCESS, SUCCESS)
 	failureBook := repository.CreateBook(FAILURE, FAILURE)
@@ -297,7 +358,7 @@ func TestRegisterBook(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			result, err := test.target.RegisterBook(t.Context(), test.bookID, test.authorIDs)
+			result, err := test.target.RegisterBook(t.Context(), "", test.bookID, test.authorIDs)
 			require.ErrorIs(t, test.expectedErr, err)
 			require.Equal(t, test.expected, result)
 		})
@@ -312,7 +373,7 @@ func TestUpdateBook(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 
 	bookMock.EXPECT().UpdateBook(gomock.Any(), gomock.Cond(func(x entity.Book) bool {
 		return x.Name == SUCCESS
@@ -365,7 +426,7 @@ func TestGetBook(t *testing.T) {
 	bookMock := mocks.NewMockBooksRepository(control)
 	outboxMock := mocks.NewMockOutboxRepository(control)
 
-	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{})
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
 	successBook := repository.CreateBook(SUCCESS)
 
 	bookMock.EXPECT().GetBook(gomock.Any(), gomock.Eq(SUCCESS)).Return(successBook, nil)
@@ -395,3 +456,699 @@ func TestGetBook(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterBookID(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	suppliedID := uuid.New().String()
+
+	bookMock.EXPECT().CreateBook(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, book entity.Book) (entity.Book, error) {
+		return book, nil
+	}).AnyTimes()
+	outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), repository.OutboxKindBook, gomock.Any()).Return(nil).AnyTimes()
+
+	supplied, err := target.RegisterBook(t.Context(), suppliedID, SUCCESS, nil)
+	require.NoError(t, err)
+	require.Equal(t, suppliedID, supplied.ID)
+
+	first, err := target.RegisterBook(t.Context(), "", SUCCESS, nil)
+	require.NoError(t, err)
+	second, err := target.RegisterBook(t.Context(), "", SUCCESS, nil)
+	require.NoError(t, err)
+
+	firstID, err := uuid.Parse(first.ID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), firstID.Version())
+	require.Less(t, first.ID, second.ID)
+}
+
+func TestRegisterBookIdempotency(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+	idempotencyMock := mocks.NewMockIdempotencyRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), idempotencyMock, mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	storedBook := repository.CreateBook(SUCCESS)
+	storedResponse, err := json.Marshal(storedBook)
+	require.NoError(t, err)
+	storedHash, err := hashRequest(entity.Book{Name: SUCCESS})
+	require.NoError(t, err)
+
+	bookMock.EXPECT().CreateBook(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, book entity.Book) (entity.Book, error) {
+		return book, nil
+	}).Times(1)
+	outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), repository.OutboxKindBook, gomock.Any()).Return(nil).Times(1)
+
+	idempotencyMock.EXPECT().GetResponse(gomock.Any(), idempotencyMethodRegisterBook, "new").Return(repository.IdempotencyRecord{}, entity.ErrIdempotencyKeyNotFound)
+	idempotencyMock.EXPECT().SaveResponse(gomock.Any(), gomock.Cond(func(x repository.IdempotencyRecord) bool {
+		return x.Key == "new" && x.RequestHash == storedHash
+	})).Return(nil)
+	idempotencyMock.EXPECT().GetResponse(gomock.Any(), idempotencyMethodRegisterBook, "stored").Return(repository.IdempotencyRecord{
+		Method:      idempotencyMethodRegisterBook,
+		Key:         "stored",
+		RequestHash: storedHash,
+		Response:    storedResponse,
+	}, nil).Times(2)
+
+	tests := []struct {
+		name        string
+		key         string
+		bookName    string
+		expected    entity.Book
+		expectedErr error
+	}{
+		{
+			name:     "first call",
+			key:      "new",
+			bookName: SUCCESS,
+		},
+		{
+			name:     "replay",
+			key:      "stored",
+			bookName: SUCCESS,
+			expected: storedBook,
+		},
+		{
+			name:        "reused key",
+			key:         "stored",
+			bookName:    FAILURE,
+			expectedErr: entity.ErrIdempotencyKeyReused,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := ContextWithIdempotencyKey(t.Context(), test.key)
+			result, err := target.RegisterBook(ctx, "", test.bookName, nil)
+			require.ErrorIs(t, err, test.expectedErr)
+			if test.expected.ID != "" {
+				require.Equal(t, test.expected.ID, result.ID)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateAuthors(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	duplicates := []entity.AuthorDuplicate{
+		{
+			First:      repository.CreateAuthor("Fyodor Dostoevsky"),
+			Second:     repository.CreateAuthor("Fyodor Dostoyevsky"),
+			Similarity: 0.7,
+		},
+	}
+
+	authorMock.EXPECT().FindDuplicateAuthors(gomock.Any(), DefaultDuplicateThreshold, DefaultDuplicateLimit).Return(duplicates, nil)
+	authorMock.EXPECT().FindDuplicateAuthors(gomock.Any(), 0.9, 5).Return(nil, nil)
+
+	tests := []struct {
+		name      string
+		threshold float64
+		limit     int
+		expected  []entity.AuthorDuplicate
+	}{
+		{
+			name:     "defaults",
+			expected: duplicates,
+		},
+		{
+			name:      "explicit",
+			threshold: 0.9,
+			limit:     5,
+			expected:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := target.FindDuplicateAuthors(t.Context(), test.threshold, test.limit)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestMergeAuthors(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	author := repository.CreateAuthor(SUCCESS)
+	first, second := uuid.NewString(), uuid.NewString()
+	sources := []string{first, second}
+	if second < first {
+		sources = []string{second, first}
+	}
+
+	authorMock.EXPECT().MergeAuthors(gomock.Any(), sources, author.ID).Return(nil)
+	authorMock.EXPECT().GetAuthorInfo(gomock.Any(), author.ID).Return(author, nil)
+	outboxMock.EXPECT().SendMessage(gomock.Any(), "author_merge_"+sources[0], repository.OutboxKindAuthorMerge, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ repository.OutboxKind, message []byte) error {
+			var merge entity.Merge
+			require.NoError(t, json.Unmarshal(message, &merge))
+			require.Equal(t, entity.Merge{SourceIDs: sources, TargetID: author.ID}, merge)
+			return nil
+		})
+
+	tests := []struct {
+		name        string
+		sourceIDs   []string
+		targetID    string
+		expected    entity.Author
+		expectedErr error
+	}{
+		{
+			name:        "success case",
+			sourceIDs:   []string{second, first, second},
+			targetID:    author.ID,
+			expected:    author,
+			expectedErr: nil,
+		},
+		{
+			name:        "no sources",
+			sourceIDs:   nil,
+			targetID:    author.ID,
+			expected:    entity.Author{},
+			expectedErr: entity.ErrMergeWithoutSources,
+		},
+		{
+			name:        "target among sources",
+			sourceIDs:   []string{first, author.ID},
+			targetID:    author.ID,
+			expected:    entity.Author{},
+			expectedErr: entity.ErrMergeIntoSource,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := target.MergeAuthors(t.Context(), test.sourceIDs, test.targetID)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestMergeBooks(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	book := repository.CreateBook(SUCCESS)
+	source := uuid.NewString()
+	failureSource := uuid.NewString()
+
+	bookMock.EXPECT().MergeBooks(gomock.Any(), []string{source}, book.ID).Return(nil)
+	bookMock.EXPECT().GetBook(gomock.Any(), book.ID).Return(book, nil)
+	bookMock.EXPECT().MergeBooks(gomock.Any(), []string{failureSource}, book.ID).Return(entity.ErrBookNotFound)
+	outboxMock.EXPECT().SendMessage(gomock.Any(), "book_merge_"+source, repository.OutboxKindBookMerge, gomock.Any()).Return(nil)
+
+	tests := []struct {
+		name        string
+		sourceIDs   []string
+		expected    entity.Book
+		expectedErr error
+	}{
+		{
+			name:        "success case",
+			sourceIDs:   []string{source},
+			expected:    book,
+			expectedErr: nil,
+		},
+		{
+			name:        "failure case",
+			sourceIDs:   []string{failureSource},
+			expected:    entity.Book{},
+			expectedErr: entity.ErrBookNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := target.MergeBooks(t.Context(), test.sourceIDs, book.ID)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestListDeleted(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock, outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	authorMock.EXPECT().ListDeletedAuthors(gomock.Any(), DefaultDeletedLimit).Return([]entity.Author{}, nil)
+	bookMock.EXPECT().ListDeletedBooks(gomock.Any(), 5).Return([]entity.Book{}, nil)
+
+	authors, err := target.ListDeletedAuthors(t.Context(), 0)
+	require.NoError(t, err)
+	require.Empty(t, authors)
+
+	books, err := target.ListDeletedBooks(t.Context(), 5)
+	require.NoError(t, err)
+	require.Empty(t, books)
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	historyMock := mocks.NewMockHistoryRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), historyMock, mocks.NewMockCatalogRepository(control))
+
+	historyMock.EXPECT().GetBookHistory(gomock.Any(), "book", DefaultHistoryLimit).Return([]entity.HistoryEntry{}, nil)
+	historyMock.EXPECT().GetAuthorHistory(gomock.Any(), "author", 5).Return(nil, entity.ErrAuthorNotFound)
+
+	entries, err := target.GetBookHistory(t.Context(), "book", 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	_, err = target.GetAuthorHistory(t.Context(), "author", 5)
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+}
+
+func TestListBookRecords(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), catalogMock)
+
+	catalogMock.EXPECT().ListBookRecords(gomock.Any(), entity.HarvestQuery{Limit: DefaultHarvestLimit}).Return([]entity.BookRecord{}, nil)
+	catalogMock.EXPECT().ListBookRecords(gomock.Any(), entity.HarvestQuery{Limit: 3}).Return(nil, entity.ErrBookNotFound)
+
+	records, err := target.ListBookRecords(t.Context(), entity.HarvestQuery{})
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	_, err = target.ListBookRecords(t.Context(), entity.HarvestQuery{Limit: 3})
+	require.ErrorIs(t, err, entity.ErrBookNotFound)
+}
+
+func TestBrowse(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), catalogMock)
+
+	catalogMock.EXPECT().ListBooks(gomock.Any(), entity.BookFilter{Query: "war", Limit: DefaultBrowseLimit}).Return([]entity.BookRecord{}, nil)
+	catalogMock.EXPECT().ListAuthors(gomock.Any(), 10, DefaultBrowseLimit).Return([]entity.Author{}, nil)
+
+	books, err := target.ListBooks(t.Context(), entity.BookFilter{Query: "war"})
+	require.NoError(t, err)
+	require.Empty(t, books)
+
+	authors, err := target.ListAuthors(t.Context(), 10, 0)
+	require.NoError(t, err)
+	require.Empty(t, authors)
+}
+
+func TestGetBookCitation(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+
+	target := New(zaptest.NewLogger(t), authorMock, bookMock,
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control), mocks.NewMockHistoryRepository(control), mocks.NewMockCatalogRepository(control))
+
+	book := entity.Book{ID: uuid.NewString(), Name: "War and Peace", AuthorIDs: []string{uuid.NewString(), uuid.NewString()}}
+
+	bookMock.EXPECT().GetBook(gomock.Any(), book.ID).Return(book, nil).Times(2)
+	authorMock.EXPECT().GetAuthorInfo(gomock.Any(), book.AuthorIDs[0]).Return(entity.Author{ID: book.AuthorIDs[0], Name: "Leo Tolstoy"}, nil).Times(2)
+	authorMock.EXPECT().GetAuthorInfo(gomock.Any(), book.AuthorIDs[1]).Return(entity.Author{}, entity.ErrAuthorNotFound)
+	authorMock.EXPECT().GetAuthorInfo(gomock.Any(), book.AuthorIDs[1]).Return(entity.Author{ID: book.AuthorIDs[1], Name: "Ludwig van Beethoven"}, nil)
+
+	_, err := target.GetBookCitation(t.Context(), book.ID, entity.CitationFormatBibTeX)
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+
+	out, err := target.GetBookCitation(t.Context(), book.ID, entity.CitationFormatBibTeX)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "@book{tolstoywar,")
+	require.Contains(t, string(out), "author = {Tolstoy, Leo and van Beethoven, Ludwig},")
+}
+
+func TestImportCatalog(t *testing.T) {
+	t.Parallel()
+
+	const input = `name,authors
+Skipped,Leo Tolstoy
+War and Peace,Leo Tolstoy;Anna;leo tolstoy
+Broken,Leo  Tolstoy
+Anna Karenina,Anna
+`
+
+	existingID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		dryRun bool
+		writes int
+	}{
+		{name: "import", writes: 1},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			control := gomock.NewController(t)
+			outboxMock := mocks.NewMockOutboxRepository(control)
+			catalogMock := mocks.NewMockCatalogRepository(control)
+
+			target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+				outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+				mocks.NewMockHistoryRepository(control), catalogMock)
+
+			catalogMock.EXPECT().GetImportCheckpoint(gomock.Any(), "import").Return(1, nil)
+			catalogMock.EXPECT().FindAuthorsByName(gomock.Any(), gomock.InAnyOrder([]string{"leo tolstoy", "anna"})).
+				Return(map[string]string{"leo tolstoy": existingID}, nil)
+			catalogMock.EXPECT().FindAuthorNameClashes(gomock.Any(), gomock.Len(1)).Return(map[string]string{}, nil)
+
+			var books []entity.Book
+
+			catalogMock.EXPECT().ImportAuthors(gomock.Any(), gomock.Len(1)).Times(test.writes).
+				DoAndReturn(func(_ context.Context, authors []entity.Author) ([]entity.Author, error) {
+					require.Equal(t, "Anna", authors[0].Name)
+					return authors, nil
+				})
+			catalogMock.EXPECT().ImportBooks(gomock.Any(), gomock.Len(2)).Times(test.writes).
+				DoAndReturn(func(_ context.Context, imported []entity.Book) ([]entity.Book, error) {
+					books = imported
+					return imported, nil
+				})
+			outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), repository.OutboxKindAuthor, gomock.Any()).Times(test.writes).Return(nil)
+			outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), repository.OutboxKindBook, gomock.Any()).Times(2 * test.writes).Return(nil)
+			catalogMock.EXPECT().SaveImportCheckpoint(gomock.Any(), "import", 4).Times(test.writes).Return(nil)
+
+			report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{
+				Format:   entity.CatalogFormatCSV,
+				DryRun:   test.dryRun,
+				ImportID: "import",
+			}, strings.NewReader(input))
+			require.NoError(t, err)
+
+			checkpoint := 4
+			if test.dryRun {
+				checkpoint = 1
+			}
+
+			require.Equal(t, 4, report.Rows)
+			require.Equal(t, 1, report.RowsSkipped)
+			require.Equal(t, 2, report.BooksImported)
+			require.Equal(t, 1, report.AuthorsCreated)
+			require.Equal(t, checkpoint, report.Checkpoint)
+			require.Equal(t, test.dryRun, report.DryRun)
+			require.Len(t, report.Errors, 1)
+			require.Equal(t, 3, report.Errors[0].Row)
+
+			if !test.dryRun {
+				require.Len(t, books[0].AuthorIDs, 2)
+				require.Equal(t, existingID, books[0].AuthorIDs[0])
+				require.Equal(t, books[0].AuthorIDs[1], books[1].AuthorIDs[0])
+			}
+		})
+	}
+}
+
+func TestImportCatalogTakenID(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+		mocks.NewMockHistoryRepository(control), catalogMock)
+
+	taken := uuid.NewString()
+	input := `{"id": "` + taken + `", "name": "Taken"}
+{"id": "` + taken + `", "name": "Twice"}
+`
+
+	catalogMock.EXPECT().FindBookIDs(gomock.Any(), []string{taken}).Return([]string{taken}, nil)
+
+	report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: entity.CatalogFormatJSONL}, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, []entity.ImportRowError{
+		{Row: 2, Message: entity.ErrBookAlreadyExists.Error()},
+		{Row: 1, Message: entity.ErrBookAlreadyExists.Error()},
+	}, report.Errors)
+	require.Zero(t, report.BooksImported)
+
+	_, err = target.ImportCatalog(t.Context(), entity.ImportOptions{Format: "xml"}, strings.NewReader(input))
+	require.ErrorIs(t, err, entity.ErrUnknownCatalogFormat)
+}
+
+func TestExportCatalogRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tolstoy := entity.Author{ID: uuid.NewString(), Name: "Leo Tolstoy"}
+	anonymous := entity.Author{ID: uuid.NewString(), Name: "Anonymous"}
+	books := []entity.Book{
+		{ID: uuid.NewString(), Name: "War and Peace", AuthorIDs: []string{tolstoy.ID}},
+		{ID: uuid.NewString(), Name: "Untitled", AuthorIDs: []string{}},
+	}
+
+	for _, options := range []entity.ExportOptions{
+		{Format: entity.CatalogFormatCSV},
+		{Format: entity.CatalogFormatJSONL, Gzip: true},
+	} {
+		t.Run(string(options.Format), func(t *testing.T) {
+			t.Parallel()
+
+			control := gomock.NewController(t)
+			outboxMock := mocks.NewMockOutboxRepository(control)
+			catalogMock := mocks.NewMockCatalogRepository(control)
+
+			target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+				outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+				mocks.NewMockHistoryRepository(control), catalogMock)
+
+			catalogMock.EXPECT().ExportAuthors(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, fn func(entity.Author) error) error {
+					for _, author := range []entity.Author{tolstoy, anonymous} {
+						if err := fn(author); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			catalogMock.EXPECT().ExportBooks(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, fn func(entity.Book) error) error {
+					for _, book := range books {
+						if err := fn(book); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+
+			var snapshot bytes.Buffer
+			require.NoError(t, target.ExportCatalog(t.Context(), options, &snapshot))
+
+			catalogMock.EXPECT().FindAuthorIDs(gomock.Any(), []string{tolstoy.ID, anonymous.ID}).Return(nil, nil)
+			catalogMock.EXPECT().FindAuthorNameClashes(gomock.Any(), []entity.Author{tolstoy, anonymous}).Return(nil, nil)
+			catalogMock.EXPECT().FindBookIDs(gomock.Any(), []string{books[0].ID, books[1].ID}).Return(nil, nil)
+			catalogMock.EXPECT().ImportAuthors(gomock.Any(), []entity.Author{tolstoy, anonymous}).Return([]entity.Author{tolstoy, anonymous}, nil)
+			catalogMock.EXPECT().ImportBooks(gomock.Any(), books).Return(books, nil)
+			outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4).Return(nil)
+
+			report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: options.Format}, &snapshot)
+			require.NoError(t, err)
+			require.Empty(t, report.Errors)
+			require.Equal(t, 4, report.Rows)
+			require.Equal(t, 2, report.AuthorsCreated)
+			require.Equal(t, 2, report.BooksImported)
+		})
+	}
+}
+
+func TestImportCatalogAuthorRecords(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		mocks.NewMockOutboxRepository(control), &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+		mocks.NewMockHistoryRepository(control), catalogMock)
+
+	existingID := uuid.NewString()
+	input := `{"kind": "author", "id": "` + existingID + `", "name": "Taken"}
+{"kind": "author", "name": "Leo Tolstoy"}
+{"kind": "journal", "name": "Unknown"}
+`
+
+	catalogMock.EXPECT().FindAuthorIDs(gomock.Any(), []string{existingID}).Return(map[string]bool{existingID: true}, nil)
+	catalogMock.EXPECT().FindAuthorsByName(gomock.Any(), []string{"leo tolstoy"}).Return(map[string]string{"leo tolstoy": uuid.NewString()}, nil)
+
+	report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: entity.CatalogFormatJSONL, DryRun: true}, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Zero(t, report.AuthorsCreated)
+	require.Len(t, report.Errors, 2)
+	require.Equal(t, 3, report.Errors[0].Row)
+	require.Equal(t, entity.ImportRowError{Row: 1, Message: entity.ErrAuthorAlreadyExists.Error()}, report.Errors[1])
+}
+
+func TestImportCatalogUnlinkableAuthors(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+		mocks.NewMockHistoryRepository(control), catalogMock)
+
+	liveID, trashedID, mergedID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	input := `{"name": "War and Peace", "author_ids": ["` + liveID + `"], "authors": ["Leo Tolstoy"]}
+{"name": "Anna Karenina", "author_ids": ["` + liveID + `", "` + trashedID + `"], "authors": ["Leo Tolstoy", "Trashed"]}
+{"name": "Resurrection", "author_ids": ["` + mergedID + `"], "authors": ["Merged"]}
+{"kind": "author", "id": "` + mergedID + `", "name": "Merged"}
+`
+
+	catalogMock.EXPECT().FindAuthorIDs(gomock.Any(), []string{liveID, trashedID, mergedID}).
+		Return(map[string]bool{liveID: true, trashedID: false, mergedID: false}, nil)
+	catalogMock.EXPECT().ImportBooks(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(_ context.Context, books []entity.Book) ([]entity.Book, error) {
+			require.Equal(t, []string{liveID}, books[0].AuthorIDs)
+			return books, nil
+		})
+	outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: entity.CatalogFormatJSONL}, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Zero(t, report.AuthorsCreated)
+	require.Equal(t, 1, report.BooksImported)
+	require.Equal(t, []entity.ImportRowError{
+		{Row: 2, Message: entity.ErrAuthorNotFound.Error()},
+		{Row: 3, Message: entity.ErrAuthorNotFound.Error()},
+		{Row: 4, Message: entity.ErrAuthorAlreadyExists.Error()},
+	}, report.Errors)
+}
+
+func TestImportCatalogAuthorNameClashes(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		outboxMock, &DumbTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+		mocks.NewMockHistoryRepository(control), catalogMock)
+
+	suppliedID, existingID := uuid.NewString(), uuid.NewString()
+	input := `{"kind": "author", "id": "` + suppliedID + `", "name": "Leo Tolstoy"}
+{"name": "War and Peace", "author_ids": ["` + suppliedID + `"], "authors": ["Leo Tolstoy"]}
+{"name": "Anna Karenina", "authors": ["Leo Tolstoy"]}
+`
+
+	catalogMock.EXPECT().FindAuthorIDs(gomock.Any(), []string{suppliedID}).Return(map[string]bool{}, nil)
+	catalogMock.EXPECT().FindAuthorsByName(gomock.Any(), []string{"leo tolstoy"}).Return(map[string]string{"leo tolstoy": existingID}, nil)
+	catalogMock.EXPECT().FindAuthorNameClashes(gomock.Any(), gomock.Len(1)).Return(map[string]string{suppliedID: existingID}, nil)
+	catalogMock.EXPECT().ImportBooks(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(_ context.Context, books []entity.Book) ([]entity.Book, error) {
+			require.Equal(t, []string{existingID}, books[0].AuthorIDs, "the book links the author holding the name")
+			return books, nil
+		})
+	outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), repository.OutboxKindBook, gomock.Any()).Return(nil)
+
+	report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: entity.CatalogFormatJSONL}, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Zero(t, report.AuthorsCreated)
+	require.Equal(t, 1, report.BooksImported)
+
+	clash := (&entity.AuthorAlreadyExistsError{ExistingID: existingID}).Error()
+	require.Equal(t, []entity.ImportRowError{{Row: 1, Message: clash}, {Row: 2, Message: clash}}, report.Errors)
+}
+
+func TestImportCatalogRetry(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	outboxMock := mocks.NewMockOutboxRepository(control)
+	catalogMock := mocks.NewMockCatalogRepository(control)
+
+	target := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		outboxMock, &RetryingTransactorImpl{}, NewUUIDv7Generator(), mocks.NewMockIdempotencyRepository(control),
+		mocks.NewMockHistoryRepository(control), catalogMock)
+
+	const input = `name,authors
+War and Peace,Leo Tolstoy
+`
+
+	var authors []entity.Author
+
+	catalogMock.EXPECT().FindAuthorsByName(gomock.Any(), []string{"leo tolstoy"}).Times(2).Return(map[string]string{}, nil)
+	catalogMock.EXPECT().FindAuthorNameClashes(gomock.Any(), gomock.Len(1)).Times(2).Return(map[string]string{}, nil)
+	gomock.InOrder(
+		catalogMock.EXPECT().ImportAuthors(gomock.Any(), gomock.Len(1)).Return(nil, &pgconn.PgError{Code: "40001"}),
+		catalogMock.EXPECT().ImportAuthors(gomock.Any(), gomock.Len(1)).
+			DoAndReturn(func(_ context.Context, imported []entity.Author) ([]entity.Author, error) {
+				authors = imported
+				return imported, nil
+			}),
+	)
+	catalogMock.EXPECT().ImportBooks(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(_ context.Context, books []entity.Book) ([]entity.Book, error) {
+			require.Equal(t, []string{authors[0].ID}, books[0].AuthorIDs, "the retry creates the author again")
+			return books, nil
+		})
+	outboxMock.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
+	report, err := target.ImportCatalog(t.Context(), entity.ImportOptions{Format: entity.CatalogFormatCSV}, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, 1, report.AuthorsCreated)
+	require.Equal(t, 1, report.BooksImported)
+}
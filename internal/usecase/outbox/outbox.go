@@ -3,6 +3,7 @@ package outbox
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/project/library/config"
@@ -24,12 +25,12 @@ var (
 
 	outboxHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "library_durations_ms",
-			Help:    "Durations in ms",
+			Name:    "outbox_durations_ms",
+			Help:    "Durations of the outbox message processing in ms",
 			Buckets: prometheus.DefBuckets,
 		},
 		[]string{
-			"lever",
+			"kind",
 		})
 )
 
@@ -41,7 +42,11 @@ type GlobalHandler = func(kind repository.OutboxKind) (KindHandler, error)
 type KindHandler = func(ctx context.Context, data []byte) error
 
 type Outbox interface {
+	// Start runs the workers until the context is done. The wait group is
+	// done once every worker has finished its current batch.
 	Start(ctx context.Context, workers int, batchSize int, waitTime time.Duration, inProgressTTL time.Duration) *sync.WaitGroup
+	// Heartbeat is when a worker last started a round, zero before Start.
+	Heartbeat() time.Time
 }
 
 var _ Outbox = (*outboxImpl)(nil)
@@ -52,6 +57,9 @@ type outboxImpl struct {
 	globalHandler    GlobalHandler
 	cfg              *config.Config
 	transactor       repository.Transactor
+
+	// heartbeat is the Unix time in nanoseconds of the last round.
+	heartbeat atomic.Int64
 }
 
 func New(
@@ -78,6 +86,7 @@ func (o *outboxImpl) Start(
 	inProgressTTL time.Duration,
 ) *sync.WaitGroup {
 	wg := new(sync.WaitGroup)
+	o.beat()
 
 	for workerID := 1; workerID <= workers; workerID++ {
 		wg.Add(1)
@@ -86,6 +95,18 @@ func (o *outboxImpl) Start(
 	return wg
 }
 
+func (o *outboxImpl) Heartbeat() time.Time {
+	if nanos := o.heartbeat.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+
+	return time.Time{}
+}
+
+func (o *outboxImpl) beat() {
+	o.heartbeat.Store(time.Now().UnixNano())
+}
+
 func (o *outboxImpl) worker(
 	ctx context.Context,
 	wg *sync.WaitGroup,
@@ -95,18 +116,21 @@ func (o *outboxImpl) worker(
 ) {
 	defer wg.Done()
 	for {
-		time.Sleep(waitTIme)
 		select {
 		case <-ctx.Done():
 			return
-		default:
+		case <-time.After(waitTIme):
 		}
 
+		o.beat()
+
 		if !o.cfg.Outbox.Enabled {
 			continue
 		}
 
-		err := o.transactor.WithTx(ctx, func(ctx context.Context) error {
+		// A batch runs to the end once started: stopping in the middle
+		// would send its messages again after the restart.
+		err := o.transactor.WithTx(context.WithoutCancel(ctx), func(ctx context.Context) error {
 			messages, getMessageErr := o.outboxRepository.GetMessages(ctx, batchSize, inProgressTTL)
 
 			if getMessageErr != nil {
@@ -122,7 +146,7 @@ func (o *outboxImpl) worker(
 				message := messages[i]
 				key := message.IdempotencyKey
 
-				kindHandler, err := o.globalHandler(message.Kind)
+				kindHandler, kindErr := o.globalHandler(message.Kind)
 				start := time.Now()
 
 				metricOutboxHistogram, err := outboxHistogram.GetMetricWithLabelValues(message.Kind.String())
@@ -130,8 +154,8 @@ func (o *outboxImpl) worker(
 					o.logger.Error("Can't get latency metric", zap.Error(err))
 				}
 
-				if err != nil {
-					o.logger.Error("unexpected kind", zap.Error(err))
+				if kindErr != nil {
+					o.logger.Error("unexpected kind", zap.Error(kindErr))
 					metricOutboxHistogram.Observe(float64(time.Since(start).Milliseconds()))
 					continue
 				}
@@ -165,7 +189,7 @@ func (o *outboxImpl) worker(
 			}
 
 			return nil
-		})
+		}, repository.WithoutRetry())
 
 		if err != nil {
 			o.logger.Error("worker stage error", zap.Error(err))
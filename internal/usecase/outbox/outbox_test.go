@@ -30,7 +30,7 @@ func TestNew(t *testing.T) {
 type MyTransactor struct {
 }
 
-func (*MyTransactor) WithTx(ctx context.Context, function func(ctx context.Context) error) error {
+func (*MyTransactor) WithTx(ctx context.Context, function func(ctx context.Context) error, _ ...repository.TxOption) error {
 	return function(ctx)
 }
 
@@ -52,17 +52,10 @@ func TestStart(t *testing.T) {
 	outbox := New(zaptest.NewLogger(t), outboxRepository, func(kind repository.OutboxKind) (KindHandler, error) {
 		return nil, errors.New("unexpected error")
 	}, &config.Config{
-		Outbox: struct {
-			Enabled         bool          `env:"OUTBOX_ENABLED"`
-			Workers         int           `env:"OUTBOX_WORKERS"`
-			BatchSize       int           `env:"OUTBOX_BATCH_SIZE"`
-			WaitTimeMS      time.Duration `env:"OUTBOX_WAIT_TIME_MS"`
-			InProgressTTLMS time.Duration `env:"OUTBOX_IN_PROGRESS_TTL_MS"`
-			BookSendURL     string        `env:"OUTBOX_BOOK_SEND_URL"`
-			AuthorSendURL   string        `env:"OUTBOX_AUTHOR_SEND_URL"`
-		}{Enabled: true},
+		Outbox: config.Outbox{Enabled: true},
 	}, transactor)
 	assert.NotNil(t, outbox)
+	assert.True(t, outbox.Heartbeat().IsZero())
 
 	ctx, cancelFunc := context.WithCancel(t.Context())
 	wg := outbox.Start(ctx, 2, 2, time.Duration(2), time.Duration(2))
@@ -70,6 +63,8 @@ func TestStart(t *testing.T) {
 	cancelFunc()
 	wg.Wait()
 
+	assert.WithinDuration(t, time.Now(), outbox.Heartbeat(), time.Second)
+
 	secondCtx, secondCancelFunc := context.WithCancel(t.Context())
 	secondWg := outbox.Start(secondCtx, 2, 3, time.Duration(2), time.Duration(2))
 	time.Sleep(2 * time.Second)
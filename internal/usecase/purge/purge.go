@@ -0,0 +1,136 @@
+package purge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/project/library/internal/usecase/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var purgedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "library_purged_total",
+	Help: "Total number of rows removed from the trash and expired idempotency records",
+}, []string{"kind"})
+
+func init() {
+	prometheus.MustRegister(purgedTotal)
+}
+
+// Purge removes books and authors that stayed in the trash longer than the
+// retention period, unless it is zero, and the idempotency records past
+// their TTL.
+type Purge interface {
+	Start(ctx context.Context, interval time.Duration, retention time.Duration) *sync.WaitGroup
+}
+
+var _ Purge = (*purgeImpl)(nil)
+
+type purgeImpl struct {
+	logger                *zap.Logger
+	authorRepository      repository.AuthorRepository
+	booksRepository       repository.BooksRepository
+	idempotencyRepository repository.IdempotencyRepository
+	transactor            repository.Transactor
+}
+
+func New(
+	logger *zap.Logger,
+	authorRepository repository.AuthorRepository,
+	booksRepository repository.BooksRepository,
+	idempotencyRepository repository.IdempotencyRepository,
+	transactor repository.Transactor,
+) *purgeImpl {
+	return &purgeImpl{
+		logger:                logger,
+		authorRepository:      authorRepository,
+		booksRepository:       booksRepository,
+		idempotencyRepository: idempotencyRepository,
+		transactor:            transactor,
+	}
+}
+
+func (p *purgeImpl) Start(ctx context.Context, interval time.Duration, retention time.Duration) *sync.WaitGroup {
+	wg := new(sync.WaitGroup)
+
+	wg.Add(1)
+	go p.worker(ctx, wg, interval, retention)
+
+	return wg
+}
+
+func (p *purgeImpl) worker(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, retention time.Duration) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if retention > 0 {
+			if err := p.purge(ctx, time.Now().Add(-retention)); err != nil {
+				p.logger.Error("purge error", zap.Error(err))
+			}
+		}
+
+		if err := p.purgeIdempotency(ctx); err != nil {
+			p.logger.Error("idempotency purge error", zap.Error(err))
+		}
+	}
+}
+
+// purge runs in one transaction so a failure leaves the trash untouched.
+func (p *purgeImpl) purge(ctx context.Context, before time.Time) error {
+	var books, authors int64
+
+	err := p.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var txErr error
+		books, txErr = p.booksRepository.PurgeDeletedBooks(ctx, before)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		authors, txErr = p.authorRepository.PurgeDeletedAuthors(ctx, before)
+		return txErr
+	})
+
+	if err != nil {
+		return err
+	}
+
+	purgedTotal.WithLabelValues("book").Add(float64(books))
+	purgedTotal.WithLabelValues("author").Add(float64(authors))
+
+	p.logger.Info("trash purged", zap.Int64("books", books), zap.Int64("authors", authors))
+
+	return nil
+}
+
+func (p *purgeImpl) purgeIdempotency(ctx context.Context) error {
+	var records int64
+
+	err := p.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var txErr error
+		records, txErr = p.idempotencyRepository.PurgeExpired(ctx)
+
+		return txErr
+	})
+
+	if err != nil {
+		return err
+	}
+
+	purgedTotal.WithLabelValues("idempotency").Add(float64(records))
+
+	p.logger.Info("idempotency records purged", zap.Int64("records", records))
+
+	return nil
+}
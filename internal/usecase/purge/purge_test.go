@@ -0,0 +1,107 @@
+package purge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/project/library/generated/mocks"
+	"github.com/project/library/internal/usecase/repository"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+type MyTransactor struct {
+}
+
+func (*MyTransactor) WithTx(ctx context.Context, function func(ctx context.Context) error, _ ...repository.TxOption) error {
+	return function(ctx)
+}
+
+func TestPurge(t *testing.T) {
+	t.Parallel()
+
+	purgeError := errors.New("purge error")
+	before := time.Now()
+
+	tests := []struct {
+		name        string
+		prepare     func(authorMock *mocks.MockAuthorRepository, bookMock *mocks.MockBooksRepository)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			prepare: func(authorMock *mocks.MockAuthorRepository, bookMock *mocks.MockBooksRepository) {
+				bookMock.EXPECT().PurgeDeletedBooks(gomock.Any(), before).Return(int64(2), nil)
+				authorMock.EXPECT().PurgeDeletedAuthors(gomock.Any(), before).Return(int64(1), nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "books failure",
+			prepare: func(_ *mocks.MockAuthorRepository, bookMock *mocks.MockBooksRepository) {
+				bookMock.EXPECT().PurgeDeletedBooks(gomock.Any(), before).Return(int64(0), purgeError)
+			},
+			expectedErr: purgeError,
+		},
+		{
+			name: "authors failure",
+			prepare: func(authorMock *mocks.MockAuthorRepository, bookMock *mocks.MockBooksRepository) {
+				bookMock.EXPECT().PurgeDeletedBooks(gomock.Any(), before).Return(int64(2), nil)
+				authorMock.EXPECT().PurgeDeletedAuthors(gomock.Any(), before).Return(int64(0), purgeError)
+			},
+			expectedErr: purgeError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			control := gomock.NewController(t)
+			authorMock := mocks.NewMockAuthorRepository(control)
+			bookMock := mocks.NewMockBooksRepository(control)
+			test.prepare(authorMock, bookMock)
+
+			target := New(zaptest.NewLogger(t), authorMock, bookMock, mocks.NewMockIdempotencyRepository(control), &MyTransactor{})
+			err := target.purge(t.Context(), before)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	authorMock := mocks.NewMockAuthorRepository(control)
+	bookMock := mocks.NewMockBooksRepository(control)
+	idempotencyMock := mocks.NewMockIdempotencyRepository(control)
+
+	bookMock.EXPECT().PurgeDeletedBooks(gomock.Any(), gomock.Any()).Return(int64(0), nil).MinTimes(1)
+	authorMock.EXPECT().PurgeDeletedAuthors(gomock.Any(), gomock.Any()).Return(int64(0), nil).MinTimes(1)
+	idempotencyMock.EXPECT().PurgeExpired(gomock.Any()).Return(int64(0), nil).MinTimes(1)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	wg := New(zaptest.NewLogger(t), authorMock, bookMock, idempotencyMock, &MyTransactor{}).Start(ctx, 10*time.Millisecond, time.Hour)
+	wg.Wait()
+}
+
+func TestStartWithoutRetention(t *testing.T) {
+	t.Parallel()
+
+	control := gomock.NewController(t)
+	idempotencyMock := mocks.NewMockIdempotencyRepository(control)
+
+	idempotencyMock.EXPECT().PurgeExpired(gomock.Any()).Return(int64(2), nil).MinTimes(1)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	wg := New(zaptest.NewLogger(t), mocks.NewMockAuthorRepository(control), mocks.NewMockBooksRepository(control),
+		idempotencyMock, &MyTransactor{}).Start(ctx, 10*time.Millisecond, 0)
+	wg.Wait()
+}
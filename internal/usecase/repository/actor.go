@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type actorInjector struct{}
+
+// ContextWithActor makes the history triggers record actor as the author of
+// the changes made with ctx. Without it they record the database user.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	if actor == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, actorInjector{}, actor)
+}
+
+// setActor must be called right after the transaction begins.
+func setActor(ctx context.Context, tx pgx.Tx) error {
+	actor, ok := ctx.Value(actorInjector{}).(string)
+
+	if !ok {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `SELECT set_config('library.actor', $1, true)`, actor)
+	return err
+}
+
+// beginWithActor begins a top-level transaction and sets its actor.
+func beginWithActor(ctx context.Context, begin func(ctx context.Context) (pgx.Tx, error)) (pgx.Tx, error) {
+	tx, err := begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setActor(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return tx, nil
+}
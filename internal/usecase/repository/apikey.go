@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/entity"
+)
+
+var _ APIKeyRepository = (*apiKeyRepository)(nil)
+
+type apiKeyRepository struct {
+	db MyPgxPool
+}
+
+func NewAPIKey(db MyPgxPool) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (a *apiKeyRepository) CreateAPIKey(ctx context.Context, key entity.APIKey, keyHash string) (entity.APIKey, error) {
+	return myExtractCtx(ctx, a.db, func(tx pgx.Tx) (entity.APIKey, error) {
+		const query = `
+INSERT INTO api_key (id, name, subject, roles, key_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING created_at`
+
+		var expiresAt *time.Time
+
+		if !key.ExpiresAt.IsZero() {
+			expiresAt = &key.ExpiresAt
+		}
+
+		if err := tx.QueryRow(ctx, query, key.ID, key.Name, key.Subject, roleNames(key.Roles), keyHash, expiresAt).Scan(&key.CreatedAt); err != nil {
+			return entity.APIKey{}, err
+		}
+
+		return key, nil
+	})
+}
+
+func (a *apiKeyRepository) GetAPIKey(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	return myExtractCtx(ctx, a.db, func(tx pgx.Tx) (entity.APIKey, error) {
+		const query = `
+SELECT id, name, subject, roles, created_at, expires_at, revoked_at
+FROM api_key
+WHERE key_hash = $1`
+
+		var (
+			key                  entity.APIKey
+			roles                []string
+			expiresAt, revokedAt *time.Time
+		)
+
+		err := tx.QueryRow(ctx, query, keyHash).Scan(&key.ID, &key.Name, &key.Subject, &roles, &key.CreatedAt, &expiresAt, &revokedAt)
+
+		if err != nil {
+			return entity.APIKey{}, changeError(err, entity.ErrAPIKeyNotFound)
+		}
+
+		// A role the service no longer knows grants nothing.
+		for _, name := range roles {
+			if role, err := entity.ParseRole(name); err == nil {
+				key.Roles = append(key.Roles, role)
+			}
+		}
+
+		if expiresAt != nil {
+			key.ExpiresAt = *expiresAt
+		}
+
+		if revokedAt != nil {
+			key.RevokedAt = *revokedAt
+		}
+
+		return key, nil
+	})
+}
+
+// RevokeAPIKey keeps the time of the first revocation.
+func (a *apiKeyRepository) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return myExtractCtxNoT(ctx, a.db, func(tx pgx.Tx) error {
+		const query = `UPDATE api_key SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`
+
+		tag, err := tx.Exec(ctx, query, keyID)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return entity.ErrAPIKeyNotFound
+		}
+
+		return nil
+	})
+}
+
+func roleNames(roles []entity.Role) []string {
+	names := make([]string, len(roles))
+
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+
+	return names
+}
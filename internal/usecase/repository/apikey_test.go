@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	key := entity.APIKey{ID: uuid.NewString(), Name: "ci", Subject: "ci-bot", Roles: []entity.Role{entity.RoleLibrarian}}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("INSERT INTO api_key").
+		WithArgs(key.ID, key.Name, key.Subject, []string{"librarian"}, "hash", (*time.Time)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+	pool.ExpectCommit()
+
+	created, err := NewAPIKey(pool).CreateAPIKey(t.Context(), key, "hash")
+	require.NoError(t, err)
+
+	key.CreatedAt = createdAt
+	require.Equal(t, key, created)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestGetAPIKey(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.NewString()
+	createdAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(time.Hour)
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM api_key").WithArgs("hash").WillReturnRows(
+		pgxmock.NewRows([]string{"id", "name", "subject", "roles", "created_at", "expires_at", "revoked_at"}).
+			AddRow(id, "ci", "ci-bot", []string{"reader", "retired"}, createdAt, &expiresAt, (*time.Time)(nil)),
+	)
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM api_key").WithArgs("missing").WillReturnRows(
+		pgxmock.NewRows([]string{"id", "name", "subject", "roles", "created_at", "expires_at", "revoked_at"}),
+	)
+	pool.ExpectRollback()
+
+	target := NewAPIKey(pool)
+
+	key, err := target.GetAPIKey(t.Context(), "hash")
+	require.NoError(t, err)
+	require.Equal(t, entity.APIKey{ID: id, Name: "ci", Subject: "ci-bot", Roles: []entity.Role{entity.RoleReader}, CreatedAt: createdAt, ExpiresAt: expiresAt}, key)
+	require.True(t, key.Active(createdAt))
+	require.False(t, key.Active(expiresAt))
+
+	_, err = target.GetAPIKey(t.Context(), "missing")
+	require.ErrorIs(t, err, entity.ErrAPIKeyNotFound)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.NewString()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectExec("UPDATE api_key").WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectExec("UPDATE api_key").WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	pool.ExpectRollback()
+
+	target := NewAPIKey(pool)
+
+	require.NoError(t, target.RevokeAPIKey(t.Context(), id))
+	require.ErrorIs(t, target.RevokeAPIKey(t.Context(), id), entity.ErrAPIKeyNotFound)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
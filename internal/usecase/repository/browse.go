@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/entity"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (c *catalogRepository) ListBooks(ctx context.Context, filter entity.BookFilter) ([]entity.BookRecord, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]entity.BookRecord, error) {
+		// The authors of a book are listed by name, their IDs in the same
+		// order.
+		const request = `
+SELECT b.id, b.name, b.isbn, b.publisher, b.created_at, b.updated_at,
+       COALESCE(array_agg(a.id ORDER BY a.sort_key, a.id) FILTER (WHERE a.id IS NOT NULL), '{}'),
+       COALESCE(array_agg(a.name ORDER BY a.sort_key, a.id) FILTER (WHERE a.id IS NOT NULL), '{}')
+FROM book b
+LEFT JOIN author_book ab ON ab.book_id = b.id
+LEFT JOIN author a ON a.id = ab.author_id AND a.deleted_at IS NULL
+WHERE b.deleted_at IS NULL
+  AND ($1::uuid IS NULL OR EXISTS (SELECT 1 FROM author_book f WHERE f.book_id = b.id AND f.author_id = $1))
+  AND ($2::text IS NULL
+    OR lower(b.name) LIKE $2
+    OR EXISTS (SELECT 1
+               FROM author_book s
+               JOIN author sa ON sa.id = s.author_id AND sa.deleted_at IS NULL
+               WHERE s.book_id = b.id AND (sa.search_key LIKE $3 OR sa.alternate_search_key LIKE $3)))
+GROUP BY b.id
+ORDER BY b.created_at DESC, b.id
+OFFSET $4 LIMIT $5`
+
+		var authorID, pattern, authorPattern *string
+
+		if filter.AuthorID != "" {
+			authorID = &filter.AuthorID
+		}
+
+		if query := entity.NormalizeAuthorName(filter.Query); query != "" {
+			contains := "%" + likeEscaper.Replace(query) + "%"
+			pattern = &contains
+
+			// Authors are searched by their names and alternate names in
+			// ASCII, Достоевский is found as dostoevsky.
+			authorContains := "%" + likeEscaper.Replace(entity.AuthorSearchKey(filter.Query)) + "%"
+			authorPattern = &authorContains
+		}
+
+		rows, err := tx.Query(ctx, request, authorID, pattern, authorPattern, filter.Offset, filter.Limit)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.BookRecord, error) {
+			var record entity.BookRecord
+
+			err := row.Scan(
+				&record.ID, &record.Name, &record.ISBN, &record.Publisher, &record.CreatedAt, &record.UpdatedAt,
+				&record.AuthorIDs, &record.Authors,
+			)
+			record.Datestamp = record.UpdatedAt
+
+			return record, err
+		})
+	})
+}
+
+func (c *catalogRepository) ListAuthors(ctx context.Context, offset int, limit int) ([]entity.Author, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]entity.Author, error) {
+		const request = `
+SELECT id, name, created_at, updated_at
+FROM author
+WHERE deleted_at IS NULL
+ORDER BY sort_key, id
+OFFSET $1 LIMIT $2`
+
+		rows, err := tx.Query(ctx, request, offset, limit)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Author, error) {
+			var author entity.Author
+			err := row.Scan(&author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt)
+
+			return author, err
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBooks(t *testing.T) {
+	t.Parallel()
+
+	changed := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	bookID, authorID := uuid.NewString(), uuid.NewString()
+	columns := []string{"id", "name", "isbn", "publisher", "created_at", "updated_at", "author_ids", "authors"}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM book b").WithArgs((*string)(nil), (*string)(nil), (*string)(nil), 0, 10).WillReturnRows(
+		pgxmock.NewRows(columns).AddRow(bookID, "War and Peace", "", "", changed, changed, []string{authorID}, []string{"Leo Tolstoy"}),
+	)
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM book b").WithArgs(&authorID, ptr(`%50\% \_off%`), ptr(`%50 off%`), 20, 10).WillReturnRows(pgxmock.NewRows(columns))
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectQuery("sa.search_key LIKE").WithArgs((*string)(nil), ptr("%фёдор достоевский%"), ptr("%fyodor dostoevsky%"), 0, 10).
+		WillReturnRows(pgxmock.NewRows(columns))
+	pool.ExpectCommit()
+
+	target := NewCatalog(pool)
+
+	books, err := target.ListBooks(t.Context(), entity.BookFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []entity.BookRecord{{
+		Book: entity.Book{
+			ID:        bookID,
+			Name:      "War and Peace",
+			AuthorIDs: []string{authorID},
+			CreatedAt: changed,
+			UpdatedAt: changed,
+		},
+		Authors:   []string{"Leo Tolstoy"},
+		Datestamp: changed,
+	}}, books)
+
+	books, err = target.ListBooks(t.Context(), entity.BookFilter{AuthorID: authorID, Query: "  50%  _OFF ", Offset: 20, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, books)
+
+	books, err = target.ListBooks(t.Context(), entity.BookFilter{Query: "Фёдор Достоевский", Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, books)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestListAuthors(t *testing.T) {
+	t.Parallel()
+
+	changed := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.NewString()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("ORDER BY sort_key, id").WithArgs(5, 5).WillReturnRows(
+		pgxmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).AddRow(id, "Leo Tolstoy", changed, changed),
+	)
+	pool.ExpectCommit()
+
+	authors, err := NewCatalog(pool).ListAuthors(t.Context(), 5, 5)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Author{{ID: id, Name: "Leo Tolstoy", CreatedAt: changed, UpdatedAt: changed}}, authors)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
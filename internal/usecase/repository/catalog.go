@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/entity"
+)
+
+var _ CatalogRepository = (*catalogRepository)(nil)
+
+type catalogRepository struct {
+	db      MyPgxPool
+	options options
+}
+
+func NewCatalog(db MyPgxPool, opts ...Option) *catalogRepository {
+	return &catalogRepository{
+		db:      db,
+		options: newOptions(opts),
+	}
+}
+
+func (c *catalogRepository) FindAuthorsByName(ctx context.Context, nameKeys []string) (map[string]string, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (map[string]string, error) {
+		// FOR SHARE keeps the authors from being deleted before the batch
+		// links books to them; it rules out DISTINCT ON, so the oldest author
+		// of a name is picked here.
+		const query = `
+SELECT name_key, id
+FROM author
+WHERE name_key = ANY($1) AND deleted_at IS NULL
+ORDER BY name_key, created_at, id
+FOR SHARE`
+
+		rows, err := tx.Query(ctx, query, nameKeys)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer rows.Close()
+
+		result := make(map[string]string, len(nameKeys))
+
+		for rows.Next() {
+			var nameKey, id string
+
+			if err := rows.Scan(&nameKey, &id); err != nil {
+				return nil, err
+			}
+
+			if _, ok := result[nameKey]; !ok {
+				result[nameKey] = id
+			}
+		}
+
+		return result, rows.Err()
+	})
+}
+
+func (c *catalogRepository) FindBookIDs(ctx context.Context, bookIDs []string) ([]string, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]string, error) {
+		const query = `
+SELECT id FROM book WHERE id = ANY($1)
+UNION
+SELECT source_id FROM book_redirect WHERE source_id = ANY($1)`
+
+		rows, err := tx.Query(ctx, query, bookIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, pgx.RowTo[string])
+	})
+}
+
+func (c *catalogRepository) FindAuthorIDs(ctx context.Context, authorIDs []string) (map[string]bool, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (map[string]bool, error) {
+		const query = `
+SELECT id, deleted_at IS NULL FROM author WHERE id = ANY($1)
+UNION ALL
+SELECT source_id, false FROM author_redirect WHERE source_id = ANY($1)`
+
+		rows, err := tx.Query(ctx, query, authorIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer rows.Close()
+
+		result := make(map[string]bool, len(authorIDs))
+
+		for rows.Next() {
+			var (
+				id   string
+				live bool
+			)
+
+			if err := rows.Scan(&id, &live); err != nil {
+				return nil, err
+			}
+
+			// A merged author may still have its row, the redirect wins.
+			if linkable, ok := result[id]; !ok || linkable {
+				result[id] = live
+			}
+		}
+
+		return result, rows.Err()
+	})
+}
+
+func (c *catalogRepository) FindAuthorNameClashes(ctx context.Context, authors []entity.Author) (map[string]string, error) {
+	result := make(map[string]string)
+
+	if !c.options.uniqueAuthorNames || len(authors) == 0 {
+		return result, nil
+	}
+
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (map[string]string, error) {
+		nameKeys := make([]string, 0, len(authors))
+
+		for _, author := range authors {
+			nameKeys = append(nameKeys, entity.NormalizeAuthorName(author.Name))
+		}
+
+		// Sorted locks keep two imports of the same names from deadlocking;
+		// the keys are the ones checkAuthorNameKey takes.
+		nameKeys = slices.Compact(slices.Sorted(slices.Values(nameKeys)))
+
+		const lock = `
+SELECT pg_advisory_xact_lock(hashtextextended('author:' || name_key, 0))
+FROM unnest($1::text[]) WITH ORDINALITY AS keys (name_key, position)
+ORDER BY position`
+
+		if _, err := tx.Exec(ctx, lock, nameKeys); err != nil {
+			return nil, err
+		}
+
+		const query = `
+SELECT name_key, id
+FROM author
+WHERE name_key = ANY($1) AND deleted_at IS NULL
+ORDER BY name_key, created_at, id`
+
+		rows, err := tx.Query(ctx, query, nameKeys)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer rows.Close()
+
+		holders := make(map[string]string, len(nameKeys))
+
+		for rows.Next() {
+			var nameKey, id string
+
+			if err := rows.Scan(&nameKey, &id); err != nil {
+				return nil, err
+			}
+
+			if _, ok := holders[nameKey]; !ok {
+				holders[nameKey] = id
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, author := range authors {
+			nameKey := entity.NormalizeAuthorName(author.Name)
+
+			if id, ok := holders[nameKey]; ok {
+				result[author.ID] = id
+				continue
+			}
+
+			holders[nameKey] = author.ID
+		}
+
+		return result, nil
+	})
+}
+
+func (c *catalogRepository) ImportAuthors(ctx context.Context, authors []entity.Author) ([]entity.Author, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]entity.Author, error) {
+		now, err := transactionTime(ctx, tx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]entity.Author, 0, len(authors))
+		rows := make([][]any, 0, len(authors))
+
+		for _, author := range authors {
+			author.CreatedAt, author.UpdatedAt = now, now
+			result = append(result, author)
+			rows = append(rows, []any{
+				author.ID, author.Name, entity.NormalizeAuthorName(author.Name),
+				entity.AuthorSortKey(author.Name), entity.AuthorSearchKey(author.Name), now, now,
+			})
+		}
+
+		columns := []string{"id", "name", "name_key", "sort_key", "search_key", "created_at", "updated_at"}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"author"}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return nil, changeUniqueError(err, entity.ErrAuthorAlreadyExists)
+		}
+
+		return result, nil
+	})
+}
+
+func (c *catalogRepository) ImportBooks(ctx context.Context, books []entity.Book) ([]entity.Book, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]entity.Book, error) {
+		now, err := transactionTime(ctx, tx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]entity.Book, 0, len(books))
+		bookRows := make([][]any, 0, len(books))
+		authorBookRows := make([][]any, 0, len(books))
+
+		for _, book := range books {
+			book.CreatedAt, book.UpdatedAt = now, now
+			result = append(result, book)
+			bookRows = append(bookRows, []any{book.ID, book.Name, book.ISBN, book.Publisher, now, now})
+
+			for _, authorID := range book.AuthorIDs {
+				authorBookRows = append(authorBookRows, []any{authorID, book.ID})
+			}
+		}
+
+		columns := []string{"id", "name", "isbn", "publisher", "created_at", "updated_at"}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"book"}, columns, pgx.CopyFromRows(bookRows)); err != nil {
+			return nil, changeUniqueError(err, entity.ErrBookAlreadyExists)
+		}
+
+		if len(authorBookRows) == 0 {
+			return result, nil
+		}
+
+		columns = []string{"author_id", "book_id"}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"author_book"}, columns, pgx.CopyFromRows(authorBookRows)); err != nil {
+			return nil, changeUnknownError(err)
+		}
+
+		return result, nil
+	})
+}
+
+func (c *catalogRepository) GetImportCheckpoint(ctx context.Context, importID string) (int, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (int, error) {
+		const query = `SELECT row_number FROM import_checkpoint WHERE import_id = $1`
+
+		var row int
+
+		if err := tx.QueryRow(ctx, query, importID).Scan(&row); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, err
+		}
+
+		return row, nil
+	})
+}
+
+func (c *catalogRepository) SaveImportCheckpoint(ctx context.Context, importID string, row int) error {
+	return myExtractCtxNoT(ctx, c.db, func(tx pgx.Tx) error {
+		const query = `
+INSERT INTO import_checkpoint (import_id, row_number)
+VALUES ($1, $2)
+ON CONFLICT (import_id) DO UPDATE
+SET row_number = excluded.row_number, updated_at = now()
+WHERE import_checkpoint.row_number < excluded.row_number`
+
+		tag, err := tx.Exec(ctx, query, importID, row)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return entity.ErrImportCheckpointConflict
+		}
+
+		return nil
+	})
+}
+
+func (c *catalogRepository) ExportAuthors(ctx context.Context, fn func(entity.Author) error) error {
+	return myExtractCtxNoT(ctx, c.db, func(tx pgx.Tx) error {
+		const query = `
+SELECT id, name, created_at, updated_at
+FROM author
+WHERE deleted_at IS NULL
+ORDER BY id`
+
+		rows, err := tx.Query(ctx, query)
+
+		if err != nil {
+			return err
+		}
+
+		var author entity.Author
+
+		_, err = pgx.ForEachRow(rows, []any{&author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt}, func() error {
+			return fn(author)
+		})
+
+		return err
+	})
+}
+
+func (c *catalogRepository) ExportBooks(ctx context.Context, fn func(entity.Book) error) error {
+	return myExtractCtxNoT(ctx, c.db, func(tx pgx.Tx) error {
+		const query = `
+SELECT b.id, b.name, b.isbn, b.publisher, b.created_at, b.updated_at,
+       COALESCE(array_agg(a.id ORDER BY a.id) FILTER (WHERE a.id IS NOT NULL), '{}')
+FROM book b
+LEFT JOIN author_book ab ON ab.book_id = b.id
+LEFT JOIN author a ON a.id = ab.author_id AND a.deleted_at IS NULL
+WHERE b.deleted_at IS NULL
+GROUP BY b.id
+ORDER BY b.id`
+
+		rows, err := tx.Query(ctx, query)
+
+		if err != nil {
+			return err
+		}
+
+		var book entity.Book
+
+		_, err = pgx.ForEachRow(rows, []any{&book.ID, &book.Name, &book.ISBN, &book.Publisher, &book.CreatedAt, &book.UpdatedAt, &book.AuthorIDs}, func() error {
+			return fn(book)
+		})
+
+		return err
+	})
+}
+
+// transactionTime is the now() the column defaults of the transaction get.
+func transactionTime(ctx context.Context, tx pgx.Tx) (time.Time, error) {
+	var now time.Time
+
+	if err := tx.QueryRow(ctx, `SELECT now()::timestamp`).Scan(&now); err != nil {
+		return time.Time{}, err
+	}
+
+	return now, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAuthorsByName(t *testing.T) {
+	t.Parallel()
+
+	oldest, newest := uuid.NewString(), uuid.NewString()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM author").WithArgs([]string{"leo tolstoy", "anna"}).WillReturnRows(
+		pgxmock.NewRows([]string{"name_key", "id"}).
+			AddRow("leo tolstoy", oldest).
+			AddRow("leo tolstoy", newest),
+	)
+	pool.ExpectCommit()
+
+	found, err := NewCatalog(pool).FindAuthorsByName(t.Context(), []string{"leo tolstoy", "anna"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"leo tolstoy": oldest}, found)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestFindAuthorIDs(t *testing.T) {
+	t.Parallel()
+
+	live, trashed, merged := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	ids := []string{live, trashed, merged, uuid.NewString()}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM author_redirect").WithArgs(ids).WillReturnRows(
+		pgxmock.NewRows([]string{"id", "live"}).
+			AddRow(live, true).
+			AddRow(trashed, false).
+			AddRow(merged, true).
+			AddRow(merged, false),
+	)
+	pool.ExpectCommit()
+
+	found, err := NewCatalog(pool).FindAuthorIDs(t.Context(), ids)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{live: true, trashed: false, merged: false}, found)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestFindAuthorNameClashes(t *testing.T) {
+	t.Parallel()
+
+	existing, first, second, other := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	authors := []entity.Author{
+		{ID: first, Name: "Anna"},
+		{ID: second, Name: "ANNA"},
+		{ID: other, Name: "Leo Tolstoy"},
+	}
+
+	pool := getPgxMockPool(t)
+
+	found, err := NewCatalog(pool).FindAuthorNameClashes(t.Context(), authors)
+	require.NoError(t, err)
+	require.Empty(t, found, "names may repeat")
+
+	pool.ExpectBegin()
+	pool.ExpectExec("pg_advisory_xact_lock").WithArgs([]string{"anna", "leo tolstoy"}).WillReturnResult(pgxmock.NewResult("SELECT", 2))
+	pool.ExpectQuery("FROM author").WithArgs([]string{"anna", "leo tolstoy"}).WillReturnRows(
+		pgxmock.NewRows([]string{"name_key", "id"}).AddRow("leo tolstoy", existing),
+	)
+	pool.ExpectCommit()
+
+	found, err = NewCatalog(pool, WithUniqueAuthorNames(true)).FindAuthorNameClashes(t.Context(), authors)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{second: first, other: existing}, found)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestImportBooks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	authorID := uuid.NewString()
+	books := []entity.Book{
+		{ID: uuid.NewString(), Name: "War and Peace", AuthorIDs: []string{authorID}},
+		{ID: uuid.NewString(), Name: "Anonymous", AuthorIDs: []string{}},
+	}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("SELECT now()").WillReturnRows(pgxmock.NewRows([]string{"now"}).AddRow(now))
+	pool.ExpectCopyFrom(pgx.Identifier{"book"}, []string{"id", "name", "isbn", "publisher", "created_at", "updated_at"}).WillReturnResult(2)
+	pool.ExpectCopyFrom(pgx.Identifier{"author_book"}, []string{"author_id", "book_id"}).WillReturnResult(1)
+	pool.ExpectCommit()
+
+	imported, err := NewCatalog(pool).ImportBooks(t.Context(), books)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+
+	for i, book := range imported {
+		require.Equal(t, books[i].ID, book.ID)
+		require.Equal(t, now, book.CreatedAt)
+		require.Equal(t, now, book.UpdatedAt)
+	}
+
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestImportCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM import_checkpoint").WithArgs("import").WillReturnRows(pgxmock.NewRows([]string{"row_number"}))
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectExec("INSERT INTO import_checkpoint").WithArgs("import", 10).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectExec("INSERT INTO import_checkpoint").WithArgs("import", 10).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	pool.ExpectRollback()
+
+	target := NewCatalog(pool)
+
+	row, err := target.GetImportCheckpoint(t.Context(), "import")
+	require.NoError(t, err)
+	require.Zero(t, row)
+
+	require.NoError(t, target.SaveImportCheckpoint(t.Context(), "import", 10))
+	require.ErrorIs(t, target.SaveImportCheckpoint(t.Context(), "import", 10), entity.ErrImportCheckpointConflict)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestExportBooks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	authorID := uuid.NewString()
+	expected := []entity.Book{
+		{ID: uuid.NewString(), Name: "War and Peace", AuthorIDs: []string{authorID}, ISBN: "9780140447934", Publisher: "Penguin", CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), Name: "Untitled", AuthorIDs: []string{}, CreatedAt: now, UpdatedAt: now},
+	}
+
+	rows := pgxmock.NewRows([]string{"id", "name", "isbn", "publisher", "created_at", "updated_at", "author_ids"})
+	for _, book := range expected {
+		rows.AddRow(book.ID, book.Name, book.ISBN, book.Publisher, book.CreatedAt, book.UpdatedAt, book.AuthorIDs)
+	}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM book b").WillReturnRows(rows)
+	pool.ExpectCommit()
+
+	var books []entity.Book
+
+	err := NewCatalog(pool).ExportBooks(t.Context(), func(book entity.Book) error {
+		books = append(books, book)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, books)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/entity"
+)
+
+// bookRecords lists the books with the datestamps harvesters see, the
+// deleted ones included: a book in the trash keeps its row, a purged or
+// merged book is known by the DELETE in its history.
+const bookRecords = `
+WITH record AS (
+    SELECT id, updated_at AS datestamp, deleted_at IS NOT NULL AS deleted
+    FROM book
+    UNION ALL
+    SELECT h.book_id, max(h.changed_at), true
+    FROM book_history h
+    WHERE h.operation = 'DELETE'
+      AND NOT EXISTS (SELECT 1 FROM book WHERE id = h.book_id)
+    GROUP BY h.book_id
+)
+SELECT r.id, r.datestamp, r.deleted,
+       COALESCE(b.name, ''), COALESCE(b.isbn, ''), COALESCE(b.publisher, ''),
+       COALESCE(b.created_at, r.datestamp), COALESCE(b.updated_at, r.datestamp),
+       COALESCE(array_agg(a.name ORDER BY a.name) FILTER (WHERE a.id IS NOT NULL), '{}')
+FROM record r
+LEFT JOIN book b ON b.id = r.id AND NOT r.deleted
+LEFT JOIN author_book ab ON ab.book_id = b.id
+LEFT JOIN author a ON a.id = ab.author_id AND a.deleted_at IS NULL
+`
+
+func (c *catalogRepository) ListBookRecords(ctx context.Context, query entity.HarvestQuery) ([]entity.BookRecord, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) ([]entity.BookRecord, error) {
+		const request = bookRecords + `
+WHERE r.datestamp >= $1
+  AND ($2::timestamp IS NULL OR r.datestamp < $2)
+  AND (r.datestamp, r.id) > ($3, $4)
+GROUP BY r.id, r.datestamp, r.deleted, b.id
+ORDER BY r.datestamp, r.id
+LIMIT $5`
+
+		var until *time.Time
+
+		if !query.Until.IsZero() {
+			until = &query.Until
+		}
+
+		afterID := query.After.ID
+
+		if afterID == "" {
+			afterID = uuid.Nil.String()
+		}
+
+		rows, err := tx.Query(ctx, request, query.From, until, query.After.Datestamp, afterID, query.Limit)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, scanBookRecord)
+	})
+}
+
+func (c *catalogRepository) GetBookRecord(ctx context.Context, bookID string) (entity.BookRecord, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (entity.BookRecord, error) {
+		const request = bookRecords + `
+WHERE r.id = $1
+GROUP BY r.id, r.datestamp, r.deleted, b.id`
+
+		rows, err := tx.Query(ctx, request, bookID)
+
+		if err != nil {
+			return entity.BookRecord{}, err
+		}
+
+		record, err := pgx.CollectExactlyOneRow(rows, scanBookRecord)
+
+		return record, changeError(err, entity.ErrBookNotFound)
+	})
+}
+
+func (c *catalogRepository) EarliestDatestamp(ctx context.Context) (time.Time, error) {
+	return myExtractCtx(ctx, c.db, func(tx pgx.Tx) (time.Time, error) {
+		// Every book starts its history with an INSERT, so no datestamp is
+		// older than the oldest history entry.
+		const request = `SELECT COALESCE(min(changed_at), now()::timestamp) FROM book_history`
+
+		var earliest time.Time
+		err := tx.QueryRow(ctx, request).Scan(&earliest)
+
+		return earliest, err
+	})
+}
+
+func scanBookRecord(row pgx.CollectableRow) (entity.BookRecord, error) {
+	var record entity.BookRecord
+
+	err := row.Scan(
+		&record.ID, &record.Datestamp, &record.Deleted,
+		&record.Name, &record.ISBN, &record.Publisher,
+		&record.CreatedAt, &record.UpdatedAt,
+		&record.Authors,
+	)
+
+	return record, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+var bookRecordColumns = []string{"id", "datestamp", "deleted", "name", "isbn", "publisher", "created_at", "updated_at", "authors"}
+
+func TestListBookRecords(t *testing.T) {
+	t.Parallel()
+
+	changed := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	from := changed.Add(-time.Hour)
+	until := changed.Add(time.Hour)
+	live, deleted := uuid.NewString(), uuid.NewString()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM record r").WithArgs(from, &until, time.Time{}, uuid.Nil.String(), 10).WillReturnRows(
+		pgxmock.NewRows(bookRecordColumns).
+			AddRow(live, changed, false, "War and Peace", "9780140447934", "Penguin", changed, changed, []string{"Leo Tolstoy"}).
+			AddRow(deleted, changed, true, "", "", "", changed, changed, []string{}),
+	)
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM record r").WithArgs(time.Time{}, (*time.Time)(nil), changed, live, 10).WillReturnRows(pgxmock.NewRows(bookRecordColumns))
+	pool.ExpectCommit()
+
+	target := NewCatalog(pool)
+
+	records, err := target.ListBookRecords(t.Context(), entity.HarvestQuery{From: from, Until: until, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []entity.BookRecord{
+		{
+			Book:      entity.Book{ID: live, Name: "War and Peace", ISBN: "9780140447934", Publisher: "Penguin", CreatedAt: changed, UpdatedAt: changed},
+			Authors:   []string{"Leo Tolstoy"},
+			Datestamp: changed,
+		},
+		{
+			Book:      entity.Book{ID: deleted, CreatedAt: changed, UpdatedAt: changed},
+			Authors:   []string{},
+			Deleted:   true,
+			Datestamp: changed,
+		},
+	}, records)
+
+	records, err = target.ListBookRecords(t.Context(), entity.HarvestQuery{After: entity.HarvestCursor{Datestamp: changed, ID: live}, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, records)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestGetBookRecord(t *testing.T) {
+	t.Parallel()
+
+	changed := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.NewString()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("WHERE r.id = \\$1").WithArgs(id).WillReturnRows(
+		pgxmock.NewRows(bookRecordColumns).AddRow(id, changed, true, "", "", "", changed, changed, []string{}),
+	)
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectQuery("WHERE r.id = \\$1").WithArgs(id).WillReturnRows(pgxmock.NewRows(bookRecordColumns))
+	pool.ExpectRollback()
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM book_history").WillReturnRows(pgxmock.NewRows([]string{"min"}).AddRow(changed))
+	pool.ExpectCommit()
+
+	target := NewCatalog(pool)
+
+	record, err := target.GetBookRecord(t.Context(), id)
+	require.NoError(t, err)
+	require.True(t, record.Deleted)
+	require.Equal(t, changed, record.Datestamp)
+
+	_, err = target.GetBookRecord(t.Context(), id)
+	require.ErrorIs(t, err, entity.ErrBookNotFound)
+
+	earliest, err := target.EarliestDatestamp(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, changed, earliest)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/project/library/internal/entity"
+)
+
+var _ HistoryRepository = (*historyRepository)(nil)
+
+type historyRepository struct {
+	db MyPgxPool
+}
+
+func NewHistory(db MyPgxPool) *historyRepository {
+	return &historyRepository{
+		db: db,
+	}
+}
+
+func (h *historyRepository) GetBookHistory(ctx context.Context, bookID string, limit int) ([]entity.HistoryEntry, error) {
+	return myExtractCtx(ctx, h.db, func(tx pgx.Tx) ([]entity.HistoryEntry, error) {
+		return getHistory(ctx, tx, "book", "author", bookID, limit)
+	})
+}
+
+func (h *historyRepository) GetAuthorHistory(ctx context.Context, authorID string, limit int) ([]entity.HistoryEntry, error) {
+	return myExtractCtx(ctx, h.db, func(tx pgx.Tx) ([]entity.HistoryEntry, error) {
+		return getHistory(ctx, tx, "author", "book", authorID, limit)
+	})
+}
+
+// getHistory merges the changes of the row with the changes of its links to
+// the other table; the shared sequence orders them.
+func getHistory(ctx context.Context, tx pgx.Tx, table string, linked string, id string, limit int) ([]entity.HistoryEntry, error) {
+	const request = `
+SELECT id, operation, actor, changed_at, diff, NULL::uuid
+FROM %[1]s_history
+WHERE %[1]s_id = $1
+UNION ALL
+SELECT id, operation, actor, changed_at, NULL::jsonb, %[2]s_id
+FROM author_book_history
+WHERE %[1]s_id = $1
+ORDER BY id DESC
+LIMIT $2`
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(request, table, linked), id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.HistoryEntry, error) {
+		var (
+			entry     entity.HistoryEntry
+			historyID int64
+			operation string
+			diff      []byte
+			linkedID  *string
+		)
+
+		if err := row.Scan(&historyID, &operation, &entry.Actor, &entry.ChangedAt, &diff, &linkedID); err != nil {
+			return entity.HistoryEntry{}, err
+		}
+
+		if linkedID != nil {
+			return linkEntry(entry, operation, linked+"_id", *linkedID), nil
+		}
+
+		entry.Operation = rowOperations[operation]
+		entry.Changes, err = parseDiff(diff)
+		return entry, err
+	})
+}
+
+var rowOperations = map[string]entity.HistoryOperation{
+	"INSERT": entity.HistoryOperationCreate,
+	"UPDATE": entity.HistoryOperationUpdate,
+	"DELETE": entity.HistoryOperationDelete,
+}
+
+func linkEntry(entry entity.HistoryEntry, operation string, field string, linkedID string) entity.HistoryEntry {
+	value := `"` + linkedID + `"`
+
+	if operation == "DELETE" {
+		entry.Operation = entity.HistoryOperationUnlink
+		entry.Changes = []entity.FieldChange{{Field: field, Old: value}}
+	} else {
+		entry.Operation = entity.HistoryOperationLink
+		entry.Changes = []entity.FieldChange{{Field: field, New: value}}
+	}
+
+	return entry
+}
+
+// parseDiff reads {"field": {"old": ..., "new": ...}} written by history_diff.
+func parseDiff(diff []byte) ([]entity.FieldChange, error) {
+	var fields map[string]struct {
+		Old json.RawMessage `json:"old"`
+		New json.RawMessage `json:"new"`
+	}
+
+	if err := json.Unmarshal(diff, &fields); err != nil {
+		return nil, err
+	}
+
+	changes := make([]entity.FieldChange, 0, len(fields))
+	for field, change := range fields {
+		changes = append(changes, entity.FieldChange{
+			Field: field,
+			Old:   jsonValue(change.Old),
+			New:   jsonValue(change.New),
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b entity.FieldChange) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return changes, nil
+}
+
+func jsonValue(raw json.RawMessage) string {
+	if string(raw) == "null" {
+		return ""
+	}
+
+	return string(raw)
+}
+
+// GetBookAsOf follows the redirect of a book merged before asOf.
+func (h *historyRepository) GetBookAsOf(ctx context.Context, bookID string, asOf time.Time) (entity.Book, error) {
+	return myExtractCtx(ctx, h.db, func(tx pgx.Tx) (entity.Book, error) {
+		book, err := getBookAsOf(ctx, tx, bookID, asOf)
+
+		if !errors.Is(err, entity.ErrBookNotFound) {
+			return book, err
+		}
+
+		const redirect = `SELECT target_id FROM book_redirect WHERE source_id = $1`
+
+		var targetID string
+		if redirectErr := tx.QueryRow(ctx, redirect, bookID).Scan(&targetID); redirectErr != nil {
+			return entity.Book{}, changeError(redirectErr, entity.ErrBookNotFound)
+		}
+
+		return getBookAsOf(ctx, tx, targetID, asOf)
+	})
+}
+
+func getBookAsOf(ctx context.Context, tx pgx.Tx, bookID string, asOf time.Time) (entity.Book, error) {
+	const request = `
+SELECT operation,
+       state ->> 'name',
+       COALESCE(state ->> 'isbn', ''),
+       COALESCE(state ->> 'publisher', ''),
+       (state ->> 'created_at')::timestamp,
+       (state ->> 'updated_at')::timestamp,
+       state ->> 'deleted_at' IS NOT NULL
+FROM book_history
+WHERE book_id = $1 AND changed_at <= $2
+ORDER BY changed_at DESC, id DESC
+LIMIT 1`
+
+	asOf = asOf.UTC()
+
+	var (
+		operation string
+		deleted   bool
+	)
+
+	book := entity.Book{ID: bookID}
+
+	err := tx.QueryRow(ctx, request, bookID, asOf).Scan(&operation, &book.Name, &book.ISBN, &book.Publisher, &book.CreatedAt, &book.UpdatedAt, &deleted)
+	if err != nil {
+		return entity.Book{}, changeError(err, entity.ErrBookNotFound)
+	}
+
+	if operation == "DELETE" || deleted {
+		return entity.Book{}, entity.ErrBookNotFound
+	}
+
+	const authorsRequest = `
+SELECT author_id
+FROM (SELECT DISTINCT ON (author_id) author_id, operation
+      FROM author_book_history
+      WHERE book_id = $1 AND changed_at <= $2
+      ORDER BY author_id, changed_at DESC, id DESC) AS latest
+WHERE operation = 'INSERT'
+ORDER BY author_id`
+
+	rows, err := tx.Query(ctx, authorsRequest, bookID, asOf)
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	book.AuthorIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	return book, nil
+}
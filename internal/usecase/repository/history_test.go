@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBookHistory(t *testing.T) {
+	t.Parallel()
+
+	bookID := uuid.NewString()
+	authorID := uuid.NewString()
+	changedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectQuery("FROM book_history").WithArgs(bookID, 10).WillReturnRows(
+		pgxmock.NewRows([]string{"id", "operation", "actor", "changed_at", "diff", "linked_id"}).
+			AddRow(int64(3), "DELETE", "bob", changedAt, nil, &authorID).
+			AddRow(int64(2), "UPDATE", "alice", changedAt, []byte(`{"name": {"old": "Old", "new": "New"}}`), nil).
+			AddRow(int64(1), "INSERT", "alice", changedAt, []byte(`{"name": {"old": null, "new": "Old"}, "id": {"old": null, "new": "`+bookID+`"}}`), nil),
+	)
+	pool.ExpectCommit()
+
+	entries, err := NewHistory(pool).GetBookHistory(t.Context(), bookID, 10)
+	require.NoError(t, err)
+	require.Equal(t, []entity.HistoryEntry{
+		{
+			Operation: entity.HistoryOperationUnlink,
+			Actor:     "bob",
+			ChangedAt: changedAt,
+			Changes:   []entity.FieldChange{{Field: "author_id", Old: `"` + authorID + `"`}},
+		},
+		{
+			Operation: entity.HistoryOperationUpdate,
+			Actor:     "alice",
+			ChangedAt: changedAt,
+			Changes:   []entity.FieldChange{{Field: "name", Old: `"Old"`, New: `"New"`}},
+		},
+		{
+			Operation: entity.HistoryOperationCreate,
+			Actor:     "alice",
+			ChangedAt: changedAt,
+			Changes: []entity.FieldChange{
+				{Field: "id", New: `"` + bookID + `"`},
+				{Field: "name", New: `"Old"`},
+			},
+		},
+	}, entries)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestGetBookAsOf(t *testing.T) {
+	t.Parallel()
+
+	bookID := uuid.NewString()
+	targetID := uuid.NewString()
+	authorID := uuid.NewString()
+	createdAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	asOf := createdAt.Add(time.Hour)
+
+	stateColumns := []string{"operation", "name", "isbn", "publisher", "created_at", "updated_at", "deleted"}
+
+	tests := []struct {
+		name        string
+		pool        func(t *testing.T) pgxmock.PgxPoolIface
+		expected    entity.Book
+		expectedErr error
+	}{
+		{
+			name: "existing book",
+			pool: func(t *testing.T) pgxmock.PgxPoolIface {
+				pool := getPgxMockPool(t)
+				pool.ExpectBegin()
+				pool.ExpectQuery("FROM book_history").WithArgs(bookID, asOf).
+					WillReturnRows(pgxmock.NewRows(stateColumns).AddRow("UPDATE", "Old", "", "", createdAt, createdAt, false))
+				pool.ExpectQuery("FROM author_book_history").WithArgs(bookID, asOf).
+					WillReturnRows(pgxmock.NewRows([]string{"author_id"}).AddRow(authorID))
+				pool.ExpectCommit()
+				return pool
+			},
+			expected: entity.Book{
+				ID:        bookID,
+				Name:      "Old",
+				AuthorIDs: []string{authorID},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+		},
+		{
+			name: "merged book",
+			pool: func(t *testing.T) pgxmock.PgxPoolIface {
+				pool := getPgxMockPool(t)
+				pool.ExpectBegin()
+				pool.ExpectQuery("FROM book_history").WithArgs(bookID, asOf).
+					WillReturnRows(pgxmock.NewRows(stateColumns).AddRow("DELETE", "Old", "", "", createdAt, createdAt, false))
+				pool.ExpectQuery("FROM book_redirect").WithArgs(bookID).
+					WillReturnRows(pgxmock.NewRows([]string{"target_id"}).AddRow(targetID))
+				pool.ExpectQuery("FROM book_history").WithArgs(targetID, asOf).
+					WillReturnRows(pgxmock.NewRows(stateColumns).AddRow("INSERT", "Target", "9780140447934", "Penguin", createdAt, createdAt, false))
+				pool.ExpectQuery("FROM author_book_history").WithArgs(targetID, asOf).
+					WillReturnRows(pgxmock.NewRows([]string{"author_id"}))
+				pool.ExpectCommit()
+				return pool
+			},
+			expected: entity.Book{
+				ID:        targetID,
+				Name:      "Target",
+				ISBN:      "9780140447934",
+				Publisher: "Penguin",
+				AuthorIDs: []string{},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+		},
+		{
+			name: "book in the trash",
+			pool: func(t *testing.T) pgxmock.PgxPoolIface {
+				pool := getPgxMockPool(t)
+				pool.ExpectBegin()
+				pool.ExpectQuery("FROM book_history").WithArgs(bookID, asOf).
+					WillReturnRows(pgxmock.NewRows(stateColumns).AddRow("UPDATE", "Old", "", "", createdAt, createdAt, true))
+				pool.ExpectQuery("FROM book_redirect").WithArgs(bookID).
+					WillReturnRows(pgxmock.NewRows([]string{"target_id"}))
+				pool.ExpectRollback()
+				return pool
+			},
+			expectedErr: entity.ErrBookNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			pool := test.pool(t)
+			book, err := NewHistory(pool).GetBookAsOf(t.Context(), bookID, asOf)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expected, book)
+			require.NoError(t, pool.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestActor(t *testing.T) {
+	t.Parallel()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectExec("set_config").WithArgs("alice").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	pool.ExpectCommit()
+	pool.ExpectBegin()
+	pool.ExpectCommit()
+
+	transactor := NewTransactor(pool)
+	noop := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, transactor.WithTx(ContextWithActor(t.Context(), "alice"), noop))
+	require.NoError(t, transactor.WithTx(ContextWithActor(t.Context(), ""), noop))
+	require.NoError(t, pool.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/project/library/internal/entity"
+)
+
+var _ IdempotencyRepository = (*idempotencyRepository)(nil)
+
+var ErrIdempotencyKeyStored = errors.New("idempotency key is already stored")
+
+// idempotencyRepository works only inside a transaction, it has no pool.
+type idempotencyRepository struct {
+	ttl time.Duration
+}
+
+func NewIdempotency(ttl time.Duration) *idempotencyRepository {
+	return &idempotencyRepository{
+		ttl: ttl,
+	}
+}
+
+func (i *idempotencyRepository) GetResponse(ctx context.Context, method string, key string) (IdempotencyRecord, error) {
+	tx, err := extractTx(ctx)
+
+	if err != nil {
+		return IdempotencyRecord{}, err
+	}
+
+	const lock = `SELECT pg_advisory_xact_lock(hashtextextended($1 || ':' || $2, 0))`
+
+	if _, err = tx.Exec(ctx, lock, method, key); err != nil {
+		return IdempotencyRecord{}, err
+	}
+
+	const query = `
+SELECT request_hash, response
+FROM idempotency
+WHERE method = $1 AND key = $2 AND expires_at > now()`
+
+	record := IdempotencyRecord{
+		Method: method,
+		Key:    key,
+	}
+
+	if err = tx.QueryRow(ctx, query, method, key).Scan(&record.RequestHash, &record.Response); err != nil {
+		return IdempotencyRecord{}, changeError(err, entity.ErrIdempotencyKeyNotFound)
+	}
+
+	return record, nil
+}
+
+// SaveResponse overwrites an expired record with the same key, if any.
+func (i *idempotencyRepository) SaveResponse(ctx context.Context, record IdempotencyRecord) error {
+	tx, err := extractTx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	const query = `
+INSERT INTO idempotency (method, key, request_hash, response, expires_at)
+VALUES ($1, $2, $3, $4, now() + $5::interval)
+ON CONFLICT (method, key) DO UPDATE
+SET request_hash = excluded.request_hash,
+    response     = excluded.response,
+    created_at   = now(),
+    expires_at   = excluded.expires_at
+WHERE idempotency.expires_at <= now()`
+
+	interval := fmt.Sprintf("%d ms", i.ttl.Milliseconds())
+
+	tag, err := tx.Exec(ctx, query, record.Method, record.Key, record.RequestHash, record.Response, interval)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrIdempotencyKeyStored
+	}
+
+	return nil
+}
+
+func (i *idempotencyRepository) PurgeExpired(ctx context.Context) (int64, error) {
+	tx, err := extractTx(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	const query = `DELETE FROM idempotency WHERE expires_at <= now()`
+
+	tag, err := tx.Exec(ctx, query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
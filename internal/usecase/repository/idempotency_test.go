@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRequiresTx(t *testing.T) {
+	t.Parallel()
+
+	target := NewIdempotency(time.Hour)
+
+	_, err := target.GetResponse(t.Context(), "RegisterBook", "key")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	err = target.SaveResponse(t.Context(), IdempotencyRecord{})
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	_, err = target.PurgeExpired(t.Context())
+	require.ErrorIs(t, err, ErrTxNotFound)
+}
+
+func TestIdempotencyGetResponse(t *testing.T) {
+	t.Parallel()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectExec("pg_advisory_xact_lock").WithArgs("RegisterBook", "key").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	pool.ExpectQuery("FROM idempotency").WithArgs("RegisterBook", "key").WillReturnRows(pgxmock.NewRows([]string{"request_hash", "response"}))
+	pool.ExpectRollback()
+
+	target := NewIdempotency(time.Hour)
+
+	ctx, tx, err := injectTx(t.Context(), pool.Begin)
+	require.NoError(t, err)
+
+	_, err = target.GetResponse(ctx, "RegisterBook", "key")
+	require.ErrorIs(t, err, entity.ErrIdempotencyKeyNotFound)
+
+	require.NoError(t, tx.Rollback(ctx))
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestIdempotencySaveResponse(t *testing.T) {
+	t.Parallel()
+
+	record := IdempotencyRecord{
+		Method:      "RegisterBook",
+		Key:         "key",
+		RequestHash: "hash",
+		Response:    []byte(`{}`),
+	}
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectExec("INSERT INTO idempotency").
+		WithArgs(record.Method, record.Key, record.RequestHash, record.Response, "3600000 ms").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	pool.ExpectExec("INSERT INTO idempotency").
+		WithArgs(record.Method, record.Key, record.RequestHash, record.Response, "3600000 ms").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	target := NewIdempotency(time.Hour)
+
+	ctx, _, err := injectTx(t.Context(), pool.Begin)
+	require.NoError(t, err)
+
+	require.NoError(t, target.SaveResponse(ctx, record))
+	require.ErrorIs(t, target.SaveResponse(ctx, record), ErrIdempotencyKeyStored)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
+func TestIdempotencyPurgeExpired(t *testing.T) {
+	t.Parallel()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectExec("DELETE FROM idempotency WHERE expires_at").WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	target := NewIdempotency(time.Hour)
+
+	ctx, _, err := injectTx(t.Context(), pool.Begin)
+	require.NoError(t, err)
+
+	purged, err := target.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), purged)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/project/library/internal/entity"
 )
@@ -12,11 +14,15 @@ var _ AuthorRepository = (*inMemoryImpl)(nil)
 var _ BooksRepository = (*inMemoryImpl)(nil)
 
 type inMemoryImpl struct {
-	authorsMx *sync.RWMutex
-	authors   map[string]*entity.Author
+	authorsMx       *sync.RWMutex
+	authors         map[string]*entity.Author
+	authorRedirects map[string]string
 
-	booksMx *sync.RWMutex
-	books   map[string]*entity.Book
+	booksMx       *sync.RWMutex
+	books         map[string]*entity.Book
+	bookRedirects map[string]string
+
+	options options
 }
 
 func (i *inMemoryImpl) UpdateBook(_ context.Context, book entity.Book) error {
@@ -25,11 +31,18 @@ func (i *inMemoryImpl) UpdateBook(_ context.Context, book entity.Book) error {
 	i.authorsMx.Lock()
 	defer i.authorsMx.Unlock()
 	for _, author := range book.AuthorIDs {
-		if _, ok := i.authors[author]; !ok {
+		if _, ok := i.liveAuthor(author); !ok {
 			return entity.ErrAuthorNotFound
 		}
 	}
 
+	existing, ok := i.liveBook(book.ID)
+
+	if !ok {
+		return entity.ErrBookNotFound
+	}
+
+	book.CreatedAt, book.UpdatedAt = existing.CreatedAt, time.Now()
 	i.books[book.ID] = &book
 	return nil
 }
@@ -37,17 +50,142 @@ func (i *inMemoryImpl) UpdateBook(_ context.Context, book entity.Book) error {
 func (i *inMemoryImpl) UpdateAuthor(_ context.Context, author entity.Author) error {
 	i.authorsMx.Lock()
 	defer i.authorsMx.Unlock()
+
+	existing, ok := i.liveAuthor(author.ID)
+
+	if !ok {
+		return entity.ErrAuthorNotFound
+	}
+
+	if err := i.checkAuthorName(author); err != nil {
+		return err
+	}
+
+	author.Profile = existing.Profile
+	author.CreatedAt, author.UpdatedAt = existing.CreatedAt, time.Now()
 	i.authors[author.ID] = &author
 	return nil
 }
 
+func (i *inMemoryImpl) UpdateAuthorProfile(_ context.Context, authorID string, profile entity.AuthorProfile) error {
+	i.authorsMx.Lock()
+	defer i.authorsMx.Unlock()
+
+	author, ok := i.liveAuthor(authorID)
+
+	if !ok {
+		return entity.ErrAuthorNotFound
+	}
+
+	if _, ok := i.authors[profile.PseudonymOf]; profile.PseudonymOf != "" && !ok {
+		return entity.ErrPseudonymOfNotFound
+	}
+
+	profile.Pseudonyms = nil
+	author.Profile = profile
+
+	return nil
+}
+
+// liveAuthor must be called with authorsMx held.
+func (i *inMemoryImpl) liveAuthor(authorID string) (*entity.Author, bool) {
+	author, ok := i.authors[authorID]
+	return author, ok && author.DeletedAt.IsZero()
+}
+
+// liveBook must be called with booksMx held.
+func (i *inMemoryImpl) liveBook(bookID string) (*entity.Book, bool) {
+	book, ok := i.books[bookID]
+	return book, ok && book.DeletedAt.IsZero()
+}
+
+// liveAuthorIDs drops authors in the trash, it must be called with authorsMx
+// held.
+func (i *inMemoryImpl) liveAuthorIDs(book entity.Book) entity.Book {
+	authorIDs := make([]string, 0, len(book.AuthorIDs))
+	for _, id := range book.AuthorIDs {
+		if _, ok := i.liveAuthor(id); ok {
+			authorIDs = append(authorIDs, id)
+		}
+	}
+	book.AuthorIDs = authorIDs
+	return book
+}
+
+// checkAuthorName must be called with authorsMx held.
+func (i *inMemoryImpl) checkAuthorName(author entity.Author) error {
+	if !i.options.uniqueAuthorNames {
+		return nil
+	}
+
+	nameKey := entity.NormalizeAuthorName(author.Name)
+
+	for id, existing := range i.authors {
+		if id != author.ID && existing.DeletedAt.IsZero() && entity.NormalizeAuthorName(existing.Name) == nameKey {
+			return &entity.AuthorAlreadyExistsError{ExistingID: id}
+		}
+	}
+
+	return nil
+}
+
+func (i *inMemoryImpl) FindDuplicateAuthors(_ context.Context, threshold float64, limit int) ([]entity.AuthorDuplicate, error) {
+	i.authorsMx.RLock()
+	defer i.authorsMx.RUnlock()
+
+	authors := make([]entity.Author, 0, len(i.authors))
+	for _, author := range i.authors {
+		if author.DeletedAt.IsZero() {
+			authors = append(authors, *author)
+		}
+	}
+
+	slices.SortFunc(authors, func(a, b entity.Author) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	res := make([]entity.AuthorDuplicate, 0)
+
+	for first := range authors {
+		for second := first + 1; second < len(authors); second++ {
+			similarity := entity.NameSimilarity(
+				entity.NormalizeAuthorName(authors[first].Name),
+				entity.NormalizeAuthorName(authors[second].Name),
+			)
+
+			if similarity >= threshold {
+				res = append(res, entity.AuthorDuplicate{
+					First:      authors[first],
+					Second:     authors[second],
+					Similarity: similarity,
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(res, func(a, b entity.AuthorDuplicate) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
 func (i *inMemoryImpl) GetAuthorBooks(_ context.Context, authorID string) ([]entity.Book, error) {
 	i.booksMx.Lock()
 	defer i.booksMx.Unlock()
+	i.authorsMx.RLock()
+	defer i.authorsMx.RUnlock()
 	res := make([]entity.Book, 0)
+	if _, ok := i.liveAuthor(authorID); !ok {
+		return res, nil
+	}
 	for _, book := range i.books {
-		if slices.Contains(book.AuthorIDs, authorID) {
-			res = append(res, *book)
+		if book.DeletedAt.IsZero() && slices.Contains(book.AuthorIDs, authorID) {
+			res = append(res, i.liveAuthorIDs(*book))
 		}
 	}
 	return res, nil
@@ -56,20 +194,39 @@ func (i *inMemoryImpl) GetAuthorBooks(_ context.Context, authorID string) ([]ent
 func (i *inMemoryImpl) GetAuthorInfo(_ context.Context, authorID string) (entity.Author, error) {
 	i.authorsMx.Lock()
 	defer i.authorsMx.Unlock()
-	author, ok := i.authors[authorID]
+	if targetID, ok := i.authorRedirects[authorID]; ok {
+		authorID = targetID
+	}
+	author, ok := i.liveAuthor(authorID)
 	if !ok {
 		return entity.Author{}, entity.ErrAuthorNotFound
 	}
-	return *author, nil
+
+	result := *author
+	result.Profile.Pseudonyms = nil
+
+	for id, other := range i.authors {
+		if other.DeletedAt.IsZero() && other.Profile.PseudonymOf == authorID {
+			result.Profile.Pseudonyms = append(result.Profile.Pseudonyms, id)
+		}
+	}
+
+	slices.Sort(result.Profile.Pseudonyms)
+
+	return result, nil
 }
 
-func NewInMemoryRepository() *inMemoryImpl {
+func NewInMemoryRepository(opts ...Option) *inMemoryImpl {
 	return &inMemoryImpl{
-		authorsMx: new(sync.RWMutex),
-		authors:   make(map[string]*entity.Author),
+		authorsMx:       new(sync.RWMutex),
+		authors:         make(map[string]*entity.Author),
+		authorRedirects: make(map[string]string),
+
+		books:         map[string]*entity.Book{},
+		booksMx:       new(sync.RWMutex),
+		bookRedirects: make(map[string]string),
 
-		books:   map[string]*entity.Book{},
-		booksMx: new(sync.RWMutex),
+		options: newOptions(opts),
 	}
 }
 
@@ -81,6 +238,12 @@ func (i *inMemoryImpl) CreateAuthor(_ context.Context, author entity.Author) (en
 		return entity.Author{}, entity.ErrAuthorAlreadyExists
 	}
 
+	if err := i.checkAuthorName(author); err != nil {
+		return entity.Author{}, err
+	}
+
+	author.CreatedAt = time.Now()
+	author.UpdatedAt = author.CreatedAt
 	i.authors[author.ID] = &author
 	return author, nil
 }
@@ -96,10 +259,13 @@ func (i *inMemoryImpl) CreateBook(_ context.Context, book entity.Book) (entity.B
 	}
 
 	for _, author := range book.AuthorIDs {
-		if _, ok := i.authors[author]; !ok {
+		if _, ok := i.liveAuthor(author); !ok {
 			return entity.Book{}, entity.ErrAuthorNotFound
 		}
 	}
+
+	book.CreatedAt = time.Now()
+	book.UpdatedAt = book.CreatedAt
 	i.books[book.ID] = &book
 	return book, nil
 }
@@ -107,9 +273,249 @@ func (i *inMemoryImpl) CreateBook(_ context.Context, book entity.Book) (entity.B
 func (i *inMemoryImpl) GetBook(_ context.Context, bookID string) (entity.Book, error) {
 	i.booksMx.RLock()
 	defer i.booksMx.RUnlock()
-	v, ok := i.books[bookID]
+	if targetID, ok := i.bookRedirects[bookID]; ok {
+		bookID = targetID
+	}
+	i.authorsMx.RLock()
+	defer i.authorsMx.RUnlock()
+	v, ok := i.liveBook(bookID)
 	if !ok {
 		return entity.Book{}, entity.ErrBookNotFound
 	}
-	return *v, nil
+	return i.liveAuthorIDs(*v), nil
+}
+
+func (i *inMemoryImpl) MergeAuthors(_ context.Context, sourceIDs []string, targetID string) error {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+	i.authorsMx.Lock()
+	defer i.authorsMx.Unlock()
+
+	for _, id := range append([]string{targetID}, sourceIDs...) {
+		if _, ok := i.liveAuthor(id); !ok {
+			return entity.ErrAuthorNotFound
+		}
+	}
+
+	for id, book := range i.books {
+		merged := *book
+		merged.AuthorIDs = mergeIDs(book.AuthorIDs, sourceIDs, targetID)
+		i.books[id] = &merged
+	}
+
+	for _, id := range sourceIDs {
+		delete(i.authors, id)
+	}
+
+	addRedirects(i.authorRedirects, sourceIDs, targetID)
+	return nil
+}
+
+func (i *inMemoryImpl) MergeBooks(_ context.Context, sourceIDs []string, targetID string) error {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+
+	for _, id := range append([]string{targetID}, sourceIDs...) {
+		if _, ok := i.liveBook(id); !ok {
+			return entity.ErrBookNotFound
+		}
+	}
+
+	target := *i.books[targetID]
+	for _, id := range sourceIDs {
+		for _, authorID := range i.books[id].AuthorIDs {
+			if !slices.Contains(target.AuthorIDs, authorID) {
+				target.AuthorIDs = append(slices.Clip(target.AuthorIDs), authorID)
+			}
+		}
+		delete(i.books, id)
+	}
+	i.books[targetID] = &target
+
+	addRedirects(i.bookRedirects, sourceIDs, targetID)
+	return nil
+}
+
+// mergeIDs replaces the sources in ids with a single target.
+func mergeIDs(ids []string, sourceIDs []string, targetID string) []string {
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if slices.Contains(sourceIDs, id) {
+			id = targetID
+		}
+		if !slices.Contains(res, id) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+func addRedirects(redirects map[string]string, sourceIDs []string, targetID string) {
+	for source, target := range redirects {
+		if slices.Contains(sourceIDs, target) {
+			redirects[source] = targetID
+		}
+	}
+	for _, id := range sourceIDs {
+		redirects[id] = targetID
+	}
+}
+
+func (i *inMemoryImpl) DeleteAuthor(_ context.Context, authorID string) error {
+	i.authorsMx.Lock()
+	defer i.authorsMx.Unlock()
+
+	author, ok := i.liveAuthor(authorID)
+	if !ok {
+		return entity.ErrAuthorNotFound
+	}
+
+	deleted := *author
+	deleted.DeletedAt = time.Now()
+	i.authors[authorID] = &deleted
+	return nil
+}
+
+func (i *inMemoryImpl) DeleteBook(_ context.Context, bookID string) error {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+
+	book, ok := i.liveBook(bookID)
+	if !ok {
+		return entity.ErrBookNotFound
+	}
+
+	deleted := *book
+	deleted.DeletedAt = time.Now()
+	i.books[bookID] = &deleted
+	return nil
+}
+
+func (i *inMemoryImpl) RestoreAuthor(_ context.Context, authorID string) error {
+	i.authorsMx.Lock()
+	defer i.authorsMx.Unlock()
+
+	author, ok := i.authors[authorID]
+	if !ok || author.DeletedAt.IsZero() {
+		return entity.ErrAuthorNotFound
+	}
+
+	if err := i.checkAuthorName(*author); err != nil {
+		return err
+	}
+
+	restored := *author
+	restored.DeletedAt = time.Time{}
+	i.authors[authorID] = &restored
+	return nil
+}
+
+func (i *inMemoryImpl) RestoreBook(_ context.Context, bookID string) error {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+
+	book, ok := i.books[bookID]
+	if !ok || book.DeletedAt.IsZero() {
+		return entity.ErrBookNotFound
+	}
+
+	restored := *book
+	restored.DeletedAt = time.Time{}
+	i.books[bookID] = &restored
+	return nil
+}
+
+func (i *inMemoryImpl) ListDeletedAuthors(_ context.Context, limit int) ([]entity.Author, error) {
+	i.authorsMx.RLock()
+	defer i.authorsMx.RUnlock()
+
+	res := make([]entity.Author, 0)
+	for _, author := range i.authors {
+		if !author.DeletedAt.IsZero() {
+			res = append(res, *author)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b entity.Author) int {
+		return cmp.Or(b.DeletedAt.Compare(a.DeletedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return res[:min(limit, len(res))], nil
+}
+
+func (i *inMemoryImpl) ListDeletedBooks(_ context.Context, limit int) ([]entity.Book, error) {
+	i.booksMx.RLock()
+	defer i.booksMx.RUnlock()
+
+	res := make([]entity.Book, 0)
+	for _, book := range i.books {
+		if !book.DeletedAt.IsZero() {
+			res = append(res, *book)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b entity.Book) int {
+		return cmp.Or(b.DeletedAt.Compare(a.DeletedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return res[:min(limit, len(res))], nil
+}
+
+func (i *inMemoryImpl) PurgeDeletedAuthors(_ context.Context, before time.Time) (int64, error) {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+	i.authorsMx.Lock()
+	defer i.authorsMx.Unlock()
+
+	var purged int64
+	for id, author := range i.authors {
+		if author.DeletedAt.IsZero() || !author.DeletedAt.Before(before) {
+			continue
+		}
+
+		delete(i.authors, id)
+		purged++
+
+		for bookID, book := range i.books {
+			if slices.Contains(book.AuthorIDs, id) {
+				unlinked := *book
+				unlinked.AuthorIDs = slices.DeleteFunc(slices.Clone(book.AuthorIDs), func(authorID string) bool {
+					return authorID == id
+				})
+				i.books[bookID] = &unlinked
+			}
+		}
+
+		deleteRedirects(i.authorRedirects, id)
+	}
+
+	return purged, nil
+}
+
+func (i *inMemoryImpl) PurgeDeletedBooks(_ context.Context, before time.Time) (int64, error) {
+	i.booksMx.Lock()
+	defer i.booksMx.Unlock()
+
+	var purged int64
+	for id, book := range i.books {
+		if book.DeletedAt.IsZero() || !book.DeletedAt.Before(before) {
+			continue
+		}
+
+		delete(i.books, id)
+		purged++
+		deleteRedirects(i.bookRedirects, id)
+	}
+
+	return purged, nil
+}
+
+// deleteRedirects drops the redirects to a removed target like the foreign
+// key cascade does.
+func deleteRedirects(redirects map[string]string, targetID string) {
+	for source, target := range redirects {
+		if target == targetID {
+			delete(redirects, source)
+		}
+	}
 }
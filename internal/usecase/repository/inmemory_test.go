@@ -4,6 +4,7 @@ import (
 	"github.com/project/library/internal/entity"
 
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -26,6 +27,22 @@ func authorRepository(t *testing.T, target *inMemoryImpl, authors ...entity.Auth
 	return target
 }
 
+// withoutAuthorTimes and withoutBookTimes drop the timestamps the repository
+// stamps, which the expected values can't know.
+func withoutAuthorTimes(author entity.Author) entity.Author {
+	author.CreatedAt, author.UpdatedAt = time.Time{}, time.Time{}
+	return author
+}
+
+func withoutBookTimes(books ...entity.Book) []entity.Book {
+	result := make([]entity.Book, 0, len(books))
+	for _, book := range books {
+		book.CreatedAt, book.UpdatedAt = time.Time{}, time.Time{}
+		result = append(result, book)
+	}
+	return result
+}
+
 func createInMemoryRepository(t *testing.T, books []entity.Book, authors []entity.Author) *inMemoryImpl {
 	t.Helper()
 	target := NewInMemoryRepository()
@@ -72,7 +89,99 @@ func TestCreateAuthor(t *testing.T) {
 			t.Parallel()
 			actual, actualErr := test.target.CreateAuthor(t.Context(), test.author)
 			require.ErrorIs(t, test.expectedErr, actualErr)
-			require.Equal(t, test.expected, actual)
+
+			if actualErr == nil {
+				require.False(t, actual.CreatedAt.IsZero())
+				require.Equal(t, actual.CreatedAt, actual.UpdatedAt)
+			}
+
+			require.Equal(t, test.expected, withoutAuthorTimes(actual))
+		})
+	}
+}
+
+func TestCreateAuthorUniqueName(t *testing.T) {
+	t.Parallel()
+	existing := CreateAuthor("Leo Tolstoy")
+	duplicate := CreateAuthor("  LEO   tolstoy ")
+
+	tests := []struct {
+		name        string
+		target      AuthorRepository
+		expectedErr error
+	}{
+		{
+			name:        "Uniqueness disabled",
+			target:      authorRepository(t, NewInMemoryRepository(), existing),
+			expectedErr: nil,
+		},
+		{
+			name:        "Uniqueness enabled",
+			target:      authorRepository(t, NewInMemoryRepository(WithUniqueAuthorNames(true)), existing),
+			expectedErr: entity.ErrAuthorAlreadyExists,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, actualErr := test.target.CreateAuthor(t.Context(), duplicate)
+			require.ErrorIs(t, actualErr, test.expectedErr)
+
+			var alreadyExists *entity.AuthorAlreadyExistsError
+			if test.expectedErr != nil {
+				require.ErrorAs(t, actualErr, &alreadyExists)
+				require.Equal(t, existing.ID, alreadyExists.ExistingID)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateAuthors(t *testing.T) {
+	t.Parallel()
+	authors := []entity.Author{
+		CreateAuthor("Fyodor Dostoevsky"),
+		CreateAuthor("Fyodor Dostoyevsky"),
+		CreateAuthor("Anton Chekhov"),
+	}
+	target := authorRepository(t, NewInMemoryRepository(), authors...)
+
+	tests := []struct {
+		name      string
+		threshold float64
+		limit     int
+		expected  int
+	}{
+		{
+			name:      "Similar names",
+			threshold: 0.5,
+			limit:     10,
+			expected:  1,
+		},
+		{
+			name:      "Every pair",
+			threshold: 0,
+			limit:     10,
+			expected:  3,
+		},
+		{
+			name:      "Limit",
+			threshold: 0,
+			limit:     2,
+			expected:  2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := target.FindDuplicateAuthors(t.Context(), test.threshold, test.limit)
+			require.NoError(t, err)
+			require.Len(t, actual, test.expected)
+
+			for _, duplicate := range actual {
+				require.GreaterOrEqual(t, duplicate.Similarity, test.threshold)
+			}
 		})
 	}
 }
@@ -86,6 +195,7 @@ func TestUpdateAuthor(t *testing.T) {
 	}
 	authors[0].Name = "Dave"
 	target := authorRepository(t, NewInMemoryRepository(), authors...)
+	require.NoError(t, target.DeleteAuthor(t.Context(), authors[2].ID))
 	tests := []struct {
 		name        string
 		target      AuthorRepository
@@ -98,6 +208,18 @@ func TestUpdateAuthor(t *testing.T) {
 			author:      authors[0],
 			expectedErr: nil,
 		},
+		{
+			name:        "Author not found",
+			target:      target,
+			author:      CreateAuthor("Eve"),
+			expectedErr: entity.ErrAuthorNotFound,
+		},
+		{
+			name:        "Author in the trash",
+			target:      target,
+			author:      authors[2],
+			expectedErr: entity.ErrAuthorNotFound,
+		},
 	}
 
 	for _, test := range tests {
@@ -164,11 +286,36 @@ func TestGetAuthorBooks(t *testing.T) {
 			t.Parallel()
 			actual, actualErr := test.target.GetAuthorBooks(t.Context(), test.authorID)
 			require.ErrorIs(t, test.expectedErr, actualErr)
-			require.Equal(t, test.expected, actual)
+			require.ElementsMatch(t, test.expected, withoutBookTimes(actual...))
 		})
 	}
 }
 
+func TestUpdateAuthorProfile(t *testing.T) {
+	t.Parallel()
+
+	twain, clemens := CreateAuthor("Mark Twain"), CreateAuthor("Samuel Clemens")
+	target := authorRepository(t, NewInMemoryRepository(), twain, clemens)
+
+	profile := entity.AuthorProfile{BirthDate: "1835-11-30", PseudonymOf: clemens.ID}
+	require.NoError(t, target.UpdateAuthorProfile(t.Context(), twain.ID, profile))
+	require.NoError(t, target.UpdateAuthor(t.Context(), entity.Author{ID: twain.ID, Name: "Mark  Twain"}))
+
+	actual, err := target.GetAuthorInfo(t.Context(), twain.ID)
+	require.NoError(t, err)
+	require.Equal(t, profile, actual.Profile)
+
+	actual, err = target.GetAuthorInfo(t.Context(), clemens.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{twain.ID}, actual.Profile.Pseudonyms)
+
+	err = target.UpdateAuthorProfile(t.Context(), twain.ID, entity.AuthorProfile{PseudonymOf: CreateAuthor("Nobody").ID})
+	require.ErrorIs(t, err, entity.ErrPseudonymOfNotFound)
+
+	err = target.UpdateAuthorProfile(t.Context(), CreateAuthor("Nobody").ID, entity.AuthorProfile{})
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+}
+
 func TestGetAuthorInfo(t *testing.T) {
 	t.Parallel()
 	authors := []entity.Author{
@@ -206,7 +353,7 @@ func TestGetAuthorInfo(t *testing.T) {
 			t.Parallel()
 			actual, actualErr := test.target.GetAuthorInfo(t.Context(), test.authorID)
 			require.ErrorIs(t, test.expectedErr, actualErr)
-			require.Equal(t, test.expected, actual)
+			require.Equal(t, test.expected, withoutAuthorTimes(actual))
 		})
 	}
 }
@@ -263,7 +410,13 @@ func TestCreateBook(t *testing.T) {
 			t.Parallel()
 			actual, actualErr := test.target.CreateBook(t.Context(), test.book)
 			require.ErrorIs(t, test.expectedErr, actualErr)
-			require.Equal(t, test.expected, actual)
+
+			if actualErr == nil {
+				require.False(t, actual.CreatedAt.IsZero())
+				require.Equal(t, actual.CreatedAt, actual.UpdatedAt)
+			}
+
+			require.Equal(t, test.expected, withoutBookTimes(actual)[0])
 		})
 	}
 }
@@ -358,7 +511,169 @@ func TestGetBook(t *testing.T) {
 			t.Parallel()
 			actual, actualErr := test.target.GetBook(t.Context(), test.bookID)
 			require.ErrorIs(t, test.expectedErr, actualErr)
-			require.Equal(t, test.expected, actual)
+			require.Equal(t, test.expected, withoutBookTimes(actual)[0])
 		})
 	}
 }
+
+func TestMergeAuthors(t *testing.T) {
+	t.Parallel()
+	authors := []entity.Author{
+		CreateAuthor("Alice"),
+		CreateAuthor("Alise"),
+		CreateAuthor("Alisa"),
+		CreateAuthor("Bob"),
+	}
+	books := []entity.Book{
+		CreateBook("Both", authors[0].ID, authors[1].ID),
+		CreateBook("Source", authors[2].ID, authors[3].ID),
+	}
+	target := createInMemoryRepository(t, books, authors)
+
+	require.NoError(t, target.MergeAuthors(t.Context(), []string{authors[1].ID}, authors[2].ID))
+	require.NoError(t, target.MergeAuthors(t.Context(), []string{authors[2].ID}, authors[0].ID))
+
+	for _, author := range authors[:3] {
+		resolved, err := target.GetAuthorInfo(t.Context(), author.ID)
+		require.NoError(t, err)
+		require.Equal(t, authors[0], withoutAuthorTimes(resolved))
+	}
+
+	both, err := target.GetBook(t.Context(), books[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{authors[0].ID}, both.AuthorIDs)
+
+	source, err := target.GetBook(t.Context(), books[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{authors[0].ID, authors[3].ID}, source.AuthorIDs)
+
+	err = target.MergeAuthors(t.Context(), []string{authors[1].ID}, authors[3].ID)
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+}
+
+func TestMergeBooks(t *testing.T) {
+	t.Parallel()
+	authors := []entity.Author{
+		CreateAuthor("Alice"),
+		CreateAuthor("Bob"),
+	}
+	books := []entity.Book{
+		CreateBook("Book", authors[0].ID),
+		CreateBook("Book 2nd edition", authors[0].ID, authors[1].ID),
+	}
+	target := createInMemoryRepository(t, books, authors)
+
+	require.NoError(t, target.MergeBooks(t.Context(), []string{books[1].ID}, books[0].ID))
+
+	for _, book := range books {
+		resolved, err := target.GetBook(t.Context(), book.ID)
+		require.NoError(t, err)
+		require.Equal(t, books[0].ID, resolved.ID)
+		require.Equal(t, []string{authors[0].ID, authors[1].ID}, resolved.AuthorIDs)
+	}
+
+	err := target.MergeBooks(t.Context(), []string{books[1].ID}, books[0].ID)
+	require.ErrorIs(t, err, entity.ErrBookNotFound)
+}
+
+func TestSoftDelete(t *testing.T) {
+	t.Parallel()
+	authors := []entity.Author{
+		CreateAuthor("Alice"),
+		CreateAuthor("Bob"),
+	}
+	books := []entity.Book{
+		CreateBook("Shared", authors[0].ID, authors[1].ID),
+	}
+	target := createInMemoryRepository(t, books, authors)
+
+	require.NoError(t, target.DeleteAuthor(t.Context(), authors[1].ID))
+	require.ErrorIs(t, target.DeleteAuthor(t.Context(), authors[1].ID), entity.ErrAuthorNotFound)
+
+	_, err := target.GetAuthorInfo(t.Context(), authors[1].ID)
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+
+	book, err := target.GetBook(t.Context(), books[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{authors[0].ID}, book.AuthorIDs)
+
+	_, err = target.CreateBook(t.Context(), CreateBook("New", authors[1].ID))
+	require.ErrorIs(t, err, entity.ErrAuthorNotFound)
+
+	require.NoError(t, target.DeleteBook(t.Context(), books[0].ID))
+
+	_, err = target.GetBook(t.Context(), books[0].ID)
+	require.ErrorIs(t, err, entity.ErrBookNotFound)
+
+	authorBooks, err := target.GetAuthorBooks(t.Context(), authors[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, authorBooks)
+
+	deletedAuthors, err := target.ListDeletedAuthors(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, deletedAuthors, 1)
+	require.Equal(t, authors[1].ID, deletedAuthors[0].ID)
+	require.False(t, deletedAuthors[0].DeletedAt.IsZero())
+
+	deletedBooks, err := target.ListDeletedBooks(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, deletedBooks, 1)
+
+	require.NoError(t, target.RestoreAuthor(t.Context(), authors[1].ID))
+	require.ErrorIs(t, target.RestoreAuthor(t.Context(), authors[1].ID), entity.ErrAuthorNotFound)
+	require.NoError(t, target.RestoreBook(t.Context(), books[0].ID))
+
+	book, err = target.GetBook(t.Context(), books[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, books[0].AuthorIDs, book.AuthorIDs)
+}
+
+func TestRestoreAuthorUniqueName(t *testing.T) {
+	t.Parallel()
+	deleted := CreateAuthor("Alice")
+	target := authorRepository(t, NewInMemoryRepository(WithUniqueAuthorNames(true)), deleted)
+
+	require.NoError(t, target.DeleteAuthor(t.Context(), deleted.ID))
+
+	replacement, err := target.CreateAuthor(t.Context(), CreateAuthor("alice"))
+	require.NoError(t, err)
+
+	var alreadyExists *entity.AuthorAlreadyExistsError
+	require.ErrorAs(t, target.RestoreAuthor(t.Context(), deleted.ID), &alreadyExists)
+	require.Equal(t, replacement.ID, alreadyExists.ExistingID)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	t.Parallel()
+	authors := []entity.Author{
+		CreateAuthor("Alice"),
+		CreateAuthor("Bob"),
+	}
+	books := []entity.Book{
+		CreateBook("Shared", authors[0].ID, authors[1].ID),
+		CreateBook("Deleted", authors[0].ID),
+	}
+	target := createInMemoryRepository(t, books, authors)
+
+	require.NoError(t, target.DeleteAuthor(t.Context(), authors[1].ID))
+	require.NoError(t, target.DeleteBook(t.Context(), books[1].ID))
+
+	purged, err := target.PurgeDeletedBooks(t.Context(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	purged, err = target.PurgeDeletedBooks(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	purged, err = target.PurgeDeletedAuthors(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	require.ErrorIs(t, target.RestoreBook(t.Context(), books[1].ID), entity.ErrBookNotFound)
+	require.ErrorIs(t, target.RestoreAuthor(t.Context(), authors[1].ID), entity.ErrAuthorNotFound)
+
+	book, err := target.GetBook(t.Context(), books[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{authors[0].ID}, book.AuthorIDs)
+}
@@ -12,14 +12,41 @@ type (
 	AuthorRepository interface {
 		CreateAuthor(ctx context.Context, author entity.Author) (entity.Author, error)
 		UpdateAuthor(ctx context.Context, author entity.Author) error
+		// UpdateAuthorProfile replaces the profile of a live author. It
+		// returns entity.ErrPseudonymOfNotFound when PseudonymOf names no
+		// author.
+		UpdateAuthorProfile(ctx context.Context, authorID string, profile entity.AuthorProfile) error
 		GetAuthorBooks(ctx context.Context, authorID string) ([]entity.Book, error)
 		GetAuthorInfo(ctx context.Context, authorID string) (entity.Author, error)
+		FindDuplicateAuthors(ctx context.Context, threshold float64, limit int) ([]entity.AuthorDuplicate, error)
+		// MergeAuthors moves the books of the sources to the target, deletes
+		// the sources and redirects their IDs to the target.
+		MergeAuthors(ctx context.Context, sourceIDs []string, targetID string) error
+		// DeleteAuthor moves the author to the trash; the other methods
+		// ignore authors in the trash.
+		DeleteAuthor(ctx context.Context, authorID string) error
+		RestoreAuthor(ctx context.Context, authorID string) error
+		ListDeletedAuthors(ctx context.Context, limit int) ([]entity.Author, error)
+		// PurgeDeletedAuthors removes authors moved to the trash before the
+		// given time and returns how many were removed.
+		PurgeDeletedAuthors(ctx context.Context, before time.Time) (int64, error)
 	}
 
 	BooksRepository interface {
 		CreateBook(ctx context.Context, book entity.Book) (entity.Book, error)
 		GetBook(ctx context.Context, bookID string) (entity.Book, error)
 		UpdateBook(ctx context.Context, book entity.Book) error
+		// MergeBooks moves the authors of the sources to the target, deletes
+		// the sources and redirects their IDs to the target.
+		MergeBooks(ctx context.Context, sourceIDs []string, targetID string) error
+		// DeleteBook moves the book to the trash; the other methods ignore
+		// books in the trash.
+		DeleteBook(ctx context.Context, bookID string) error
+		RestoreBook(ctx context.Context, bookID string) error
+		ListDeletedBooks(ctx context.Context, limit int) ([]entity.Book, error)
+		// PurgeDeletedBooks removes books moved to the trash before the given
+		// time and returns how many were removed.
+		PurgeDeletedBooks(ctx context.Context, before time.Time) (int64, error)
 	}
 
 	OutboxRepository interface {
@@ -28,6 +55,90 @@ type (
 		MarkAsProcessed(ctx context.Context, idempotencyKeys []string) error
 	}
 
+	// IdempotencyRepository stores responses of create calls by client key.
+	// The methods must run inside a transaction: GetResponse locks the key
+	// until the transaction ends so concurrent retries wait for the first call.
+	IdempotencyRepository interface {
+		GetResponse(ctx context.Context, method string, key string) (IdempotencyRecord, error)
+		SaveResponse(ctx context.Context, record IdempotencyRecord) error
+		// PurgeExpired deletes the records past their TTL.
+		PurgeExpired(ctx context.Context) (int64, error)
+	}
+
+	// HistoryRepository reads the changes recorded by the database triggers,
+	// newest first.
+	HistoryRepository interface {
+		GetBookHistory(ctx context.Context, bookID string, limit int) ([]entity.HistoryEntry, error)
+		GetAuthorHistory(ctx context.Context, authorID string, limit int) ([]entity.HistoryEntry, error)
+		// GetBookAsOf reconstructs the book as it was at asOf.
+		GetBookAsOf(ctx context.Context, bookID string, asOf time.Time) (entity.Book, error)
+	}
+
+	// CatalogRepository loads catalog imports batch by batch and reads
+	// catalog exports.
+	CatalogRepository interface {
+		// FindAuthorsByName maps normalized author names to the IDs of live
+		// authors; of several authors with the same name the oldest wins.
+		FindAuthorsByName(ctx context.Context, nameKeys []string) (map[string]string, error)
+		// FindAuthorIDs maps the IDs among authorIDs that are already taken by
+		// an author, an author in the trash or a merged author to whether books
+		// can be linked to them, which only live authors allow.
+		FindAuthorIDs(ctx context.Context, authorIDs []string) (map[string]bool, error)
+		// FindAuthorNameClashes maps the authors whose names are taken, when
+		// unique author names are enabled, to the ID of the author holding the
+		// name: a live author or an earlier one of authors. The names stay
+		// locked until the transaction ends, so ImportAuthors can't race an
+		// author created meanwhile.
+		FindAuthorNameClashes(ctx context.Context, authors []entity.Author) (map[string]string, error)
+		// FindBookIDs returns the IDs among bookIDs that are already taken by a
+		// book, a book in the trash or a merged book.
+		FindBookIDs(ctx context.Context, bookIDs []string) ([]string, error)
+		// ImportAuthors and ImportBooks insert new rows with COPY and return
+		// them with the timestamps set.
+		ImportAuthors(ctx context.Context, authors []entity.Author) ([]entity.Author, error)
+		ImportBooks(ctx context.Context, books []entity.Book) ([]entity.Book, error)
+		// GetImportCheckpoint returns the last row committed under importID,
+		// zero for a new import.
+		GetImportCheckpoint(ctx context.Context, importID string) (int, error)
+		// SaveImportCheckpoint moves the checkpoint forward to row and fails
+		// with entity.ErrImportCheckpointConflict if another run of the import
+		// already got as far.
+		SaveImportCheckpoint(ctx context.Context, importID string, row int) error
+		// ExportAuthors and ExportBooks call fn for every live row in the
+		// order of IDs; links to authors in the trash are left out. A
+		// consistent export runs both in one repeatable read transaction.
+		ExportAuthors(ctx context.Context, fn func(entity.Author) error) error
+		ExportBooks(ctx context.Context, fn func(entity.Book) error) error
+		// ListBookRecords returns the book records the query selects, deleted
+		// ones included, in the order of datestamps and IDs.
+		ListBookRecords(ctx context.Context, query entity.HarvestQuery) ([]entity.BookRecord, error)
+		// GetBookRecord returns the record of a live or deleted book and
+		// entity.ErrBookNotFound for an ID that was never used.
+		GetBookRecord(ctx context.Context, bookID string) (entity.BookRecord, error)
+		// EarliestDatestamp returns the time of the first change to any
+		// book, now for an empty catalog.
+		EarliestDatestamp(ctx context.Context) (time.Time, error)
+		// ListBooks returns a page of the live books the filter selects with
+		// the names of their authors; the Datestamp is the last update.
+		ListBooks(ctx context.Context, filter entity.BookFilter) ([]entity.BookRecord, error)
+		// ListAuthors returns a page of the live authors by name.
+		ListAuthors(ctx context.Context, offset int, limit int) ([]entity.Author, error)
+	}
+
+	// APIKeyRepository stores API keys by the hash of the key.
+	APIKeyRepository interface {
+		CreateAPIKey(ctx context.Context, key entity.APIKey, keyHash string) (entity.APIKey, error)
+		GetAPIKey(ctx context.Context, keyHash string) (entity.APIKey, error)
+		RevokeAPIKey(ctx context.Context, keyID string) error
+	}
+
+	IdempotencyRecord struct {
+		Method      string
+		Key         string
+		RequestHash string
+		Response    []byte
+	}
+
 	OutboxData struct {
 		IdempotencyKey string
 		Kind           OutboxKind
@@ -41,6 +152,8 @@ const (
 	OutboxKindUndefined OutboxKind = iota
 	OutboxKindBook
 	OutboxKindAuthor
+	OutboxKindAuthorMerge
+	OutboxKindBookMerge
 )
 
 func (o OutboxKind) String() string {
@@ -49,6 +162,10 @@ func (o OutboxKind) String() string {
 		return "book"
 	case OutboxKindAuthor:
 		return "author"
+	case OutboxKindAuthorMerge:
+		return "author_merge"
+	case OutboxKindBookMerge:
+		return "book_merge"
 	default:
 		return "undefined"
 	}
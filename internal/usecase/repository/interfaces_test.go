@@ -9,11 +9,15 @@ import (
 func TestString(t *testing.T) {
 	t.Parallel()
 	const (
-		outboxKindBook   = "book"
-		outboxKindAuthor = "author"
-		undefined        = "undefined"
+		outboxKindBook        = "book"
+		outboxKindAuthor      = "author"
+		outboxKindAuthorMerge = "author_merge"
+		outboxKindBookMerge   = "book_merge"
+		undefined             = "undefined"
 	)
 	require.Equal(t, outboxKindBook, OutboxKindBook.String())
 	require.Equal(t, outboxKindAuthor, OutboxKindAuthor.String())
+	require.Equal(t, outboxKindAuthorMerge, OutboxKindAuthorMerge.String())
+	require.Equal(t, outboxKindBookMerge, OutboxKindBookMerge.String())
 	require.Equal(t, undefined, OutboxKindUndefined.String())
 }
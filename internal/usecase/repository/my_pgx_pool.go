@@ -11,6 +11,7 @@ import (
 
 type MyPgxPool interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
 
 type MyPgxPoolSmart struct {
@@ -21,6 +22,10 @@ func (p *MyPgxPoolSmart) Begin(_ context.Context) (pgx.Tx, error) {
 	return p.pool, nil
 }
 
+func (p *MyPgxPoolSmart) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return p.pool, nil
+}
+
 type MyPgxPoolDump struct {
 	err error
 }
@@ -29,6 +34,10 @@ func (p *MyPgxPoolDump) Begin(_ context.Context) (pgx.Tx, error) {
 	return nil, p.err
 }
 
+func (p *MyPgxPoolDump) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return nil, p.err
+}
+
 type MyPgxOutboxPool interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error)
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
@@ -0,0 +1,27 @@
+package repository
+
+type (
+	Option func(options *options)
+
+	options struct {
+		uniqueAuthorNames bool
+	}
+)
+
+// WithUniqueAuthorNames rejects an author whose normalized name is already
+// taken with *entity.AuthorAlreadyExistsError.
+func WithUniqueAuthorNames(enabled bool) Option {
+	return func(options *options) {
+		options.uniqueAuthorNames = enabled
+	}
+}
+
+func newOptions(opts []Option) options {
+	var result options
+
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
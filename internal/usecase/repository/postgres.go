@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -17,20 +20,34 @@ var _ BooksRepository = (*postgresRepository)(nil)
 var _ AuthorRepository = (*postgresRepository)(nil)
 
 type postgresRepository struct {
-	logger *zap.Logger
-	db     *pgxpool.Pool
+	logger  *zap.Logger
+	db      *pgxpool.Pool
+	options options
 }
 
 func (p *postgresRepository) CreateAuthor(ctx context.Context, author entity.Author) (resAuthor entity.Author, txErr error) {
 	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) (entity.Author, error) {
-		const request = `INSERT INTO author (name) VALUES ($1) RETURNING id`
+		nameKey := entity.NormalizeAuthorName(author.Name)
+
+		if err := p.checkAuthorNameKey(ctx, tx, author.ID, nameKey); err != nil {
+			return entity.Author{}, err
+		}
+
+		const request = `
+INSERT INTO author (id, name, name_key, sort_key, search_key)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at`
 
 		result := entity.Author{
 			Name: author.Name,
 		}
 
-		if err := tx.QueryRow(ctx, request, author.Name).Scan(&result.ID); err != nil {
-			return entity.Author{}, changeError(err, entity.ErrAuthorNotFound)
+		err := tx.QueryRow(ctx, request, author.ID, author.Name, nameKey,
+			entity.AuthorSortKey(author.Name), entity.AuthorSearchKey(author.Name),
+		).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt)
+
+		if err != nil {
+			return entity.Author{}, changeUniqueError(changeError(err, entity.ErrAuthorNotFound), entity.ErrAuthorAlreadyExists)
 		}
 
 		return result, nil
@@ -40,17 +57,110 @@ func (p *postgresRepository) CreateAuthor(ctx context.Context, author entity.Aut
 
 func (p *postgresRepository) UpdateAuthor(ctx context.Context, author entity.Author) (txErr error) {
 	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
-		const request = `UPDATE author SET name = $1 where id = $2`
-		_, err := tx.Exec(ctx, request, author.Name, author.ID)
+		nameKey := entity.NormalizeAuthorName(author.Name)
+
+		if err := p.checkAuthorNameKey(ctx, tx, author.ID, nameKey); err != nil {
+			return err
+		}
+
+		const request = `
+UPDATE author SET name = $1, name_key = $2, sort_key = $3, search_key = $4
+WHERE id = $5 AND deleted_at IS NULL`
+		tag, err := tx.Exec(ctx, request, author.Name, nameKey,
+			entity.AuthorSortKey(author.Name), entity.AuthorSearchKey(author.Name), author.ID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return entity.ErrAuthorNotFound
+		}
+
+		return nil
+	})
+}
+
+// checkAuthorNameKey returns *entity.AuthorAlreadyExistsError when unique
+// author names are enabled and another author has the same name key. The
+// advisory lock serializes concurrent inserts of the same name.
+func (p *postgresRepository) checkAuthorNameKey(ctx context.Context, tx pgx.Tx, authorID string, nameKey string) error {
+	if !p.options.uniqueAuthorNames {
+		return nil
+	}
+
+	const lock = `SELECT pg_advisory_xact_lock(hashtextextended('author:' || $1, 0))`
+
+	if _, err := tx.Exec(ctx, lock, nameKey); err != nil {
 		return err
+	}
+
+	const request = `
+SELECT id FROM author
+WHERE name_key = $1 AND id <> $2 AND deleted_at IS NULL
+ORDER BY created_at
+LIMIT 1`
+
+	var existingID string
+	err := tx.QueryRow(ctx, request, nameKey, authorID).Scan(&existingID)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return &entity.AuthorAlreadyExistsError{ExistingID: existingID}
+	}
+}
+
+func (p *postgresRepository) FindDuplicateAuthors(ctx context.Context, threshold float64, limit int) ([]entity.AuthorDuplicate, error) {
+	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) ([]entity.AuthorDuplicate, error) {
+		// The % operator uses the trigram index and this threshold.
+		const setThreshold = `SELECT set_config('pg_trgm.similarity_threshold', $1::text, true)`
+
+		// pgx has no plan to encode a float64 as text.
+		if _, err := tx.Exec(ctx, setThreshold, strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+			return nil, err
+		}
+
+		const request = `
+SELECT a.id, a.name, a.created_at, a.updated_at,
+       b.id, b.name, b.created_at, b.updated_at,
+       similarity(a.name_key, b.name_key) AS score
+FROM author a
+JOIN author b ON a.id < b.id AND a.name_key % b.name_key
+WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+ORDER BY score DESC, a.id, b.id
+LIMIT $1`
+
+		rows, err := tx.Query(ctx, request, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.AuthorDuplicate, error) {
+			var duplicate entity.AuthorDuplicate
+			err := row.Scan(
+				&duplicate.First.ID, &duplicate.First.Name, &duplicate.First.CreatedAt, &duplicate.First.UpdatedAt,
+				&duplicate.Second.ID, &duplicate.Second.Name, &duplicate.Second.CreatedAt, &duplicate.Second.UpdatedAt,
+				&duplicate.Similarity,
+			)
+			return duplicate, err
+		})
 	})
 }
 
 func getBook(ctx context.Context, tx pgx.Tx, id string) (entity.Book, error) {
-	const query = `SELECT b.id, b.name, array_remove(array_agg(ab.author_id), NULL) AS author_ids, b.created_at, b.updated_at FROM book b LEFT JOIN author_book ab ON b.id = ab.book_id WHERE b.id = $1 GROUP BY b.id;`
+	const query = `
+SELECT b.id, b.name, array_remove(array_agg(a.id), NULL) AS author_ids, b.isbn, b.publisher, b.created_at, b.updated_at
+FROM book b
+LEFT JOIN author_book ab ON b.id = ab.book_id
+LEFT JOIN author a ON a.id = ab.author_id AND a.deleted_at IS NULL
+WHERE b.id = COALESCE((SELECT target_id FROM book_redirect WHERE source_id = $1), $1) AND b.deleted_at IS NULL
+GROUP BY b.id;`
 	var book entity.Book
 
-	if err := tx.QueryRow(ctx, query, id).Scan(&book.ID, &book.Name, &book.AuthorIDs, &book.CreatedAt, &book.UpdatedAt); err != nil {
+	if err := tx.QueryRow(ctx, query, id).Scan(&book.ID, &book.Name, &book.AuthorIDs, &book.ISBN, &book.Publisher, &book.CreatedAt, &book.UpdatedAt); err != nil {
 		return entity.Book{}, changeError(err, entity.ErrBookNotFound)
 	}
 
@@ -59,7 +169,12 @@ func getBook(ctx context.Context, tx pgx.Tx, id string) (entity.Book, error) {
 
 func (p *postgresRepository) GetAuthorBooks(ctx context.Context, authorID string) (resBooks []entity.Book, txErr error) {
 	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) ([]entity.Book, error) {
-		const request = `SELECT book_id FROM author_book WHERE author_id = $1`
+		const request = `
+SELECT ab.book_id
+FROM author_book ab
+JOIN author a ON a.id = ab.author_id AND a.deleted_at IS NULL
+JOIN book b ON b.id = ab.book_id AND b.deleted_at IS NULL
+WHERE ab.author_id = $1`
 		rows, err := tx.Query(ctx, request, authorID)
 		if err != nil {
 			return []entity.Book{}, err
@@ -104,11 +219,34 @@ func changeUnknownError(err error) error {
 	return err
 }
 
+func changeUniqueError(err error, custom error) error {
+	const ErrUniqueViolation = "23505"
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == ErrUniqueViolation {
+		return custom
+	}
+
+	return err
+}
+
 func (p *postgresRepository) GetAuthorInfo(ctx context.Context, authorID string) (resAuthor entity.Author, txErr error) {
 	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) (entity.Author, error) {
-		const request = `SELECT id, name, created_at, updated_at FROM author WHERE id = $1;`
+		const request = `
+SELECT a.id, a.name, a.biography, a.birth_date, a.death_date, a.nationality, a.alternate_names,
+       COALESCE(a.pseudonym_of::text, ''),
+       ARRAY(SELECT p.id::text FROM author p WHERE p.pseudonym_of = a.id AND p.deleted_at IS NULL ORDER BY p.sort_key, p.id),
+       a.viaf, a.isni, a.wikidata_id, a.created_at, a.updated_at
+FROM author a
+WHERE a.id = COALESCE((SELECT target_id FROM author_redirect WHERE source_id = $1), $1) AND a.deleted_at IS NULL;`
 		var author entity.Author
-		if err := tx.QueryRow(ctx, request, authorID).Scan(&author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt); err != nil {
+		profile := &author.Profile
+		err := tx.QueryRow(ctx, request, authorID).Scan(
+			&author.ID, &author.Name, &profile.Biography, &profile.BirthDate, &profile.DeathDate, &profile.Nationality,
+			&profile.AlternateNames, &profile.PseudonymOf, &profile.Pseudonyms,
+			&profile.VIAF, &profile.ISNI, &profile.WikidataID, &author.CreatedAt, &author.UpdatedAt,
+		)
+		if err != nil {
 			return entity.Author{}, changeError(err, entity.ErrAuthorNotFound)
 		}
 		return author, nil
@@ -117,25 +255,36 @@ func (p *postgresRepository) GetAuthorInfo(ctx context.Context, authorID string)
 
 func (p *postgresRepository) UpdateBook(ctx context.Context, book entity.Book) (txErr error) {
 	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
-		const request = `UPDATE book SET name = $1 WHERE id = $2`
-		_, err := tx.Exec(ctx, request, book.Name, book.ID)
+		const request = `UPDATE book SET name = $1 WHERE id = $2 AND deleted_at IS NULL`
+		tag, err := tx.Exec(ctx, request, book.Name, book.ID)
 		if err != nil {
 			return err
 		}
 
-		const newRequest = `DELETE FROM author_book WHERE book_id = $1`
-		_, err = tx.Exec(ctx, newRequest, book.ID)
-		if err != nil {
+		if tag.RowsAffected() == 0 {
+			return entity.ErrBookNotFound
+		}
+
+		if err = checkAuthorsAlive(ctx, tx, book.AuthorIDs); err != nil {
 			return err
 		}
 
-		insertedRows := make([][]any, 0, len(book.AuthorIDs))
+		// Only the links that change are touched, so the history shows the
+		// authors actually added and removed. A nil slice would be NULL and
+		// keep every link.
+		authorIDs := append([]string{}, book.AuthorIDs...)
 
-		for _, id := range book.AuthorIDs {
-			insertedRows = append(insertedRows, []any{id, book.ID})
+		const newRequest = `DELETE FROM author_book WHERE book_id = $1 AND NOT (author_id = ANY($2))`
+		_, err = tx.Exec(ctx, newRequest, book.ID, authorIDs)
+		if err != nil {
+			return err
 		}
 
-		_, err = tx.CopyFrom(ctx, pgx.Identifier{"author_book"}, []string{"author_id", "book_id"}, pgx.CopyFromRows(insertedRows))
+		const insertRequest = `
+INSERT INTO author_book (author_id, book_id)
+SELECT unnest($2::uuid[]), $1
+ON CONFLICT DO NOTHING`
+		_, err = tx.Exec(ctx, insertRequest, book.ID, authorIDs)
 		if err != nil {
 			return changeUnknownError(err)
 		}
@@ -144,27 +293,34 @@ func (p *postgresRepository) UpdateBook(ctx context.Context, book entity.Book) (
 	})
 }
 
-func NewPostgresRepository(logger *zap.Logger, db *pgxpool.Pool) *postgresRepository {
+func NewPostgresRepository(logger *zap.Logger, db *pgxpool.Pool, opts ...Option) *postgresRepository {
 	return &postgresRepository{
-		logger: logger,
-		db:     db,
+		logger:  logger,
+		db:      db,
+		options: newOptions(opts),
 	}
 }
 
 func (p *postgresRepository) CreateBook(ctx context.Context, book entity.Book) (resBook entity.Book, txErr error) {
 	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) (entity.Book, error) {
 		const queryBook = `
-INSERT INTO book (name)
-VALUES ($1)
+INSERT INTO book (id, name, isbn, publisher)
+VALUES ($1, $2, $3, $4)
 RETURNING id, created_at, updated_at
 `
 		result := entity.Book{
 			Name:      book.Name,
 			AuthorIDs: book.AuthorIDs,
+			ISBN:      book.ISBN,
+			Publisher: book.Publisher,
+		}
+
+		if err := tx.QueryRow(ctx, queryBook, book.ID, book.Name, book.ISBN, book.Publisher).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt); err != nil {
+			return entity.Book{}, changeUniqueError(changeError(err, entity.ErrBookNotFound), entity.ErrBookAlreadyExists)
 		}
 
-		if err := tx.QueryRow(ctx, queryBook, book.Name).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt); err != nil {
-			return entity.Book{}, changeError(err, entity.ErrBookNotFound)
+		if err := checkAuthorsAlive(ctx, tx, book.AuthorIDs); err != nil {
+			return entity.Book{}, err
 		}
 
 		const queryAuthorBooks = `
@@ -195,3 +351,242 @@ func (p *postgresRepository) GetBook(ctx context.Context, bookID string) (resBoo
 		return ans, nil
 	})
 }
+
+func (p *postgresRepository) MergeAuthors(ctx context.Context, sourceIDs []string, targetID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		if err := lockForMerge(ctx, tx, "author", sourceIDs, targetID); err != nil {
+			return changeError(err, entity.ErrAuthorNotFound)
+		}
+
+		// ON CONFLICT skips books the target already has, the old links go
+		// away with the sources.
+		const relink = `
+INSERT INTO author_book (author_id, book_id)
+SELECT $2, book_id FROM author_book WHERE author_id = ANY($1)
+ON CONFLICT DO NOTHING`
+
+		if _, err := tx.Exec(ctx, relink, sourceIDs, targetID); err != nil {
+			return err
+		}
+
+		return redirect(ctx, tx, "author", sourceIDs, targetID)
+	})
+}
+
+func (p *postgresRepository) MergeBooks(ctx context.Context, sourceIDs []string, targetID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		if err := lockForMerge(ctx, tx, "book", sourceIDs, targetID); err != nil {
+			return changeError(err, entity.ErrBookNotFound)
+		}
+
+		const relink = `
+INSERT INTO author_book (author_id, book_id)
+SELECT author_id, $2 FROM author_book WHERE book_id = ANY($1)
+ON CONFLICT DO NOTHING`
+
+		if _, err := tx.Exec(ctx, relink, sourceIDs, targetID); err != nil {
+			return err
+		}
+
+		return redirect(ctx, tx, "book", sourceIDs, targetID)
+	})
+}
+
+// lockForMerge locks the rows of the target and the sources of table and
+// returns sql.ErrNoRows if any of them is missing.
+func lockForMerge(ctx context.Context, tx pgx.Tx, table string, sourceIDs []string, targetID string) error {
+	const request = `
+SELECT count(*)
+FROM (SELECT id FROM %s WHERE (id = ANY($1) OR id = $2) AND deleted_at IS NULL FOR UPDATE) AS locked`
+
+	var locked int
+	if err := tx.QueryRow(ctx, fmt.Sprintf(request, table), sourceIDs, targetID).Scan(&locked); err != nil {
+		return err
+	}
+
+	if locked != len(sourceIDs)+1 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// redirect points the sources and everything already redirected to them at
+// the target, then deletes the sources. Redirects are updated first because
+// deleting a source cascades to the redirects that target it.
+func redirect(ctx context.Context, tx pgx.Tx, table string, sourceIDs []string, targetID string) error {
+	const (
+		updateRedirects = `UPDATE %[1]s_redirect SET target_id = $2 WHERE target_id = ANY($1)`
+		insertRedirects = `INSERT INTO %[1]s_redirect (source_id, target_id) SELECT unnest($1::uuid[]), $2`
+		deleteSources   = `DELETE FROM %[1]s WHERE id = ANY($1)`
+	)
+
+	for _, request := range []string{updateRedirects, insertRedirects} {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(request, table), sourceIDs, targetID); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf(deleteSources, table), sourceIDs)
+	return err
+}
+
+// checkAuthorsAlive returns entity.ErrAuthorNotFound if one of the authors is
+// in the trash: the foreign key of author_book only checks that rows exist.
+func checkAuthorsAlive(ctx context.Context, tx pgx.Tx, authorIDs []string) error {
+	if len(authorIDs) == 0 {
+		return nil
+	}
+
+	const request = `SELECT count(*) FROM author WHERE id = ANY($1) AND deleted_at IS NOT NULL`
+
+	var deleted int
+	if err := tx.QueryRow(ctx, request, authorIDs).Scan(&deleted); err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		return entity.ErrAuthorNotFound
+	}
+
+	return nil
+}
+
+func (p *postgresRepository) DeleteAuthor(ctx context.Context, authorID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		return softDelete(ctx, tx, "author", authorID, entity.ErrAuthorNotFound)
+	})
+}
+
+func (p *postgresRepository) DeleteBook(ctx context.Context, bookID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		return softDelete(ctx, tx, "book", bookID, entity.ErrBookNotFound)
+	})
+}
+
+func softDelete(ctx context.Context, tx pgx.Tx, table string, id string, notFound error) error {
+	const request = `UPDATE %s SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(request, table), id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+// RestoreAuthor fails with *entity.AuthorAlreadyExistsError when unique author
+// names are enabled and the name was taken while the author was in the trash.
+func (p *postgresRepository) RestoreAuthor(ctx context.Context, authorID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		const selectRequest = `SELECT name_key FROM author WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`
+
+		var nameKey string
+		if err := tx.QueryRow(ctx, selectRequest, authorID).Scan(&nameKey); err != nil {
+			return changeError(err, entity.ErrAuthorNotFound)
+		}
+
+		if err := p.checkAuthorNameKey(ctx, tx, authorID, nameKey); err != nil {
+			return err
+		}
+
+		const request = `UPDATE author SET deleted_at = NULL WHERE id = $1`
+		_, err := tx.Exec(ctx, request, authorID)
+		return err
+	})
+}
+
+func (p *postgresRepository) RestoreBook(ctx context.Context, bookID string) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		const request = `UPDATE book SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+		tag, err := tx.Exec(ctx, request, bookID)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return entity.ErrBookNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *postgresRepository) ListDeletedAuthors(ctx context.Context, limit int) ([]entity.Author, error) {
+	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) ([]entity.Author, error) {
+		const request = `
+SELECT id, name, created_at, updated_at, deleted_at
+FROM author
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id
+LIMIT $1`
+
+		rows, err := tx.Query(ctx, request, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Author, error) {
+			var author entity.Author
+			err := row.Scan(&author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt, &author.DeletedAt)
+			return author, err
+		})
+	})
+}
+
+func (p *postgresRepository) ListDeletedBooks(ctx context.Context, limit int) ([]entity.Book, error) {
+	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) ([]entity.Book, error) {
+		const request = `
+SELECT b.id, b.name, array_remove(array_agg(ab.author_id), NULL) AS author_ids, b.created_at, b.updated_at, b.deleted_at
+FROM book b
+LEFT JOIN author_book ab ON b.id = ab.book_id
+WHERE b.deleted_at IS NOT NULL
+GROUP BY b.id
+ORDER BY b.deleted_at DESC, b.id
+LIMIT $1`
+
+		rows, err := tx.Query(ctx, request, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Book, error) {
+			var book entity.Book
+			err := row.Scan(&book.ID, &book.Name, &book.AuthorIDs, &book.CreatedAt, &book.UpdatedAt, &book.DeletedAt)
+			return book, err
+		})
+	})
+}
+
+func (p *postgresRepository) PurgeDeletedAuthors(ctx context.Context, before time.Time) (int64, error) {
+	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) (int64, error) {
+		return purge(ctx, tx, "author", before)
+	})
+}
+
+func (p *postgresRepository) PurgeDeletedBooks(ctx context.Context, before time.Time) (int64, error) {
+	return myExtractCtx(ctx, p.db, func(tx pgx.Tx) (int64, error) {
+		return purge(ctx, tx, "book", before)
+	})
+}
+
+// purge hard-deletes rows of table moved to the trash before the given time;
+// links and redirects go away with them by cascade.
+func purge(ctx context.Context, tx pgx.Tx, table string, before time.Time) (int64, error) {
+	const request = `DELETE FROM %s WHERE deleted_at < $1`
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(request, table), before)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFindDuplicateAuthorsThreshold(t *testing.T) {
+	t.Parallel()
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	// The threshold goes as text: pgx can't encode a float64 into a text
+	// parameter.
+	pool.ExpectExec("set_config").WithArgs("0.3").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	pool.ExpectQuery("similarity").WithArgs(10).WillReturnRows(pgxmock.NewRows([]string{
+		"id", "name", "created_at", "updated_at", "id", "name", "created_at", "updated_at", "score",
+	}))
+
+	ctx, _, err := injectTx(t.Context(), pool.Begin)
+	require.NoError(t, err)
+
+	target := NewPostgresRepository(zaptest.NewLogger(t), nil)
+
+	duplicates, err := target.FindDuplicateAuthors(ctx, 0.3, 10)
+	require.NoError(t, err)
+	require.Empty(t, duplicates)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/project/library/internal/entity"
+)
+
+// alternateSearchKey joins the search keys of the names with a separator
+// no key contains, so a LIKE pattern can't match across two names.
+func alternateSearchKey(names []string) string {
+	keys := make([]string, 0, len(names))
+
+	for _, name := range names {
+		keys = append(keys, entity.AuthorSearchKey(name))
+	}
+
+	return strings.Join(keys, " | ")
+}
+
+func (p *postgresRepository) UpdateAuthorProfile(ctx context.Context, authorID string, profile entity.AuthorProfile) error {
+	return myExtractCtxNoT(ctx, p.db, func(tx pgx.Tx) error {
+		const request = `
+UPDATE author
+SET biography = $2, birth_date = $3, death_date = $4, nationality = $5,
+    alternate_names = $6, alternate_search_key = $7, pseudonym_of = $8,
+    viaf = $9, isni = $10, wikidata_id = $11
+WHERE id = $1 AND deleted_at IS NULL`
+
+		var pseudonymOf *string
+
+		if profile.PseudonymOf != "" {
+			pseudonymOf = &profile.PseudonymOf
+		}
+
+		alternateNames := profile.AlternateNames
+
+		if alternateNames == nil {
+			alternateNames = []string{}
+		}
+
+		tag, err := tx.Exec(ctx, request, authorID,
+			profile.Biography, profile.BirthDate, profile.DeathDate, profile.Nationality,
+			alternateNames, alternateSearchKey(alternateNames), pseudonymOf,
+			profile.VIAF, profile.ISNI, profile.WikidataID,
+		)
+
+		if err != nil {
+			if errors.Is(changeUnknownError(err), entity.ErrAuthorNotFound) {
+				return entity.ErrPseudonymOfNotFound
+			}
+
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return entity.ErrAuthorNotFound
+		}
+
+		return nil
+	})
+}
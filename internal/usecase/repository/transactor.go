@@ -3,72 +3,187 @@ package repository
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 //go:generate ../../../bin/mockgen -source=transactor.go -destination=../../../generated/mocks/transactor_mock.go -package=mocks
 type Transactor interface {
-	WithTx(ctx context.Context, function func(ctx context.Context) error) error
+	WithTx(ctx context.Context, function func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type (
+	// RetryPolicy controls how many times a transaction is re-run after a
+	// serialization failure or a deadlock and how long to wait between runs.
+	RetryPolicy struct {
+		MaxAttempts    int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+	}
+
+	TxOption func(options *txOptions)
+
+	txOptions struct {
+		pgx   pgx.TxOptions
+		retry RetryPolicy
+	}
+)
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+func WithIsolation(level pgx.TxIsoLevel) TxOption {
+	return func(options *txOptions) {
+		options.pgx.IsoLevel = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(options *txOptions) {
+		options.pgx.AccessMode = pgx.ReadOnly
+	}
+}
+
+func WithDeferrable() TxOption {
+	return func(options *txOptions) {
+		options.pgx.DeferrableMode = pgx.Deferrable
+	}
+}
+
+func WithRetry(policy RetryPolicy) TxOption {
+	return func(options *txOptions) {
+		options.retry = policy
+	}
+}
+
+// WithoutRetry disables re-running the closure, e.g. when it has side effects
+// outside the database.
+func WithoutRetry() TxOption {
+	return WithRetry(RetryPolicy{MaxAttempts: 1})
 }
 
 var _ Transactor = (*transactorImpl)(nil)
 
 type transactorImpl struct {
-	db MyPgxPool
+	db       MyPgxPool
+	defaults []TxOption
 }
 
-func NewTransactor(db MyPgxPool) *transactorImpl {
+// NewTransactor creates a transactor; defaults are applied to every WithTx
+// call before the per-call options.
+func NewTransactor(db MyPgxPool, defaults ...TxOption) *transactorImpl {
 	return &transactorImpl{
-		db: db,
+		db:       db,
+		defaults: slices.Concat([]TxOption{WithRetry(DefaultRetryPolicy)}, defaults),
+	}
+}
+
+// WithTx runs function inside a transaction. Serialization failures and
+// deadlocks re-run function according to the retry policy. When ctx already
+// carries a transaction, function runs inside a savepoint of it instead and
+// the options are ignored: isolation and retries belong to the outer call.
+func (t *transactorImpl) WithTx(ctx context.Context, function func(ctx context.Context) error, opts ...TxOption) error {
+	if tx, err := extractTx(ctx); err == nil {
+		return runTx(ctx, tx.Begin, function)
+	}
+
+	// append could write opts into spare capacity of defaults that every
+	// call shares.
+	var options txOptions
+	for _, opt := range slices.Concat(t.defaults, opts) {
+		opt(&options)
+	}
+
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		return beginWithActor(ctx, func(ctx context.Context) (pgx.Tx, error) {
+			return t.db.BeginTx(ctx, options.pgx)
+		})
+	}
+
+	backoff := options.retry.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, begin, function)
+
+		if err == nil || !isRetryable(err) || attempt >= options.retry.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(jitter(backoff)):
+		}
+
+		backoff = min(2*backoff, options.retry.MaxBackoff)
 	}
 }
 
-func (t *transactorImpl) WithTx(ctx context.Context, function func(ctx context.Context) error) (txErr error) {
-	ctxWithTx, tx, err := injectTx(ctx, t.db)
+func runTx(
+	ctx context.Context,
+	begin func(ctx context.Context) (pgx.Tx, error),
+	function func(ctx context.Context) error,
+) (txErr error) {
+	ctxWithTx, tx, err := injectTx(ctx, begin)
 
 	if err != nil {
 		return err
 	}
 
 	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+
 		if txErr != nil {
-			newErr := tx.Rollback(ctxWithTx)
-			if newErr != nil {
-				err = newErr
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				txErr = errors.Join(txErr, err)
 			}
 			return
 		}
 
-		newErr := tx.Commit(ctxWithTx)
-		if newErr != nil {
-			err = newErr
-		}
+		txErr = tx.Commit(ctx)
 	}()
 
-	if err != nil {
-		return err
+	return function(ctxWithTx)
+}
+
+func isRetryable(err error) bool {
+	const (
+		ErrSerializationFailure = "40001"
+		ErrDeadlockDetected     = "40P01"
+	)
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
 	}
 
-	err = function(ctxWithTx)
+	return pgErr.Code == ErrSerializationFailure || pgErr.Code == ErrDeadlockDetected
+}
 
-	if err != nil {
-		return err
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
 	}
 
-	return nil
+	return d/2 + rand.N(d/2+1)
 }
 
 type txInjector struct{}
 
 var ErrTxNotFound = errors.New("tx not found in context")
 
-func injectTx(ctx context.Context, pool MyPgxPool) (context.Context, pgx.Tx, error) {
-	if tx, err := extractTx(ctx); err == nil {
-		return ctx, tx, nil
-	}
-
-	tx, err := pool.Begin(ctx)
+func injectTx(ctx context.Context, begin func(ctx context.Context) (pgx.Tx, error)) (context.Context, pgx.Tx, error) {
+	tx, err := begin(ctx)
 
 	if err != nil {
 		return nil, nil, err
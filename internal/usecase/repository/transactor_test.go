@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -24,62 +27,176 @@ func TestNewTransactor(t *testing.T) {
 func TestWithTx(t *testing.T) {
 	t.Parallel()
 
-	poolMock, err := pgxmock.NewPool()
-	require.NoError(t, err)
-
 	invalidPgxPool := errors.New("invalid pgxpool")
 	functionError := errors.New("function error")
+	serializationError := &pgconn.PgError{Code: "40001"}
+	deadlockError := &pgconn.PgError{Code: "40P01"}
 
-	successTarget := NewTransactor(&MyPgxPoolSmart{
-		pool: poolMock,
-	})
-	failureTarget := NewTransactor(&MyPgxPoolDump{
-		err: invalidPgxPool,
-	})
+	fastRetry := WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	failing := func(errs ...error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if len(errs) == 0 {
+				return nil
+			}
+			err := errs[0]
+			errs = errs[1:]
+			return err
+		}
+	}
 
 	tests := []struct {
 		name        string
-		target      *transactorImpl
+		pool        func(t *testing.T) MyPgxPool
 		function    func(ctx context.Context) error
+		opts        []TxOption
 		expectedErr error
 	}{
 		{
-			name:   "success test",
-			target: successTarget,
-			function: func(ctx context.Context) error {
-				return nil
+			name: "success test",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectCommit()
+				return newPool
 			},
+			function:    failing(),
 			expectedErr: nil,
 		},
 		{
-			name:   "failure test",
-			target: failureTarget,
-			function: func(ctx context.Context) error {
-				return nil
+			name: "failure test",
+			pool: func(t *testing.T) MyPgxPool {
+				return &MyPgxPoolDump{err: invalidPgxPool}
 			},
+			function:    failing(),
 			expectedErr: invalidPgxPool,
 		},
 		{
-			name:   "failure function",
-			target: successTarget,
-			function: func(ctx context.Context) error {
-				return functionError
+			name: "failure function",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				return newPool
 			},
+			function:    failing(functionError),
 			expectedErr: functionError,
 		},
+		{
+			name: "failure function is not retried",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				return newPool
+			},
+			function:    failing(functionError, nil),
+			opts:        []TxOption{fastRetry},
+			expectedErr: functionError,
+		},
+		{
+			name: "retry after serialization failure and deadlock",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				newPool.ExpectBegin()
+				newPool.ExpectCommit()
+				return newPool
+			},
+			function:    failing(serializationError, deadlockError),
+			opts:        []TxOption{fastRetry},
+			expectedErr: nil,
+		},
+		{
+			name: "retries exhausted",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				return newPool
+			},
+			function:    failing(serializationError, serializationError, nil),
+			opts:        []TxOption{fastRetry, WithRetry(RetryPolicy{MaxAttempts: 2})},
+			expectedErr: serializationError,
+		},
+		{
+			name: "without retry",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBegin()
+				newPool.ExpectRollback()
+				return newPool
+			},
+			function:    failing(serializationError, nil),
+			opts:        []TxOption{WithoutRetry()},
+			expectedErr: serializationError,
+		},
+		{
+			name: "transaction options",
+			pool: func(t *testing.T) MyPgxPool {
+				newPool := getPgxMockPool(t)
+				newPool.ExpectBeginTx(pgx.TxOptions{
+					IsoLevel:       pgx.Serializable,
+					AccessMode:     pgx.ReadOnly,
+					DeferrableMode: pgx.Deferrable,
+				})
+				newPool.ExpectCommit()
+				return newPool
+			},
+			function:    failing(),
+			opts:        []TxOption{WithIsolation(pgx.Serializable), WithReadOnly(), WithDeferrable()},
+			expectedErr: nil,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
-			err := test.target.WithTx(t.Context(), test.function)
-			if err != nil {
+			pool := test.pool(t)
+			err := NewTransactor(pool).WithTx(t.Context(), test.function, test.opts...)
+			if test.expectedErr != nil {
 				require.ErrorIs(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if mock, ok := pool.(pgxmock.PgxPoolIface); ok {
+				require.NoError(t, mock.ExpectationsWereMet())
 			}
 		})
 	}
 }
 
+func TestWithTxNested(t *testing.T) {
+	t.Parallel()
+
+	functionError := errors.New("function error")
+
+	pool := getPgxMockPool(t)
+	pool.ExpectBegin()
+	pool.ExpectBegin()
+	pool.ExpectRollback()
+	pool.ExpectCommit()
+
+	transactor := NewTransactor(pool)
+
+	err := transactor.WithTx(t.Context(), func(ctx context.Context) error {
+		nestedErr := transactor.WithTx(ctx, func(ctx context.Context) error {
+			return functionError
+		})
+		require.ErrorIs(t, nestedErr, functionError)
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, pool.ExpectationsWereMet())
+}
+
 func TestInjectTx(t *testing.T) {
 	t.Parallel()
 
@@ -92,10 +209,13 @@ func TestInjectTx(t *testing.T) {
 
 	ctx := t.Context()
 
-	newCtx, _, err := injectTx(ctx, successTarget)
+	newCtx, tx, err := injectTx(ctx, successTarget.Begin)
 	require.NoError(t, err)
-	newCtx1, _, err1 := injectTx(newCtx, successTarget)
-	require.NoError(t, err1)
-	require.Equal(t, newCtx, newCtx1)
+
+	extracted, err := extractTx(newCtx)
 	require.NoError(t, err)
+	require.Equal(t, tx, extracted)
+
+	_, err = extractTx(ctx)
+	require.ErrorIs(t, err, ErrTxNotFound)
 }
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/project/library/internal/entity"
 )
 
 func myExtractCtx[T any](ctx context.Context, pgxPool MyPgxPool, function func(tx pgx.Tx) (T, error)) (res T, txErr error) {
@@ -13,11 +17,11 @@ func myExtractCtx[T any](ctx context.Context, pgxPool MyPgxPool, function func(t
 	)
 
 	if tx, err = extractTx(ctx); err != nil {
-		tx, err = pgxPool.Begin(ctx)
+		tx, err = beginWithActor(ctx, pgxPool.Begin)
 
 		if err != nil {
 			var ans T
-			return ans, err
+			return ans, storageError(err)
 		}
 
 		defer func(tx pgx.Tx, ctx context.Context) {
@@ -35,7 +39,9 @@ func myExtractCtx[T any](ctx context.Context, pgxPool MyPgxPool, function func(t
 		}(tx, ctx)
 	}
 
-	return function(tx)
+	res, err = function(tx)
+
+	return res, storageError(err)
 }
 
 func myExtractCtxNoT(ctx context.Context, pgxPool MyPgxPool, function func(tx pgx.Tx) error) (txErr error) {
@@ -45,10 +51,10 @@ func myExtractCtxNoT(ctx context.Context, pgxPool MyPgxPool, function func(tx pg
 	)
 
 	if tx, err = extractTx(ctx); err != nil {
-		tx, err = pgxPool.Begin(ctx)
+		tx, err = beginWithActor(ctx, pgxPool.Begin)
 
 		if err != nil {
-			return err
+			return storageError(err)
 		}
 
 		defer func(tx pgx.Tx, ctx context.Context) {
@@ -66,5 +72,35 @@ func myExtractCtxNoT(ctx context.Context, pgxPool MyPgxPool, function func(tx pg
 		}(tx, ctx)
 	}
 
-	return function(tx)
+	return storageError(function(tx))
+}
+
+// storageError marks the errors of the database that go away on a retry
+// with entity.ErrStorageUnavailable: lost connections, serialization
+// failures and deadlocks. Canceled calls and the other errors pass as they
+// are.
+func storageError(err error) error {
+	const (
+		serializationFailure = "40001"
+		deadlockDetected     = "40P01"
+	)
+
+	var (
+		pgErr      *pgconn.PgError
+		connectErr *pgconn.ConnectError
+	)
+
+	switch {
+	case err == nil, errors.Is(err, entity.ErrStorageUnavailable),
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr):
+		if pgErr.Code != serializationFailure && pgErr.Code != deadlockDetected {
+			return err
+		}
+	case !errors.As(err, &connectErr) && !pgconn.SafeToRetry(err):
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", entity.ErrStorageUnavailable, err)
 }
@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
+	"github.com/project/library/internal/entity"
 	"github.com/stretchr/testify/require"
 )
 
@@ -147,3 +150,35 @@ func TestMyExtractCtxNoT(t *testing.T) {
 		})
 	}
 }
+
+func TestStorageError(t *testing.T) {
+	t.Parallel()
+
+	plain := errors.New("syntax error")
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "nil"},
+		{name: "plain", err: plain},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, retryable: true},
+		{name: "deadlock", err: fmt.Errorf("update: %w", &pgconn.PgError{Code: "40P01"}), retryable: true},
+		{name: "connect", err: &pgconn.ConnectError{}, retryable: true},
+		{name: "canceled", err: context.Canceled},
+		{name: "not found", err: entity.ErrBookNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := storageError(test.err)
+
+			require.ErrorIs(t, err, test.err)
+			require.Equal(t, test.retryable, errors.Is(err, entity.ErrStorageUnavailable))
+			require.Equal(t, err, storageError(err), "wrapped once")
+		})
+	}
+}